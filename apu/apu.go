@@ -10,6 +10,7 @@ import (
 	"log"
 
 	"github.com/djhworld/gomeboycolor/components"
+	"github.com/djhworld/gomeboycolor/state"
 	"github.com/djhworld/gomeboycolor/types"
 )
 
@@ -44,4 +45,13 @@ func (apu *APU) LinkIRQHandler(m components.IRQHandler) {
 
 func (apu *APU) Reset() {
 	log.Println(apu.Name()+": Resetting", apu.Name())
+	apu.mem = *new([0x41]byte)
+}
+
+func (apu *APU) SaveState(s *state.Writer) {
+	s.Bytes(apu.mem[:])
+}
+
+func (apu *APU) LoadState(s *state.Reader) {
+	s.Bytes(apu.mem[:])
 }
@@ -3,6 +3,8 @@ package cartridge
 import (
 	"io"
 
+	"github.com/djhworld/gomeboycolor/state"
+
 	"github.com/djhworld/gomeboycolor/types"
 )
 
@@ -11,6 +13,13 @@ type MemoryBankController interface {
 	Read(addr types.Word) byte
 	SaveRam(writer io.Writer) error
 	LoadRam(reader io.Reader) error
+	IsRamDirty() bool
+	IsRamCommitted() bool
+	SnapshotRam() [][]byte
+	CurrentROMBank() int
+	SaveState(s *state.Writer)
+	LoadState(s *state.Reader)
+	Reset()
 	switchROMBank(bank int)
 	switchRAMBank(bank int)
 }
@@ -38,3 +47,24 @@ func populateRAMBanks(noOfBanks int) [][]byte {
 
 	return ramBanks
 }
+
+//RAM without a battery loses its contents when the power goes
+func clearRAMBanks(banks [][]byte) {
+	for _, bank := range banks {
+		for i := range bank {
+			bank[i] = 0x00
+		}
+	}
+}
+
+//Copies RAM banks so they can be persisted while the cartridge keeps running
+func copyRAMBanks(banks [][]byte) [][]byte {
+	result := make([][]byte, len(banks))
+
+	for i, bank := range banks {
+		result[i] = make([]byte, len(bank))
+		copy(result[i], bank)
+	}
+
+	return result
+}
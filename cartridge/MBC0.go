@@ -50,10 +50,18 @@ func (m *MBC0) switchROMBank(bank int) {
 	// not needed for MBC0
 }
 
+//The bank mapped into 0x4000-0x7FFF, which is always 1 for MBC0
+func (m *MBC0) CurrentROMBank() int {
+	return 1
+}
+
 func (m *MBC0) switchRAMBank(bank int) {
 	// not needed for MBC0
 }
 
+func (m *MBC0) Reset() {
+}
+
 func (m *MBC0) SaveRam(writer io.Writer) error {
 	return nil
 }
@@ -61,3 +69,15 @@ func (m *MBC0) SaveRam(writer io.Writer) error {
 func (m *MBC0) LoadRam(reader io.Reader) error {
 	return nil
 }
+
+func (m *MBC0) IsRamDirty() bool {
+	return false
+}
+
+func (m *MBC0) IsRamCommitted() bool {
+	return false
+}
+
+func (m *MBC0) SnapshotRam() [][]byte {
+	return nil
+}
@@ -22,6 +22,8 @@ type MBC1 struct {
 	hasRAM          bool
 	ramEnabled      bool
 	hasBattery      bool
+	ramDirty        bool
+	ramCommitted    bool
 	MaxMemMode      int
 	ROMSize         int
 	RAMSize         int
@@ -68,6 +70,11 @@ func (m *MBC1) String() string {
 func (m *MBC1) Write(addr types.Word, value byte) {
 	switch {
 	case addr >= 0x0000 && addr <= 0x1FFF:
+		//games disable RAM once they have finished writing a save
+		if value&0x0F != 0x0A && m.ramDirty {
+			m.ramCommitted = true
+		}
+
 		//when in 4/32 mode...
 		if m.MaxMemMode == constants.FOURMB_ROM_32KBRAM && m.hasRAM {
 			if r := value & 0x0F; r == 0x0A {
@@ -98,6 +105,7 @@ func (m *MBC1) Write(addr types.Word, value byte) {
 			case constants.SIXTEENMB_ROM_8KBRAM:
 				m.ramBanks[0][addr-0xA000] = value
 			}
+			m.ramDirty = true
 		}
 	}
 }
@@ -132,10 +140,29 @@ func (m *MBC1) switchROMBank(bank int) {
 	m.selectedROMBank = bank
 }
 
+//The bank mapped into 0x4000-0x7FFF, selecting bank 0 maps bank 1
+func (m *MBC1) CurrentROMBank() int {
+	if m.selectedROMBank == 0 {
+		return 1
+	}
+	return m.selectedROMBank
+}
+
 func (m *MBC1) switchRAMBank(bank int) {
 	m.selectedRAMBank = bank
 }
 
+//Puts the bank registers back to their power on values, battery backed RAM is kept
+func (m *MBC1) Reset() {
+	m.selectedROMBank = 0
+	m.selectedRAMBank = 0
+	m.ramEnabled = m.hasRAM
+	m.MaxMemMode = constants.SIXTEENMB_ROM_8KBRAM
+	if !m.hasBattery {
+		clearRAMBanks(m.ramBanks)
+	}
+}
+
 func (m *MBC1) SaveRam(writer io.Writer) error {
 	if m.hasRAM && m.hasBattery {
 		s := NewSave()
@@ -158,3 +185,22 @@ func (m *MBC1) LoadRam(reader io.Reader) error {
 	}
 	return nil
 }
+
+func (m *MBC1) IsRamDirty() bool {
+	return m.ramDirty
+}
+
+func (m *MBC1) IsRamCommitted() bool {
+	return m.ramCommitted
+}
+
+//Returns a copy of the battery backed RAM banks (or nil if the cartridge has no battery)
+//and marks the RAM as clean
+func (m *MBC1) SnapshotRam() [][]byte {
+	m.ramDirty = false
+	m.ramCommitted = false
+	if m.hasRAM && m.hasBattery {
+		return copyRAMBanks(m.ramBanks)
+	}
+	return nil
+}
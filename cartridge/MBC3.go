@@ -22,6 +22,8 @@ type MBC3 struct {
 	ROMSize         int
 	RAMSize         int
 	hasBattery      bool
+	ramDirty        bool
+	ramCommitted    bool
 }
 
 func NewMBC3(rom []byte, romSize int, ramSize int, hasBattery bool) *MBC3 {
@@ -68,6 +70,10 @@ func (m *MBC3) Write(addr types.Word, value byte) {
 			if r := value & 0x0F; r == 0x0A {
 				m.ramEnabled = true
 			} else {
+				//games disable RAM once they have finished writing a save
+				if m.ramDirty {
+					m.ramCommitted = true
+				}
 				m.ramEnabled = false
 			}
 		}
@@ -81,6 +87,7 @@ func (m *MBC3) Write(addr types.Word, value byte) {
 	case addr >= 0xA000 && addr <= 0xBFFF:
 		if m.hasRAM && m.ramEnabled {
 			m.ramBanks[m.selectedRAMBank][addr-0xA000] = value
+			m.ramDirty = true
 		}
 	}
 }
@@ -110,10 +117,28 @@ func (m *MBC3) switchROMBank(bank int) {
 	m.selectedROMBank = bank
 }
 
+//The bank mapped into 0x4000-0x7FFF, selecting bank 0 maps bank 1
+func (m *MBC3) CurrentROMBank() int {
+	if m.selectedROMBank == 0 {
+		return 1
+	}
+	return m.selectedROMBank
+}
+
 func (m *MBC3) switchRAMBank(bank int) {
 	m.selectedRAMBank = bank
 }
 
+//Puts the bank registers back to their power on values, battery backed RAM is kept
+func (m *MBC3) Reset() {
+	m.selectedROMBank = 0
+	m.selectedRAMBank = 0
+	m.ramEnabled = m.hasRAM
+	if !m.hasBattery {
+		clearRAMBanks(m.ramBanks)
+	}
+}
+
 func (m *MBC3) SaveRam(writer io.Writer) error {
 	if m.hasRAM && m.hasBattery {
 		s := NewSave()
@@ -136,3 +161,22 @@ func (m *MBC3) LoadRam(reader io.Reader) error {
 	}
 	return nil
 }
+
+func (m *MBC3) IsRamDirty() bool {
+	return m.ramDirty
+}
+
+func (m *MBC3) IsRamCommitted() bool {
+	return m.ramCommitted
+}
+
+//Returns a copy of the battery backed RAM banks (or nil if the cartridge has no battery)
+//and marks the RAM as clean
+func (m *MBC3) SnapshotRam() [][]byte {
+	m.ramDirty = false
+	m.ramCommitted = false
+	if m.hasRAM && m.hasBattery {
+		return copyRAMBanks(m.ramBanks)
+	}
+	return nil
+}
@@ -22,6 +22,8 @@ type MBC5 struct {
 	ROMSize         int
 	RAMSize         int
 	hasBattery      bool
+	ramDirty        bool
+	ramCommitted    bool
 	ROMBHigher      types.Word
 	ROMBLower       types.Word
 }
@@ -41,7 +43,9 @@ func NewMBC5(rom []byte, romSize int, ramSize int, hasBattery bool) *MBC5 {
 		m.ramBanks = populateRAMBanks(16)
 	}
 
-	m.selectedROMBank = 0
+	//bank 1 is mapped at power on
+	m.selectedROMBank = 1
+	m.ROMBLower = 0x01
 	m.romBank0 = rom[0x0000:0x4000]
 	m.romBanks = populateROMBanks(rom, m.ROMSize/0x4000)
 
@@ -70,6 +74,10 @@ func (m *MBC5) Write(addr types.Word, value byte) {
 			if r := value & 0x0F; r == 0x0A {
 				m.ramEnabled = true
 			} else {
+				//games disable RAM once they have finished writing a save
+				if m.ramDirty {
+					m.ramCommitted = true
+				}
 				m.ramEnabled = false
 			}
 		}
@@ -86,6 +94,7 @@ func (m *MBC5) Write(addr types.Word, value byte) {
 	case addr >= 0xA000 && addr <= 0xBFFF:
 		if m.hasRAM && m.ramEnabled {
 			m.ramBanks[m.selectedRAMBank][addr-0xA000] = value
+			m.ramDirty = true
 		}
 	}
 }
@@ -98,8 +107,9 @@ func (m *MBC5) Read(addr types.Word) byte {
 
 	//Switchable ROM BANK
 	if addr >= 0x4000 && addr < 0x8000 {
+		//unlike the other MBCs bank 0 can be mapped here too
 		if m.selectedROMBank == 0 {
-			return m.romBank0[addr]
+			return m.romBank0[addr-0x4000]
 		}
 		return m.romBanks[m.selectedROMBank][addr-0x4000]
 	}
@@ -118,10 +128,27 @@ func (m *MBC5) switchROMBank(bank int) {
 	m.selectedROMBank = bank
 }
 
+//The bank mapped into 0x4000-0x7FFF
+func (m *MBC5) CurrentROMBank() int {
+	return m.selectedROMBank
+}
+
 func (m *MBC5) switchRAMBank(bank int) {
 	m.selectedRAMBank = bank
 }
 
+//Puts the bank registers back to their power on values, battery backed RAM is kept
+func (m *MBC5) Reset() {
+	m.selectedROMBank = 1
+	m.selectedRAMBank = 0
+	m.ramEnabled = m.hasRAM
+	m.ROMBHigher = 0x00
+	m.ROMBLower = 0x01
+	if !m.hasBattery {
+		clearRAMBanks(m.ramBanks)
+	}
+}
+
 func (m *MBC5) SaveRam(writer io.Writer) error {
 	if m.hasRAM && m.hasBattery {
 		s := NewSave()
@@ -144,3 +171,22 @@ func (m *MBC5) LoadRam(reader io.Reader) error {
 	}
 	return nil
 }
+
+func (m *MBC5) IsRamDirty() bool {
+	return m.ramDirty
+}
+
+func (m *MBC5) IsRamCommitted() bool {
+	return m.ramCommitted
+}
+
+//Returns a copy of the battery backed RAM banks (or nil if the cartridge has no battery)
+//and marks the RAM as clean
+func (m *MBC5) SnapshotRam() [][]byte {
+	m.ramDirty = false
+	m.ramCommitted = false
+	if m.hasRAM && m.hasBattery {
+		return copyRAMBanks(m.ramBanks)
+	}
+	return nil
+}
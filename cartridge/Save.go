@@ -13,11 +13,14 @@ import (
 	"time"
 )
 
+//Title and LastSaved are kept ahead of the banks so save stores can read them
+//without decoding the rest of the save
 type Save struct {
+	Title      string
+	LastSaved  string
 	NoOfBanks  int
 	Banks      []string
 	BankHashes []uint32
-	LastSaved  string
 }
 
 func NewSave() *Save {
@@ -27,7 +30,7 @@ func NewSave() *Save {
 
 func (s *Save) Validate() error {
 	if s.NoOfBanks != len(s.Banks) {
-		return errors.New(fmt.Sprintf("No. of banks does (%d) NOT match number of actual banks (%d)", s.NoOfBanks, s.Banks))
+		return errors.New(fmt.Sprintf("No. of banks does (%d) NOT match number of actual banks (%d)", s.NoOfBanks, len(s.Banks)))
 	}
 
 	return nil
@@ -99,7 +102,7 @@ func (s *Save) Load(reader io.Reader, noOfBanks int) ([][]byte, error) {
 		//decompress into byte array
 		inflatedBank, err := s.InflateBank(bank)
 		if err != nil {
-			return nil, errors.New(fmt.Sprintln("Error attempting to parse and decompress bank %d (%v), save could be corrupted!", i, err))
+			return nil, errors.New(fmt.Sprintf("Error attempting to parse and decompress bank %d (%v), save could be corrupted!", i, err))
 		}
 
 		//check to ensure checksum is valid against what we decompressed
@@ -131,7 +134,7 @@ func (s *Save) Save(writer io.Writer, data [][]byte) error {
 		//compress
 		bankStr, err := s.DeflateBank(bank)
 		if err != nil {
-			return errors.New(fmt.Sprintln("Error attempting to compress bank %d (%v)", i, err))
+			return errors.New(fmt.Sprintf("Error attempting to compress bank %d (%v)", i, err))
 		}
 
 		log.Printf("--> Storing bank %d (Compression ratio: %.1f%%)", i, 100.00-((float32(len(bankStr))/float32(len(bank)))*100))
@@ -55,6 +55,8 @@ type Cartridge struct {
 	Name       string
 	MBC        MemoryBankController
 	ID         string
+	ROMHash    string
+	Header     [0x50]byte //0x0100 -> 0x014F
 }
 
 func NewCartridge(romName string, romContents []byte) (*Cartridge, error) {
@@ -74,10 +76,12 @@ func (c *Cartridge) Init(rom []byte) error {
 		return errors.New(fmt.Sprintf("ROM size %d is too small", size))
 	}
 
+	copy(c.Header[:], rom[0x0100:0x0150])
 	c.Title = strings.TrimSpace(string(rom[0x0134:0x0142]))
 	h := md5.New()
 	io.WriteString(h, c.Title)
 	c.ID = fmt.Sprintf("%x", h.Sum(nil))
+	c.ROMHash = fmt.Sprintf("%x", md5.Sum(rom))
 
 	c.IsColourGB = (rom[0x0143] == 0x80) || (rom[0x0143] == 0xC0)
 
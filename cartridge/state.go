@@ -0,0 +1,89 @@
+package cartridge
+
+import (
+	"github.com/djhworld/gomeboycolor/state"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+func saveRAMBanksState(s *state.Writer, banks [][]byte) {
+	s.Int(len(banks))
+	for _, bank := range banks {
+		s.Bytes(bank)
+	}
+}
+
+//RAM banks are loaded in place, the number of banks must match the cartridge
+func loadRAMBanksState(s *state.Reader, banks [][]byte) {
+	if n := s.Int(); n != len(banks) && s.Err() == nil {
+		s.Invalid("expected %d RAM banks but found %d", len(banks), n)
+		return
+	}
+	for _, bank := range banks {
+		s.Bytes(bank)
+	}
+}
+
+//The selected bank has to exist on the cartridge. A cartridge without RAM never reads
+//its RAM bank so any bank the game selected is kept
+func loadBankState(s *state.Reader, name string, noOfBanks int) int {
+	bank := s.Int()
+	if (bank < 0 || (noOfBanks > 0 && bank >= noOfBanks)) && s.Err() == nil {
+		s.Invalid("selected %s bank %d is out of range", name, bank)
+		return 0
+	}
+	return bank
+}
+
+func (m *MBC0) SaveState(s *state.Writer) {
+}
+
+func (m *MBC0) LoadState(s *state.Reader) {
+}
+
+func (m *MBC1) SaveState(s *state.Writer) {
+	s.Int(m.selectedROMBank)
+	s.Int(m.selectedRAMBank)
+	s.Bool(m.ramEnabled)
+	s.Int(m.MaxMemMode)
+	saveRAMBanksState(s, m.ramBanks)
+}
+
+func (m *MBC1) LoadState(s *state.Reader) {
+	m.selectedROMBank = loadBankState(s, "ROM", len(m.romBanks))
+	m.selectedRAMBank = loadBankState(s, "RAM", len(m.ramBanks))
+	m.ramEnabled = s.Bool()
+	m.MaxMemMode = s.Int()
+	loadRAMBanksState(s, m.ramBanks)
+}
+
+func (m *MBC3) SaveState(s *state.Writer) {
+	s.Int(m.selectedROMBank)
+	s.Int(m.selectedRAMBank)
+	s.Bool(m.ramEnabled)
+	saveRAMBanksState(s, m.ramBanks)
+}
+
+func (m *MBC3) LoadState(s *state.Reader) {
+	m.selectedROMBank = loadBankState(s, "ROM", len(m.romBanks))
+	m.selectedRAMBank = loadBankState(s, "RAM", len(m.ramBanks))
+	m.ramEnabled = s.Bool()
+	loadRAMBanksState(s, m.ramBanks)
+}
+
+func (m *MBC5) SaveState(s *state.Writer) {
+	s.Int(m.selectedROMBank)
+	s.Int(m.selectedRAMBank)
+	s.Bool(m.ramEnabled)
+	s.Word(m.ROMBHigher)
+	s.Word(m.ROMBLower)
+	saveRAMBanksState(s, m.ramBanks)
+}
+
+func (m *MBC5) LoadState(s *state.Reader) {
+	m.selectedROMBank = loadBankState(s, "ROM", len(m.romBanks))
+	m.selectedRAMBank = loadBankState(s, "RAM", len(m.ramBanks))
+	m.ramEnabled = s.Bool()
+	m.ROMBHigher = s.Word() & types.Word(0x01)
+	m.ROMBLower = s.Word() & types.Word(0xFF)
+	loadRAMBanksState(s, m.ramBanks)
+}
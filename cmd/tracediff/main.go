@@ -0,0 +1,49 @@
+//Compares a trace written by the emulator against a reference log (e.g. from
+//gameboy-doctor or another emulator) and reports where they first diverge.
+//
+//	tracediff ours.log reference.log
+//
+//Either trace may be in the text or binary format. The exit status is 0 when the
+//traces match, 1 when they diverge and 2 on error
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/djhworld/gomeboycolor/trace"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "Usage: tracediff <our trace> <reference trace>")
+		os.Exit(2)
+	}
+
+	ours, err := os.Open(os.Args[1])
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+	defer ours.Close()
+
+	reference, err := os.Open(os.Args[2])
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+	defer reference.Close()
+
+	divergence, matched, err := trace.Diff(ours, reference)
+	if err != nil {
+		log.Printf("Could not compare traces after %d instructions: %v", matched, err)
+		os.Exit(2)
+	}
+
+	if divergence != nil {
+		fmt.Println(divergence)
+		os.Exit(1)
+	}
+	fmt.Printf("Traces match (%d instructions)\n", matched)
+}
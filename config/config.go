@@ -4,10 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/djhworld/gomeboycolor/utils"
 )
 
+//Shades DMG games can be drawn in
+const (
+	GREY_PALETTE   string = "grey"
+	GREEN_PALETTE  string = "green"
+	POCKET_PALETTE string = "pocket"
+	CUSTOM_PALETTE string = "custom"
+)
+
 type Config struct {
 	//mandatory settings
 	Title         string
@@ -22,6 +31,32 @@ type Config struct {
 	Debug     bool
 	BreakOn   string
 	DumpState bool
+
+	//step the PPU dot by dot through its pixel FIFOs instead of drawing whole
+	//lines at once, slower but mid line register changes become visible
+	PixelFIFO bool
+
+	//let the CPU read and write VRAM and OAM whatever the PPU is doing, real
+	//hardware locks them out in modes 2 and 3 (useful for debugging)
+	UnlockedVRAM bool
+
+	//shades DMG games are drawn in on DMG hardware, one of the palette names
+	//above (blank means grey). In color mode they are coloured in by title as
+	//the CGB boot ROM does, or by the button combo held on the boot logo
+	DMGPalette string
+
+	//lightest to darkest shades as 0xRRGGBB, used when DMGPalette is "custom"
+	CustomPalette [4]uint32
+
+	//RGBDS or no$gmb symbol file used to label disassembly in the debugger
+	SymbolFile string
+
+	//how often changed battery RAM is flushed to the save store (0 only
+	//flushes when the game disables cartridge RAM and on close)
+	BatterySaveInterval time.Duration
+
+	//memory in bytes given to the rewind history (0 disables rewinding)
+	RewindBudget int
 }
 
 func (c *Config) String() string {
@@ -35,8 +70,14 @@ func (c *Config) String() string {
 		fmt.Sprintln(utils.PadRight("Debug mode?: ", 19, " "), c.Debug) +
 		fmt.Sprintln(utils.PadRight("Breakpoint: ", 19, " "), c.BreakOn) +
 		fmt.Sprintln(utils.PadRight("CPU Dump?: ", 19, " "), c.DumpState) +
+		fmt.Sprintln(utils.PadRight("Pixel FIFO PPU: ", 19, " "), c.PixelFIFO) +
+		fmt.Sprintln(utils.PadRight("Unlocked VRAM: ", 19, " "), c.UnlockedVRAM) +
+		fmt.Sprintln(utils.PadRight("DMG Palette: ", 19, " "), c.DMGPalette) +
+		fmt.Sprintln(utils.PadRight("Symbol File: ", 19, " "), c.SymbolFile) +
 		fmt.Sprintln(utils.PadRight("Headless: ", 19, " "), c.Headless) +
 		fmt.Sprintln(utils.PadRight("FrameRateLock: ", 19, " "), c.FrameRateLock) +
+		fmt.Sprintln(utils.PadRight("Save Interval: ", 19, " "), c.BatterySaveInterval) +
+		fmt.Sprintln(utils.PadRight("Rewind Budget: ", 19, " "), c.RewindBudget) +
 		fmt.Sprint(strings.Repeat("-", 50))
 }
 
@@ -49,6 +90,26 @@ func (c *Config) Validate() error {
 		return ConfigValidationError("\"ScreenSize\" attribute must be between 1 and 6")
 	}
 
+	if c.BatterySaveInterval < 0 {
+		return ConfigValidationError("\"BatterySaveInterval\" attribute cannot be negative")
+	}
+
+	if c.RewindBudget < 0 {
+		return ConfigValidationError("\"RewindBudget\" attribute cannot be negative")
+	}
+
+	switch c.DMGPalette {
+	case "", GREY_PALETTE, GREEN_PALETTE, POCKET_PALETTE:
+	case CUSTOM_PALETTE:
+		for _, colour := range c.CustomPalette {
+			if colour > 0xFFFFFF {
+				return ConfigValidationError(fmt.Sprintf("\"CustomPalette\" colour 0x%X is not an RGB colour", colour))
+			}
+		}
+	default:
+		return ConfigValidationError(fmt.Sprintf("\"DMGPalette\" attribute must be one of %s, %s, %s or %s", GREY_PALETTE, GREEN_PALETTE, POCKET_PALETTE, CUSTOM_PALETTE))
+	}
+
 	return nil
 }
 
@@ -7,6 +7,7 @@ const (
 	V_BLANK_IR_ADDR        byte = 0x40
 	LCD_IR_ADDR                 = 0x48
 	TIMER_OVERFLOW_IR_ADDR      = 0x50
+	SERIAL_IR_ADDR              = 0x58
 	JOYP_HILO_IR_ADDR           = 0x60
 )
 
@@ -14,9 +15,18 @@ const (
 	V_BLANK_IRQ        byte = 0x01 //bit 0
 	LCD_IRQ                 = 0x02 //bit 1
 	TIMER_OVERFLOW_IRQ      = 0x04 // bit 2
+	SERIAL_IRQ              = 0x08 //bit 3
 	JOYP_HILO_IRQ           = 0x10 //bit 4
 )
 
+//LCD modes reported in the bottom of STAT
+const (
+	LCD_MODE_HBLANK byte = 0x00
+	LCD_MODE_VBLANK      = 0x01
+	LCD_MODE_OAM         = 0x02
+	LCD_MODE_VRAM        = 0x03
+)
+
 const (
 	SIXTEENMB_ROM_8KBRAM = iota
 	FOURMB_ROM_32KBRAM
@@ -26,4 +36,5 @@ const (
 const (
 	INTERRUPT_ENABLED_FLAG_ADDR types.Word = 0xFFFF
 	INTERRUPT_FLAG_ADDR                    = 0xFF0F
+	DIV_REGISTER_ADDR                      = 0xFF04
 )
@@ -13,6 +13,8 @@ import (
 const NAME = "CPU"
 const PREFIX = NAME + ":"
 
+const JOYPAD_REGISTER types.Word = 0xFF00
+
 //flags
 const (
 	_ = iota
@@ -23,15 +25,18 @@ const (
 )
 
 type CPUFrame struct {
-	PC                      types.Word // Program Counter
-	SP                      types.Word // Stack Pointer
-	R                       Registers
-	InterruptsEnabled       bool
-	CurrentInstruction      Instruction
-	LastInstrCycle          Clock
-	PCJumped                bool
-	Halted                  bool
-	InterruptFlagBeforeHalt byte
+	PC                 types.Word // Program Counter
+	SP                 types.Word // Stack Pointer
+	R                  Registers
+	InterruptsEnabled  bool
+	CurrentInstruction Instruction
+	LastInstrCycle     Clock
+	PCJumped           bool
+	Halted             bool
+	Stopped            bool
+	LockedUp           bool
+	HaltBug            bool
+	EIDelay            int
 }
 
 func (cpu *GbcCPU) GetFrame() *CPUFrame {
@@ -40,11 +45,14 @@ func (cpu *GbcCPU) GetFrame() *CPUFrame {
 	frame.SP = cpu.SP
 	frame.R = cpu.R
 	frame.InterruptsEnabled = cpu.InterruptsEnabled
-	frame.CurrentInstruction = cpu.CurrentInstruction
+	frame.CurrentInstruction = cpu.instruction()
 	frame.LastInstrCycle = cpu.LastInstrCycle
 	frame.PCJumped = cpu.PCJumped
 	frame.Halted = cpu.Halted
-	frame.InterruptFlagBeforeHalt = cpu.InterruptFlagBeforeHalt
+	frame.Stopped = cpu.Stopped
+	frame.LockedUp = cpu.LockedUp
+	frame.HaltBug = cpu.HaltBug
+	frame.EIDelay = cpu.EIDelay
 	return frame
 }
 
@@ -88,27 +96,51 @@ func (c *Clock) String() string {
 }
 
 type GbcCPU struct {
-	PC                      types.Word // Program Counter
-	SP                      types.Word // Stack Pointer
-	R                       Registers
-	InterruptsEnabled       bool
-	CurrentInstruction      Instruction
-	LastInstrCycle          Clock
-	mmu                     mmu.MemoryMappedUnit
-	PCJumped                bool
-	Halted                  bool
-	InterruptFlagBeforeHalt byte
-	Speed                   int
+	PC                 types.Word // Program Counter
+	SP                 types.Word // Stack Pointer
+	R                  Registers
+	InterruptsEnabled  bool
+	CurrentInstruction *Instruction
+	Operands           [2]byte
+	LastInstrCycle     Clock
+	mmu                mmu.MemoryMappedUnit
+	PCJumped           bool
+	Halted             bool
+	Stopped            bool
+	LockedUp           bool
+	HaltBug            bool
+	EIDelay            int
+	Speed              int
+	ticker             func(mcycles int)
+	tracer             func()
+	handlers           [256]handler
+	handlersCB         [256]handler
+	instructionCycles  int
 }
 
 func NewCPU(m mmu.MemoryMappedUnit) *GbcCPU {
 	cpu := new(GbcCPU)
+	cpu.bindHandlers()
 	cpu.Reset()
 	cpu.mmu = m
 	log.Println(PREFIX, "Linked CPU to MMU")
 	return cpu
 }
 
+//Links a function that is called for every M-cycle the CPU spends, as it spends it.
+//This lets the rest of the system be stepped in time with each memory access rather
+//than once the whole instruction has executed
+func (cpu *GbcCPU) LinkTicker(ticker func(mcycles int)) {
+	cpu.ticker = ticker
+	log.Println(PREFIX, "Linked ticker to CPU")
+}
+
+//Sets a function called before each instruction is fetched, once any interrupt has
+//been dispatched. Pass nil to remove it
+func (cpu *GbcCPU) LinkTracer(tracer func()) {
+	cpu.tracer = tracer
+}
+
 func (cpu *GbcCPU) Reset() {
 	log.Println(PREFIX, "Resetting", NAME)
 	cpu.PC = 0
@@ -122,11 +154,16 @@ func (cpu *GbcCPU) Reset() {
 	cpu.R.H = 0
 	cpu.R.L = 0
 	cpu.Speed = 1
-	cpu.CurrentInstruction, _ = cpu.Decode(0x00)
+	cpu.CurrentInstruction = &Instructions[0x00]
+	cpu.Operands = [2]byte{}
 	cpu.InterruptsEnabled = true
 	cpu.LastInstrCycle.Reset()
 	cpu.PCJumped = false
 	cpu.Halted = false
+	cpu.Stopped = false
+	cpu.LockedUp = false
+	cpu.HaltBug = false
+	cpu.EIDelay = 0
 }
 
 func (cpu *GbcCPU) FlagsString() string {
@@ -160,7 +197,14 @@ func (cpu *GbcCPU) FlagsString() string {
 }
 
 func (cpu *GbcCPU) String() string {
-	return fmt.Sprint("PC: ", cpu.PC, "  SP: ", cpu.SP, "  ", cpu.R, "  ", cpu.FlagsString(), "  ", cpu.CurrentInstruction)
+	return fmt.Sprint("PC: ", cpu.PC, "  SP: ", cpu.SP, "  ", cpu.R, "  ", cpu.FlagsString(), "  ", cpu.instruction())
+}
+
+//The current instruction along with its operands
+func (cpu *GbcCPU) instruction() Instruction {
+	var instruction Instruction = *cpu.CurrentInstruction
+	instruction.Operands = cpu.Operands
+	return instruction
 }
 
 func (cpu *GbcCPU) ResetFlag(flag int) {
@@ -215,1170 +259,205 @@ func (cpu *GbcCPU) IncrementPC(by int) {
 	cpu.PC += types.Word(by)
 }
 
-func (cpu *GbcCPU) DispatchCB(Opcode byte) {
-	switch Opcode {
-	case 0x00: //RLC B
-		cpu.Rlc_r(&cpu.R.B)
-	case 0x01: //RLC C
-		cpu.Rlc_r(&cpu.R.C)
-	case 0x02: //RLC D
-		cpu.Rlc_r(&cpu.R.D)
-	case 0x03: //RLC E
-		cpu.Rlc_r(&cpu.R.E)
-	case 0x04: //RLC H
-		cpu.Rlc_r(&cpu.R.H)
-	case 0x05: //RLC L
-		cpu.Rlc_r(&cpu.R.L)
-	case 0x06: //RLC (HL)
-		cpu.Rlc_hl()
-	case 0x07: //RLC A
-		cpu.Rlc_r(&cpu.R.A)
-	case 0x08: //RRC B
-		cpu.Rrc_r(&cpu.R.B)
-	case 0x09: //RRC C
-		cpu.Rrc_r(&cpu.R.C)
-	case 0x0A: //RRC D
-		cpu.Rrc_r(&cpu.R.D)
-	case 0x0B: //RRC E
-		cpu.Rrc_r(&cpu.R.E)
-	case 0x0C: //RRC H
-		cpu.Rrc_r(&cpu.R.H)
-	case 0x0D: //RRC L
-		cpu.Rrc_r(&cpu.R.L)
-	case 0x0E: //RRC (HL)
-		cpu.Rrc_hl()
-	case 0x0F: //RRC A
-		cpu.Rrc_r(&cpu.R.A)
-	case 0x10: //RL B
-		cpu.Rl_r(&cpu.R.B)
-	case 0x11: //RL C
-		cpu.Rl_r(&cpu.R.C)
-	case 0x12: //RL D
-		cpu.Rl_r(&cpu.R.D)
-	case 0x13: //RL E
-		cpu.Rl_r(&cpu.R.E)
-	case 0x14: //RL H
-		cpu.Rl_r(&cpu.R.H)
-	case 0x15: //RL L
-		cpu.Rl_r(&cpu.R.L)
-	case 0x16: //RL (HL)
-		cpu.Rl_hl()
-	case 0x17: //RL A
-		cpu.Rl_r(&cpu.R.A)
-	case 0x18: //RR B
-		cpu.Rr_r(&cpu.R.B)
-	case 0x19: //RR C
-		cpu.Rr_r(&cpu.R.C)
-	case 0x1A: //RR D
-		cpu.Rr_r(&cpu.R.D)
-	case 0x1B: //RR E
-		cpu.Rr_r(&cpu.R.E)
-	case 0x1C: //RR H
-		cpu.Rr_r(&cpu.R.H)
-	case 0x1D: //RR L
-		cpu.Rr_r(&cpu.R.L)
-	case 0x1E: //RR (HL)
-		cpu.Rr_hl()
-	case 0x1F: //RR A
-		cpu.Rr_r(&cpu.R.A)
-	case 0x20: //SLA B
-		cpu.Sla_r(&cpu.R.B)
-	case 0x21: //SLA C
-		cpu.Sla_r(&cpu.R.C)
-	case 0x22: //SLA D
-		cpu.Sla_r(&cpu.R.D)
-	case 0x23: //SLA E
-		cpu.Sla_r(&cpu.R.E)
-	case 0x24: //SLA H
-		cpu.Sla_r(&cpu.R.H)
-	case 0x25: //SLA L
-		cpu.Sla_r(&cpu.R.L)
-	case 0x26: //SLA (HL)
-		cpu.Sla_hl()
-	case 0x27: //SLA A
-		cpu.Sla_r(&cpu.R.A)
-	case 0x28: //SRA B
-		cpu.Sra_r(&cpu.R.B)
-	case 0x29: //SRA C
-		cpu.Sra_r(&cpu.R.C)
-	case 0x2A: //SRA D
-		cpu.Sra_r(&cpu.R.D)
-	case 0x2B: //SRA E
-		cpu.Sra_r(&cpu.R.E)
-	case 0x2C: //SRA H
-		cpu.Sra_r(&cpu.R.H)
-	case 0x2D: //SRA L
-		cpu.Sra_r(&cpu.R.L)
-	case 0x2E: //SRA (HL)
-		cpu.Sra_hl()
-	case 0x2F: //SRA A
-		cpu.Sra_r(&cpu.R.A)
-	case 0x30: //SWAP B
-		cpu.Swap_r(&cpu.R.B)
-	case 0x31: //SWAP C
-		cpu.Swap_r(&cpu.R.C)
-	case 0x32: //SWAP D
-		cpu.Swap_r(&cpu.R.D)
-	case 0x33: //SWAP E
-		cpu.Swap_r(&cpu.R.E)
-	case 0x34: //SWAP H
-		cpu.Swap_r(&cpu.R.H)
-	case 0x35: //SWAP L
-		cpu.Swap_r(&cpu.R.L)
-	case 0x36: //SWAP (HL)
-		cpu.Swap_hl()
-	case 0x37: //SWAP A
-		cpu.Swap_r(&cpu.R.A)
-	case 0x38: //SRL B
-		cpu.Srl_r(&cpu.R.B)
-	case 0x39: //SRL C
-		cpu.Srl_r(&cpu.R.C)
-	case 0x3A: //SRL D
-		cpu.Srl_r(&cpu.R.D)
-	case 0x3B: //SRL E
-		cpu.Srl_r(&cpu.R.E)
-	case 0x3C: //SRL H
-		cpu.Srl_r(&cpu.R.H)
-	case 0x3D: //SRL L
-		cpu.Srl_r(&cpu.R.L)
-	case 0x3E: //SRL (HL)
-		cpu.Srl_hl()
-	case 0x3F: //SRL A
-		cpu.Srl_r(&cpu.R.A)
-	case 0x40: //BIT 0, B
-		cpu.Bitb_r(0x00, &cpu.R.B)
-	case 0x41: //BIT 0, C
-		cpu.Bitb_r(0x00, &cpu.R.C)
-	case 0x42: //BIT 0, D
-		cpu.Bitb_r(0x00, &cpu.R.D)
-	case 0x43: //BIT 0, E
-		cpu.Bitb_r(0x00, &cpu.R.E)
-	case 0x44: //BIT 0, H
-		cpu.Bitb_r(0x00, &cpu.R.H)
-	case 0x45: //BIT 0, L
-		cpu.Bitb_r(0x00, &cpu.R.L)
-	case 0x46: //BIT 0, (HL)
-		cpu.Bitb_hl(0x00)
-	case 0x47: //BIT 0, A
-		cpu.Bitb_r(0x00, &cpu.R.A)
-	case 0x48: //BIT 1, B
-		cpu.Bitb_r(0x01, &cpu.R.B)
-	case 0x49: //BIT 1, C
-		cpu.Bitb_r(0x01, &cpu.R.C)
-	case 0x4A: //BIT 1, D
-		cpu.Bitb_r(0x01, &cpu.R.D)
-	case 0x4B: //BIT 1, E
-		cpu.Bitb_r(0x01, &cpu.R.E)
-	case 0x4C: //BIT 1, H
-		cpu.Bitb_r(0x01, &cpu.R.H)
-	case 0x4D: //BIT 1, L
-		cpu.Bitb_r(0x01, &cpu.R.L)
-	case 0x4E: //BIT 1, (HL)
-		cpu.Bitb_hl(0x01)
-	case 0x4F: //BIT 1, A
-		cpu.Bitb_r(0x01, &cpu.R.A)
-	case 0x50: //BIT 2, B
-		cpu.Bitb_r(0x02, &cpu.R.B)
-	case 0x51: //BIT 2, C
-		cpu.Bitb_r(0x02, &cpu.R.C)
-	case 0x52: //BIT 2, D
-		cpu.Bitb_r(0x02, &cpu.R.D)
-	case 0x53: //BIT 2, E
-		cpu.Bitb_r(0x02, &cpu.R.E)
-	case 0x54: //BIT 2, H
-		cpu.Bitb_r(0x02, &cpu.R.H)
-	case 0x55: //BIT 2, L
-		cpu.Bitb_r(0x02, &cpu.R.L)
-	case 0x56: //BIT 2, (HL)
-		cpu.Bitb_hl(0x02)
-	case 0x57: //BIT 2, A
-		cpu.Bitb_r(0x02, &cpu.R.A)
-	case 0x58: //BIT 3, B
-		cpu.Bitb_r(0x03, &cpu.R.B)
-	case 0x59: //BIT 3, C
-		cpu.Bitb_r(0x03, &cpu.R.C)
-	case 0x5A: //BIT 3, D
-		cpu.Bitb_r(0x03, &cpu.R.D)
-	case 0x5B: //BIT 3, E
-		cpu.Bitb_r(0x03, &cpu.R.E)
-	case 0x5C: //BIT 3, H
-		cpu.Bitb_r(0x03, &cpu.R.H)
-	case 0x5D: //BIT 3, L
-		cpu.Bitb_r(0x03, &cpu.R.L)
-	case 0x5E: //BIT 3, (HL)
-		cpu.Bitb_hl(0x03)
-	case 0x5F: //BIT 3, A
-		cpu.Bitb_r(0x03, &cpu.R.A)
-	case 0x60: //BIT 4, B
-		cpu.Bitb_r(0x04, &cpu.R.B)
-	case 0x61: //BIT 4, C
-		cpu.Bitb_r(0x04, &cpu.R.C)
-	case 0x62: //BIT 4, D
-		cpu.Bitb_r(0x04, &cpu.R.D)
-	case 0x63: //BIT 4, E
-		cpu.Bitb_r(0x04, &cpu.R.E)
-	case 0x64: //BIT 4, H
-		cpu.Bitb_r(0x04, &cpu.R.H)
-	case 0x65: //BIT 4, L
-		cpu.Bitb_r(0x04, &cpu.R.L)
-	case 0x66: //BIT 4, (HL)
-		cpu.Bitb_hl(0x04)
-	case 0x67: //BIT 4, A
-		cpu.Bitb_r(0x04, &cpu.R.A)
-	case 0x68: //BIT 5, B
-		cpu.Bitb_r(0x05, &cpu.R.B)
-	case 0x69: //BIT 5, C
-		cpu.Bitb_r(0x05, &cpu.R.C)
-	case 0x6A: //BIT 5, D
-		cpu.Bitb_r(0x05, &cpu.R.D)
-	case 0x6B: //BIT 5, E
-		cpu.Bitb_r(0x05, &cpu.R.E)
-	case 0x6C: //BIT 5, H
-		cpu.Bitb_r(0x05, &cpu.R.H)
-	case 0x6D: //BIT 5, L
-		cpu.Bitb_r(0x05, &cpu.R.L)
-	case 0x6E: //BIT 5, (HL)
-		cpu.Bitb_hl(0x05)
-	case 0x6F: //BIT 5, A
-		cpu.Bitb_r(0x05, &cpu.R.A)
-	case 0x70: //BIT 6, B
-		cpu.Bitb_r(0x06, &cpu.R.B)
-	case 0x71: //BIT 6, C
-		cpu.Bitb_r(0x06, &cpu.R.C)
-	case 0x72: //BIT 6, D
-		cpu.Bitb_r(0x06, &cpu.R.D)
-	case 0x73: //BIT 6, E
-		cpu.Bitb_r(0x06, &cpu.R.E)
-	case 0x74: //BIT 6, H
-		cpu.Bitb_r(0x06, &cpu.R.H)
-	case 0x75: //BIT 6, L
-		cpu.Bitb_r(0x06, &cpu.R.L)
-	case 0x76: //BIT 6, (HL)
-		cpu.Bitb_hl(0x06)
-	case 0x77: //BIT 6, A
-		cpu.Bitb_r(0x06, &cpu.R.A)
-	case 0x78: //BIT 7, B
-		cpu.Bitb_r(0x07, &cpu.R.B)
-	case 0x79: //BIT 7, C
-		cpu.Bitb_r(0x07, &cpu.R.C)
-	case 0x7A: //BIT 7, D
-		cpu.Bitb_r(0x07, &cpu.R.D)
-	case 0x7B: //BIT 7, E
-		cpu.Bitb_r(0x07, &cpu.R.E)
-	case 0x7C: //BIT 7, H
-		cpu.Bitb_r(0x07, &cpu.R.H)
-	case 0x7D: //BIT 7, L
-		cpu.Bitb_r(0x07, &cpu.R.L)
-	case 0x7E: //BIT 7, (HL)
-		cpu.Bitb_hl(0x07)
-	case 0x7F: //BIT 7, A
-		cpu.Bitb_r(0x07, &cpu.R.A)
-	case 0x80: //RES 0, B
-		cpu.Resb_r(0x00, &cpu.R.B)
-	case 0x81: //RES 0, C
-		cpu.Resb_r(0x00, &cpu.R.C)
-	case 0x82: //RES 0, D
-		cpu.Resb_r(0x00, &cpu.R.D)
-	case 0x83: //RES 0, E
-		cpu.Resb_r(0x00, &cpu.R.E)
-	case 0x84: //RES 0, H
-		cpu.Resb_r(0x00, &cpu.R.H)
-	case 0x85: //RES 0, L
-		cpu.Resb_r(0x00, &cpu.R.L)
-	case 0x86: //RES 0,(HL)
-		cpu.Resb_hl(0x00)
-	case 0x87: //RES 0, A
-		cpu.Resb_r(0x00, &cpu.R.A)
-	case 0x88: //RES 1, B
-		cpu.Resb_r(0x01, &cpu.R.B)
-	case 0x89: //RES 1, C
-		cpu.Resb_r(0x01, &cpu.R.C)
-	case 0x8A: //RES 1, D
-		cpu.Resb_r(0x01, &cpu.R.D)
-	case 0x8B: //RES 1, E
-		cpu.Resb_r(0x01, &cpu.R.E)
-	case 0x8C: //RES 1, H
-		cpu.Resb_r(0x01, &cpu.R.H)
-	case 0x8D: //RES 1, L
-		cpu.Resb_r(0x01, &cpu.R.L)
-	case 0x8E: //RES 1,(HL)
-		cpu.Resb_hl(0x01)
-	case 0x8F: //RES 1, A
-		cpu.Resb_r(0x01, &cpu.R.A)
-	case 0x90: //RES 2, B
-		cpu.Resb_r(0x02, &cpu.R.B)
-	case 0x91: //RES 2, C
-		cpu.Resb_r(0x02, &cpu.R.C)
-	case 0x92: //RES 2, D
-		cpu.Resb_r(0x02, &cpu.R.D)
-	case 0x93: //RES 2, E
-		cpu.Resb_r(0x02, &cpu.R.E)
-	case 0x94: //RES 2, H
-		cpu.Resb_r(0x02, &cpu.R.H)
-	case 0x95: //RES 2, L
-		cpu.Resb_r(0x02, &cpu.R.L)
-	case 0x96: //RES 2,(HL)
-		cpu.Resb_hl(0x02)
-	case 0x97: //RES 2, A
-		cpu.Resb_r(0x02, &cpu.R.A)
-	case 0x98: //RES 3, B
-		cpu.Resb_r(0x03, &cpu.R.B)
-	case 0x99: //RES 3, C
-		cpu.Resb_r(0x03, &cpu.R.C)
-	case 0x9A: //RES 3, D
-		cpu.Resb_r(0x03, &cpu.R.D)
-	case 0x9B: //RES 3, E
-		cpu.Resb_r(0x03, &cpu.R.E)
-	case 0x9C: //RES 3, H
-		cpu.Resb_r(0x03, &cpu.R.H)
-	case 0x9D: //RES 3, L
-		cpu.Resb_r(0x03, &cpu.R.L)
-	case 0x9E: //RES 3,(HL)
-		cpu.Resb_hl(0x03)
-	case 0x9F: //RES 3, A
-		cpu.Resb_r(0x03, &cpu.R.A)
-	case 0xA0: //RES 4, B
-		cpu.Resb_r(0x04, &cpu.R.B)
-	case 0xA1: //RES 4, C
-		cpu.Resb_r(0x04, &cpu.R.C)
-	case 0xA2: //RES 4, D
-		cpu.Resb_r(0x04, &cpu.R.D)
-	case 0xA3: //RES 4, E
-		cpu.Resb_r(0x04, &cpu.R.E)
-	case 0xA4: //RES 4, H
-		cpu.Resb_r(0x04, &cpu.R.H)
-	case 0xA5: //RES 4, L
-		cpu.Resb_r(0x04, &cpu.R.L)
-	case 0xA6: //RES 4,(HL)
-		cpu.Resb_hl(0x04)
-	case 0xA7: //RES 4, A
-		cpu.Resb_r(0x04, &cpu.R.A)
-	case 0xA8: //RES 5, B
-		cpu.Resb_r(0x05, &cpu.R.B)
-	case 0xA9: //RES 5, C
-		cpu.Resb_r(0x05, &cpu.R.C)
-	case 0xAA: //RES 5, D
-		cpu.Resb_r(0x05, &cpu.R.D)
-	case 0xAB: //RES 5, E
-		cpu.Resb_r(0x05, &cpu.R.E)
-	case 0xAC: //RES 5, H
-		cpu.Resb_r(0x05, &cpu.R.H)
-	case 0xAD: //RES 5, L
-		cpu.Resb_r(0x05, &cpu.R.L)
-	case 0xAE: //RES 5,(HL)
-		cpu.Resb_hl(0x05)
-	case 0xAF: //RES 5, A
-		cpu.Resb_r(0x05, &cpu.R.A)
-	case 0xB0: //RES 6, B
-		cpu.Resb_r(0x06, &cpu.R.B)
-	case 0xB1: //RES 6, C
-		cpu.Resb_r(0x06, &cpu.R.C)
-	case 0xB2: //RES 6, D
-		cpu.Resb_r(0x06, &cpu.R.D)
-	case 0xB3: //RES 6, E
-		cpu.Resb_r(0x06, &cpu.R.E)
-	case 0xB4: //RES 6, H
-		cpu.Resb_r(0x06, &cpu.R.H)
-	case 0xB5: //RES 6, L
-		cpu.Resb_r(0x06, &cpu.R.L)
-	case 0xB6: //RES 6,(HL)
-		cpu.Resb_hl(0x06)
-	case 0xB7: //RES 6, A
-		cpu.Resb_r(0x06, &cpu.R.A)
-	case 0xB8: //RES 7, B
-		cpu.Resb_r(0x07, &cpu.R.B)
-	case 0xB9: //RES 7, C
-		cpu.Resb_r(0x07, &cpu.R.C)
-	case 0xBA: //RES 7, D
-		cpu.Resb_r(0x07, &cpu.R.D)
-	case 0xBB: //RES 7, E
-		cpu.Resb_r(0x07, &cpu.R.E)
-	case 0xBC: //RES 7, H
-		cpu.Resb_r(0x07, &cpu.R.H)
-	case 0xBD: //RES 7, L
-		cpu.Resb_r(0x07, &cpu.R.L)
-	case 0xBE: //RES 7,(HL)
-		cpu.Resb_hl(0x07)
-	case 0xBF: //RES 7, A
-		cpu.Resb_r(0x07, &cpu.R.A)
-	case 0xC0: //SET 0, B
-		cpu.Setb_r(0x00, &cpu.R.B)
-	case 0xC1: //SET 0, C
-		cpu.Setb_r(0x00, &cpu.R.C)
-	case 0xC2: //SET 0, D
-		cpu.Setb_r(0x00, &cpu.R.D)
-	case 0xC3: //SET 0, E
-		cpu.Setb_r(0x00, &cpu.R.E)
-	case 0xC4: //SET 0, H
-		cpu.Setb_r(0x00, &cpu.R.H)
-	case 0xC5: //SET 0, L
-		cpu.Setb_r(0x00, &cpu.R.L)
-	case 0xC6: //SET 0, (HL)
-		cpu.Setb_hl(0x00)
-	case 0xC7: //SET 0, A
-		cpu.Setb_r(0x00, &cpu.R.A)
-	case 0xC8: //SET 1, B
-		cpu.Setb_r(0x01, &cpu.R.B)
-	case 0xC9: //SET 1, C
-		cpu.Setb_r(0x01, &cpu.R.C)
-	case 0xCA: //SET 1, D
-		cpu.Setb_r(0x01, &cpu.R.D)
-	case 0xCB: //SET 1, E
-		cpu.Setb_r(0x01, &cpu.R.E)
-	case 0xCC: //SET 1, H
-		cpu.Setb_r(0x01, &cpu.R.H)
-	case 0xCD: //SET 1, L
-		cpu.Setb_r(0x01, &cpu.R.L)
-	case 0xCE: //SET 1, (HL)
-		cpu.Setb_hl(0x01)
-	case 0xCF: //SET 1, A
-		cpu.Setb_r(0x01, &cpu.R.A)
-	case 0xD0: //SET 2, B
-		cpu.Setb_r(0x02, &cpu.R.B)
-	case 0xD1: //SET 2, C
-		cpu.Setb_r(0x02, &cpu.R.C)
-	case 0xD2: //SET 2, D
-		cpu.Setb_r(0x02, &cpu.R.D)
-	case 0xD3: //SET 2, E
-		cpu.Setb_r(0x02, &cpu.R.E)
-	case 0xD4: //SET 2, H
-		cpu.Setb_r(0x02, &cpu.R.H)
-	case 0xD5: //SET 2, L
-		cpu.Setb_r(0x02, &cpu.R.L)
-	case 0xD6: //SET 2, (HL)
-		cpu.Setb_hl(0x02)
-	case 0xD7: //SET 2, A
-		cpu.Setb_r(0x02, &cpu.R.A)
-	case 0xD8: //SET 3, B
-		cpu.Setb_r(0x03, &cpu.R.B)
-	case 0xD9: //SET 3, C
-		cpu.Setb_r(0x03, &cpu.R.C)
-	case 0xDA: //SET 3, D
-		cpu.Setb_r(0x03, &cpu.R.D)
-	case 0xDB: //SET 3, E
-		cpu.Setb_r(0x03, &cpu.R.E)
-	case 0xDC: //SET 3, H
-		cpu.Setb_r(0x03, &cpu.R.H)
-	case 0xDD: //SET 3, L
-		cpu.Setb_r(0x03, &cpu.R.L)
-	case 0xDE: //SET 3, (HL)
-		cpu.Setb_hl(0x03)
-	case 0xDF: //SET 3, A
-		cpu.Setb_r(0x03, &cpu.R.A)
-	case 0xE0: //SET 4, B
-		cpu.Setb_r(0x04, &cpu.R.B)
-	case 0xE1: //SET 4, C
-		cpu.Setb_r(0x04, &cpu.R.C)
-	case 0xE2: //SET 4, D
-		cpu.Setb_r(0x04, &cpu.R.D)
-	case 0xE3: //SET 4, E
-		cpu.Setb_r(0x04, &cpu.R.E)
-	case 0xE4: //SET 4, H
-		cpu.Setb_r(0x04, &cpu.R.H)
-	case 0xE5: //SET 4, L
-		cpu.Setb_r(0x04, &cpu.R.L)
-	case 0xE6: //SET 4, (HL)
-		cpu.Setb_hl(0x04)
-	case 0xE7: //SET 4, A
-		cpu.Setb_r(0x04, &cpu.R.A)
-	case 0xE8: //SET 5, B
-		cpu.Setb_r(0x05, &cpu.R.B)
-	case 0xE9: //SET 5, C
-		cpu.Setb_r(0x05, &cpu.R.C)
-	case 0xEA: //SET 5, D
-		cpu.Setb_r(0x05, &cpu.R.D)
-	case 0xEB: //SET 5, E
-		cpu.Setb_r(0x05, &cpu.R.E)
-	case 0xEC: //SET 5, H
-		cpu.Setb_r(0x05, &cpu.R.H)
-	case 0xED: //SET 5, L
-		cpu.Setb_r(0x05, &cpu.R.L)
-	case 0xEE: //SET 5, (HL)
-		cpu.Setb_hl(0x05)
-	case 0xEF: //SET 5, A
-		cpu.Setb_r(0x05, &cpu.R.A)
-	case 0xF0: //SET 6, B
-		cpu.Setb_r(0x06, &cpu.R.B)
-	case 0xF1: //SET 6, C
-		cpu.Setb_r(0x06, &cpu.R.C)
-	case 0xF2: //SET 6, D
-		cpu.Setb_r(0x06, &cpu.R.D)
-	case 0xF3: //SET 6, E
-		cpu.Setb_r(0x06, &cpu.R.E)
-	case 0xF4: //SET 6, H
-		cpu.Setb_r(0x06, &cpu.R.H)
-	case 0xF5: //SET 6, L
-		cpu.Setb_r(0x06, &cpu.R.L)
-	case 0xF6: //SET 6, (HL)
-		cpu.Setb_hl(0x06)
-	case 0xF7: //SET 6, A
-		cpu.Setb_r(0x06, &cpu.R.A)
-	case 0xF8: //SET 7, B
-		cpu.Setb_r(0x07, &cpu.R.B)
-	case 0xF9: //SET 7, C
-		cpu.Setb_r(0x07, &cpu.R.C)
-	case 0xFA: //SET 7, D
-		cpu.Setb_r(0x07, &cpu.R.D)
-	case 0xFB: //SET 7, E
-		cpu.Setb_r(0x07, &cpu.R.E)
-	case 0xFC: //SET 7, H
-		cpu.Setb_r(0x07, &cpu.R.H)
-	case 0xFD: //SET 7, L
-		cpu.Setb_r(0x07, &cpu.R.L)
-	case 0xFE: //SET 7, (HL)
-		cpu.Setb_hl(0x07)
-	case 0xFF: //SET 7, A
-		cpu.Setb_r(0x07, &cpu.R.A)
-
-	default:
-		log.Fatalf(PREFIX+" Invalid/Unknown instruction %X", Opcode)
-	}
-}
-
-func (cpu *GbcCPU) Dispatch(Opcode byte) {
-	switch Opcode {
-	case 0x00: //NOP
-		cpu.NOP()
-	case 0x01: //LD BC, nn
-		cpu.LDn_nn(&cpu.R.B, &cpu.R.C)
-	case 0x02: //LD (BC), A
-		cpu.LDrr_r(&cpu.R.B, &cpu.R.C, &cpu.R.A)
-	case 0x03: //INC BC
-		cpu.Inc_rr(&cpu.R.B, &cpu.R.C)
-	case 0x04: //INC B
-		cpu.Inc_r(&cpu.R.B)
-	case 0x05: //DEC B
-		cpu.Dec_r(&cpu.R.B)
-	case 0x06: //LD B,n
-		cpu.LDrn(&cpu.R.B)
-	case 0x07: //RLCA
-		cpu.RLCA()
-	case 0x08: //LD nn, SP
-		cpu.LDnn_SP()
-	case 0x09: //ADD HL,BC
-		cpu.Addhl_rr(&cpu.R.B, &cpu.R.C)
-	case 0x0A: //LD A, (BC)
-		cpu.LDr_rr(&cpu.R.B, &cpu.R.C, &cpu.R.A)
-	case 0x0B: //DEC BC
-		cpu.Dec_rr(&cpu.R.B, &cpu.R.C)
-	case 0x0C: //INC C
-		cpu.Inc_r(&cpu.R.C)
-	case 0x0D: //DEC C
-		cpu.Dec_r(&cpu.R.C)
-	case 0x0E: //LD C,n
-		cpu.LDrn(&cpu.R.C)
-	case 0x0F: //RRCA
-		cpu.RRCA()
-	case 0x10: //STOP
-		cpu.Stop()
-	case 0x11: //LD DE, nn
-		cpu.LDn_nn(&cpu.R.D, &cpu.R.E)
-	case 0x12: //LD (DE), A
-		cpu.LDrr_r(&cpu.R.D, &cpu.R.E, &cpu.R.A)
-	case 0x13: //INC DE
-		cpu.Inc_rr(&cpu.R.D, &cpu.R.E)
-	case 0x14: //INC D
-		cpu.Inc_r(&cpu.R.D)
-	case 0x15: //DEC D
-		cpu.Dec_r(&cpu.R.D)
-	case 0x16: //LD D,n
-		cpu.LDrn(&cpu.R.D)
-	case 0x17: //RLA
-		cpu.RLA()
-	case 0x18: //JR n
-		cpu.JR_n()
-	case 0x19: //ADD HL,DE
-		cpu.Addhl_rr(&cpu.R.D, &cpu.R.E)
-	case 0x1A: //LD A, (DE)
-		cpu.LDr_rr(&cpu.R.D, &cpu.R.E, &cpu.R.A)
-	case 0x1B: //DEC DE
-		cpu.Dec_rr(&cpu.R.D, &cpu.R.E)
-	case 0x1C: //INC E
-		cpu.Inc_r(&cpu.R.E)
-	case 0x1D: //DEC E
-		cpu.Dec_r(&cpu.R.E)
-	case 0x1E: //LD E,n
-		cpu.LDrn(&cpu.R.E)
-	case 0x1F: //RRA
-		cpu.RRA()
-	case 0x20: //JR NZ,n
-		cpu.JRcc_nn(Z, false)
-	case 0x21: //LD HL, nn
-		cpu.LDn_nn(&cpu.R.H, &cpu.R.L)
-	case 0x22: //LDI (HL), A
-		cpu.LDIhl_r(&cpu.R.A)
-	case 0x23: //INC HL
-		cpu.Inc_rr(&cpu.R.H, &cpu.R.L)
-	case 0x24: //INC H
-		cpu.Inc_r(&cpu.R.H)
-	case 0x25: //DEC H
-		cpu.Dec_r(&cpu.R.H)
-	case 0x26: //LD H,n
-		cpu.LDrn(&cpu.R.H)
-	case 0x27: //DAA
-		cpu.Daa()
-	case 0x28: //JR Z,n
-		cpu.JRcc_nn(Z, true)
-	case 0x29: //ADD HL,HL
-		cpu.Addhl_rr(&cpu.R.H, &cpu.R.L)
-	case 0x2A: //LDI A, (HL)
-		cpu.LDIr_hl(&cpu.R.A)
-	case 0x2B: //DEC HL
-		cpu.Dec_rr(&cpu.R.H, &cpu.R.L)
-	case 0x2C: //INC L
-		cpu.Inc_r(&cpu.R.L)
-	case 0x2D: //DEC L
-		cpu.Dec_r(&cpu.R.L)
-	case 0x2E: //LD L,n
-		cpu.LDrn(&cpu.R.L)
-	case 0x2F: //CPL
-		cpu.CPL()
-	case 0x30: //JR NZ,n
-		cpu.JRcc_nn(C, false)
-	case 0x31: //LD SP, nn
-		cpu.LDSP_nn()
-	case 0x32: //LDD (HL), A
-		cpu.LDDhl_r(&cpu.R.A)
-	case 0x33: //INC SP
-		cpu.Inc_sp()
-	case 0x34: //INC (HL)
-		cpu.Inc_hl()
-	case 0x35: //DEC (HL)
-		cpu.Dec_hl()
-	case 0x36: //LD (HL), n
-		cpu.LDhl_n()
-	case 0x37: //SCF
-		cpu.SCF()
-	case 0x38: //JR C,n
-		cpu.JRcc_nn(C, true)
-	case 0x39: //ADD HL,SP
-		cpu.Addhl_sp()
-	case 0x3A: //LDD A, (HL)
-		cpu.LDDr_hl(&cpu.R.A)
-	case 0x3B: //DEC SP
-		cpu.Dec_sp()
-	case 0x3C: //INC A
-		cpu.Inc_r(&cpu.R.A)
-	case 0x3D: //DEC A
-		cpu.Dec_r(&cpu.R.A)
-	case 0x3E: //LD A, n
-		cpu.LDrn(&cpu.R.A)
-	case 0x3F: //CCF
-		cpu.CCF()
-	case 0x40: //LD B, B
-		cpu.LDrr(&cpu.R.B, &cpu.R.B)
-	case 0x41: //LD B, C
-		cpu.LDrr(&cpu.R.B, &cpu.R.C)
-	case 0x42: //LD B, D
-		cpu.LDrr(&cpu.R.B, &cpu.R.D)
-	case 0x43: //LD B, E
-		cpu.LDrr(&cpu.R.B, &cpu.R.E)
-	case 0x44: //LD B, H
-		cpu.LDrr(&cpu.R.B, &cpu.R.H)
-	case 0x45: //LD B, L
-		cpu.LDrr(&cpu.R.B, &cpu.R.L)
-	case 0x46: //LD B, (HL)
-		cpu.LDr_rr(&cpu.R.H, &cpu.R.L, &cpu.R.B)
-	case 0x47: //LD B, A
-		cpu.LDrr(&cpu.R.B, &cpu.R.A)
-	case 0x48: //LD C, B
-		cpu.LDrr(&cpu.R.C, &cpu.R.B)
-	case 0x49: //LD C, C
-		cpu.LDrr(&cpu.R.C, &cpu.R.C)
-	case 0x4A: //LD C, D
-		cpu.LDrr(&cpu.R.C, &cpu.R.D)
-	case 0x4B: //LD C, E
-		cpu.LDrr(&cpu.R.C, &cpu.R.E)
-	case 0x4C: //LD C, H
-		cpu.LDrr(&cpu.R.C, &cpu.R.H)
-	case 0x4D: //LD C, L
-		cpu.LDrr(&cpu.R.C, &cpu.R.L)
-	case 0x4E: //LD C, (HL)
-		cpu.LDr_rr(&cpu.R.H, &cpu.R.L, &cpu.R.C)
-	case 0x4F: //LD C, A
-		cpu.LDrr(&cpu.R.C, &cpu.R.A)
-	case 0x50: //LD D, B
-		cpu.LDrr(&cpu.R.D, &cpu.R.B)
-	case 0x51: //LD D, C
-		cpu.LDrr(&cpu.R.D, &cpu.R.C)
-	case 0x52: //LD D, D
-		cpu.LDrr(&cpu.R.D, &cpu.R.D)
-	case 0x53: //LD D, E
-		cpu.LDrr(&cpu.R.D, &cpu.R.E)
-	case 0x54: //LD D, H
-		cpu.LDrr(&cpu.R.D, &cpu.R.H)
-	case 0x55: //LD D, L
-		cpu.LDrr(&cpu.R.D, &cpu.R.L)
-	case 0x56: //LD D, (HL)
-		cpu.LDr_rr(&cpu.R.H, &cpu.R.L, &cpu.R.D)
-	case 0x57: //LD D, A
-		cpu.LDrr(&cpu.R.D, &cpu.R.A)
-	case 0x58: //LD E, B
-		cpu.LDrr(&cpu.R.E, &cpu.R.B)
-	case 0x59: //LD E, C
-		cpu.LDrr(&cpu.R.E, &cpu.R.C)
-	case 0x5A: //LD E, D
-		cpu.LDrr(&cpu.R.E, &cpu.R.D)
-	case 0x5B: //LD E, E
-		cpu.LDrr(&cpu.R.E, &cpu.R.E)
-	case 0x5C: //LD E, H
-		cpu.LDrr(&cpu.R.E, &cpu.R.H)
-	case 0x5D: //LD E, L
-		cpu.LDrr(&cpu.R.E, &cpu.R.L)
-	case 0x5E: //LD E, (HL)
-		cpu.LDr_rr(&cpu.R.H, &cpu.R.L, &cpu.R.E)
-	case 0x5F: //LD E, A
-		cpu.LDrr(&cpu.R.E, &cpu.R.A)
-	case 0x60: //LD H, B
-		cpu.LDrr(&cpu.R.H, &cpu.R.B)
-	case 0x61: //LD H, C
-		cpu.LDrr(&cpu.R.H, &cpu.R.C)
-	case 0x62: //LD H, D
-		cpu.LDrr(&cpu.R.H, &cpu.R.D)
-	case 0x63: //LD H, E
-		cpu.LDrr(&cpu.R.H, &cpu.R.E)
-	case 0x64: //LD H, H
-		cpu.LDrr(&cpu.R.H, &cpu.R.H)
-	case 0x65: //LD H, L
-		cpu.LDrr(&cpu.R.H, &cpu.R.L)
-	case 0x66: //LD H, (HL)
-		cpu.LDr_rr(&cpu.R.H, &cpu.R.L, &cpu.R.H)
-	case 0x67: //LD H, A
-		cpu.LDrr(&cpu.R.H, &cpu.R.A)
-	case 0x68: //LD L, B
-		cpu.LDrr(&cpu.R.L, &cpu.R.B)
-	case 0x69: //LD L, C
-		cpu.LDrr(&cpu.R.L, &cpu.R.C)
-	case 0x6A: //LD L, D
-		cpu.LDrr(&cpu.R.L, &cpu.R.D)
-	case 0x6B: //LD L, E
-		cpu.LDrr(&cpu.R.L, &cpu.R.E)
-	case 0x6C: //LD L, H
-		cpu.LDrr(&cpu.R.L, &cpu.R.H)
-	case 0x6D: //LD L, L
-		cpu.LDrr(&cpu.R.L, &cpu.R.L)
-	case 0x6E: //LD L, (HL)
-		cpu.LDr_rr(&cpu.R.H, &cpu.R.L, &cpu.R.L)
-	case 0x6F: //LD L, A
-		cpu.LDrr(&cpu.R.L, &cpu.R.A)
-	case 0x70: //LD (HL), B
-		cpu.LDrr_r(&cpu.R.H, &cpu.R.L, &cpu.R.B)
-	case 0x71: //LD (HL), C
-		cpu.LDrr_r(&cpu.R.H, &cpu.R.L, &cpu.R.C)
-	case 0x72: //LD (HL), D
-		cpu.LDrr_r(&cpu.R.H, &cpu.R.L, &cpu.R.D)
-	case 0x73: //LD (HL), E
-		cpu.LDrr_r(&cpu.R.H, &cpu.R.L, &cpu.R.E)
-	case 0x74: //LD (HL), H
-		cpu.LDrr_r(&cpu.R.H, &cpu.R.L, &cpu.R.H)
-	case 0x75: //LD (HL), L
-		cpu.LDrr_r(&cpu.R.H, &cpu.R.L, &cpu.R.L)
-	case 0x76: //HALT
-		cpu.HALT()
-	case 0x77: //LD (HL), A
-		cpu.LDrr_r(&cpu.R.H, &cpu.R.L, &cpu.R.A)
-	case 0x78: //LD A, B
-		cpu.LDrr(&cpu.R.A, &cpu.R.B)
-	case 0x79: //LD A, C
-		cpu.LDrr(&cpu.R.A, &cpu.R.C)
-	case 0x7A: //LD A, D
-		cpu.LDrr(&cpu.R.A, &cpu.R.D)
-	case 0x7B: //LD A, E
-		cpu.LDrr(&cpu.R.A, &cpu.R.E)
-	case 0x7C: //LD A, H
-		cpu.LDrr(&cpu.R.A, &cpu.R.H)
-	case 0x7D: //LD A, L
-		cpu.LDrr(&cpu.R.A, &cpu.R.L)
-	case 0x7E: //LD A, (HL)
-		cpu.LDr_rr(&cpu.R.H, &cpu.R.L, &cpu.R.A)
-	case 0x7F: //LD A, A
-		cpu.LDrr(&cpu.R.A, &cpu.R.A)
-	case 0x80: //ADD A, B
-		cpu.AddA_r(&cpu.R.B)
-	case 0x81: //ADD A, C
-		cpu.AddA_r(&cpu.R.C)
-	case 0x82: //ADD A, D
-		cpu.AddA_r(&cpu.R.D)
-	case 0x83: //ADD A, E
-		cpu.AddA_r(&cpu.R.E)
-	case 0x84: //ADD A, H
-		cpu.AddA_r(&cpu.R.H)
-	case 0x85: //ADD A, L
-		cpu.AddA_r(&cpu.R.L)
-	case 0x86: //ADD A,(HL)
-		cpu.AddA_hl()
-	case 0x87: //ADD A, A
-		cpu.AddA_r(&cpu.R.A)
-	case 0x88: //ADC A, B
-		cpu.AddCA_r(&cpu.R.B)
-	case 0x89: //ADC A, C
-		cpu.AddCA_r(&cpu.R.C)
-	case 0x8A: //ADC A, D
-		cpu.AddCA_r(&cpu.R.D)
-	case 0x8B: //ADC A, E
-		cpu.AddCA_r(&cpu.R.E)
-	case 0x8C: //ADC A, H
-		cpu.AddCA_r(&cpu.R.H)
-	case 0x8D: //ADC A, L
-		cpu.AddCA_r(&cpu.R.L)
-	case 0x8E: //ADC A, (HL)
-		cpu.AddCA_hl()
-	case 0x8F: //ADC A, A
-		cpu.AddCA_r(&cpu.R.A)
-	case 0x90: // SUB A, B
-		cpu.SubA_r(&cpu.R.B)
-	case 0x91: // SUB A, C
-		cpu.SubA_r(&cpu.R.C)
-	case 0x92: // SUB A, D
-		cpu.SubA_r(&cpu.R.D)
-	case 0x93: // SUB A, E
-		cpu.SubA_r(&cpu.R.E)
-	case 0x94: // SUB A, H
-		cpu.SubA_r(&cpu.R.H)
-	case 0x95: // SUB A, L
-		cpu.SubA_r(&cpu.R.L)
-	case 0x96: //SUB A, (HL)
-		cpu.SubA_hl()
-	case 0x97: // SUB A, A
-		cpu.SubA_r(&cpu.R.A)
-	case 0x98: // SBC A, B
-		cpu.SubAC_r(&cpu.R.B)
-	case 0x99: // SBC A, C
-		cpu.SubAC_r(&cpu.R.C)
-	case 0x9A: // SBC A, D
-		cpu.SubAC_r(&cpu.R.D)
-	case 0x9B: // SBC A, E
-		cpu.SubAC_r(&cpu.R.E)
-	case 0x9C: // SBC A, H
-		cpu.SubAC_r(&cpu.R.H)
-	case 0x9D: // SBC A, L
-		cpu.SubAC_r(&cpu.R.L)
-	case 0x9E: //SBC A, (HL)
-		cpu.SubAC_hl()
-	case 0x9F: // SBC A, A
-		cpu.SubAC_r(&cpu.R.A)
-	case 0xA0: //AND A, B
-		cpu.AndA_r(&cpu.R.B)
-	case 0xA1: //AND A, C
-		cpu.AndA_r(&cpu.R.C)
-	case 0xA2: //AND A, D
-		cpu.AndA_r(&cpu.R.D)
-	case 0xA3: //AND A, E
-		cpu.AndA_r(&cpu.R.E)
-	case 0xA4: //AND A, H
-		cpu.AndA_r(&cpu.R.H)
-	case 0xA5: //AND A, L
-		cpu.AndA_r(&cpu.R.L)
-	case 0xA6: //AND A, (HL)
-		cpu.AndA_hl()
-	case 0xA7: //AND A, A
-		cpu.AndA_r(&cpu.R.A)
-	case 0xA8: //XOR A, B
-		cpu.XorA_r(&cpu.R.B)
-	case 0xA9: //XOR A, C
-		cpu.XorA_r(&cpu.R.C)
-	case 0xAA: //XOR A, D
-		cpu.XorA_r(&cpu.R.D)
-	case 0xAB: //XOR A, E
-		cpu.XorA_r(&cpu.R.E)
-	case 0xAC: //XOR A, H
-		cpu.XorA_r(&cpu.R.H)
-	case 0xAD: //XOR A, L
-		cpu.XorA_r(&cpu.R.L)
-	case 0xAE: //XOR A,(HL)
-		cpu.XorA_hl()
-	case 0xAF: //XOR A, A
-		cpu.XorA_r(&cpu.R.A)
-	case 0xB0: //OR A, B
-		cpu.OrA_r(&cpu.R.B)
-	case 0xB1: //OR A, C
-		cpu.OrA_r(&cpu.R.C)
-	case 0xB2: //OR A, D
-		cpu.OrA_r(&cpu.R.D)
-	case 0xB3: //OR A, E
-		cpu.OrA_r(&cpu.R.E)
-	case 0xB4: //OR A, H
-		cpu.OrA_r(&cpu.R.H)
-	case 0xB5: //OR A, L
-		cpu.OrA_r(&cpu.R.L)
-	case 0xB6: //OR A,(HL)
-		cpu.OrA_hl()
-	case 0xB7: //OR A, A
-		cpu.OrA_r(&cpu.R.A)
-	case 0xB8: //CP A, B
-		cpu.CPA_r(&cpu.R.B)
-	case 0xB9: //CP A, C
-		cpu.CPA_r(&cpu.R.C)
-	case 0xBA: //CP A, D
-		cpu.CPA_r(&cpu.R.D)
-	case 0xBB: //CP A, E
-		cpu.CPA_r(&cpu.R.E)
-	case 0xBC: //CP A, H
-		cpu.CPA_r(&cpu.R.H)
-	case 0xBD: //CP A, L
-		cpu.CPA_r(&cpu.R.L)
-	case 0xBE: //CP A, (HL)
-		cpu.CPA_hl()
-	case 0xBF: //CP A, A
-		cpu.CPA_r(&cpu.R.A)
-	case 0xC0: //RET NZ
-		cpu.Retcc(Z, false)
-	case 0xC1: //POP BC
-		cpu.Pop_nn(&cpu.R.B, &cpu.R.C)
-	case 0xC2: //JP NZ,nn
-		cpu.JPcc_nn(Z, false)
-	case 0xC3: //JP nn
-		cpu.JP_nn()
-	case 0xC4: //CALL NZ, nn
-		cpu.Callcc_nn(Z, false)
-	case 0xC5: //PUSH BC
-		cpu.Push_nn(&cpu.R.B, &cpu.R.C)
-	case 0xC6: //ADD A,#
-		cpu.AddA_n()
-	case 0xC7: //RST n
-		cpu.Rst(0x00)
-	case 0xC8: //RET Z
-		cpu.Retcc(Z, true)
-	case 0xC9: //RET
-		cpu.Ret()
-	case 0xCA: //JP Z,nn
-		cpu.JPcc_nn(Z, true)
-	case 0xCC: //CALL Z, nn
-		cpu.Callcc_nn(Z, true)
-	case 0xCD: //CALL nn
-		cpu.Call_nn()
-	case 0xCE: //ADC A, n
-		cpu.AddCA_n()
-	case 0xCF: //RST n
-		cpu.Rst(0x08)
-	case 0xD0: //RET NC
-		cpu.Retcc(C, false)
-	case 0xD1: //POP DE
-		cpu.Pop_nn(&cpu.R.D, &cpu.R.E)
-	case 0xD2: //JP NC,nn
-		cpu.JPcc_nn(C, false)
-	case 0xD4: //CALL NC, nn
-		cpu.Callcc_nn(C, false)
-	case 0xD5: //PUSH DE
-		cpu.Push_nn(&cpu.R.D, &cpu.R.E)
-	case 0xD6: //SUB A, n
-		cpu.SubA_n()
-	case 0xD7: //RST n
-		cpu.Rst(0x10)
-	case 0xD8: //RET C
-		cpu.Retcc(C, true)
-	case 0xD9: //RETI
-		cpu.Ret_i()
-	case 0xDA: //JP C,nn
-		cpu.JPcc_nn(C, true)
-	case 0xDC: //CALL C, nn
-		cpu.Callcc_nn(C, true)
-	case 0xDE: //SBC A, n
-		cpu.SubAC_n()
-	case 0xDF: //RST n
-		cpu.Rst(0x18)
-	case 0xE0: //LDH n, A
-		cpu.LDHn_r(&cpu.R.A)
-	case 0xE1: //POP HL
-		cpu.Pop_nn(&cpu.R.H, &cpu.R.L)
-	case 0xE2: //LD (C),A
-		cpu.LDffplusc_r(&cpu.R.A)
-	case 0xE5: //PUSH HL
-		cpu.Push_nn(&cpu.R.H, &cpu.R.L)
-	case 0xE6: //AND A, n
-		cpu.AndA_n()
-	case 0xE7: //RST n
-		cpu.Rst(0x20)
-	case 0xE8: //ADD SP,n
-		cpu.Addsp_n()
-	case 0xE9: //JP (HL)
-		cpu.JP_hl()
-	case 0xEA: //LD (nn), A
-		cpu.LDnn_r(&cpu.R.A)
-	case 0xEE: //XOR A, n
-		cpu.XorA_n()
-	case 0xEF: //RST n
-		cpu.Rst(0x28)
-	case 0xF0: //LDH r, n
-		cpu.LDHr_n(&cpu.R.A)
-	case 0xF1: //POP AF
-		cpu.Pop_AF()
-	case 0xF2: //LD A,(C)
-		cpu.LDr_ffplusc(&cpu.R.A)
-	case 0xF3: //DI
-		cpu.DI()
-	case 0xF5: //PUSH AF
-		cpu.Push_nn(&cpu.R.A, &cpu.R.F)
-	case 0xF6: //OR A, n
-		cpu.OrA_n()
-	case 0xF7: //RST n
-		cpu.Rst(0x30)
-	case 0xF8: //LDHL SP, n
-		cpu.LDHLSP_n()
-	case 0xF9: //LD SP, HL
-		cpu.LDSP_hl()
-	case 0xFA: //LD A, (nn)
-		cpu.LDr_nn(&cpu.R.A)
-	case 0xFB: //EI
-		cpu.EI()
-	case 0xFE: //CP A, n
-		cpu.CPA_n()
-	case 0xFF: //RST n
-		cpu.Rst(0x38)
-	default:
-		log.Fatalf(PREFIX+" Invalid/Unknown instruction %X", Opcode)
-	}
-}
-
+//Executes the next instruction, ticking the rest of the system as each M-cycle of it
+//passes. Returns the number of M-cycles spent
 func (cpu *GbcCPU) Step() int {
 	cpu.LastInstrCycle.Reset()
 	var opcode byte
-	var ok bool = false
 
-	if !cpu.Halted {
+	if !cpu.Halted && !cpu.Stopped && !cpu.LockedUp {
 		cpu.CheckForInterrupts()
-		opcode = cpu.ReadByte(cpu.PC)
-		ok = false
+		if cpu.tracer != nil {
+			cpu.tracer()
+		}
+		var start int = cpu.LastInstrCycle.M
+		opcode = cpu.readByte(cpu.PC)
+		var h *handler = &cpu.handlers[opcode]
+		if h.execute == nil && opcode != 0xCB {
+			cpu.lockUp(opcode)
+			return cpu.LastInstrCycle.M
+		}
+
+		//the HALT bug stops the PC moving past the opcode, so the byte is read again
+		//as the start of the operands (or as the opcode after a CB prefix)
+		if cpu.HaltBug {
+			cpu.HaltBug = false
+			cpu.PC--
+		}
 
 		if opcode == 0xCB {
 			cpu.IncrementPC(1)
-			opcode = cpu.ReadByte(cpu.PC)
-			cpu.CurrentInstruction, ok = cpu.DecodeCB(opcode)
-			if !ok {
-				panic(fmt.Sprintf("No instruction found for opcode: %X\n%s", opcode, cpu.String()))
-			}
-			cpu.CurrentInstruction = cpu.Compile(cpu.CurrentInstruction)
-			cpu.DispatchCB(opcode)
-		} else {
-			cpu.CurrentInstruction, ok = cpu.Decode(opcode)
-			if !ok {
-				panic(fmt.Sprintf("No instruction found for opcode: %X\n%s", opcode, cpu.String()))
-			}
-			cpu.CurrentInstruction = cpu.Compile(cpu.CurrentInstruction)
-			cpu.Dispatch(opcode)
+			h = &cpu.handlersCB[cpu.readByte(cpu.PC)]
 		}
 
+		cpu.CurrentInstruction = h.instruction
+		cpu.instructionCycles = h.cycles
+		cpu.fetchOperands(h.operandsSize)
+		h.execute()
+
 		//this is put in place to check whether the PC has been altered by an instruction. If it has then don't
 		//do any incrementing
 		if cpu.PCJumped == false {
-			cpu.IncrementPC(cpu.CurrentInstruction.OperandsSize + 1)
+			cpu.IncrementPC(h.operandsSize + 1)
 		}
 
 		cpu.PCJumped = false
 
-		//calculate cycles
-		cpu.LastInstrCycle.M += cpu.CurrentInstruction.Cycles
-	} else {
-		iflagnow := cpu.mmu.ReadByte(constants.INTERRUPT_FLAG_ADDR)
+		//memory accesses have already ticked as they happened, what is left of the
+		//instruction's cycles is spent internally
+		cpu.tick(cpu.instructionCycles - (cpu.LastInstrCycle.M - start))
 
-		//if IF flag has changed then the cpu should resume...
-		if cpu.InterruptFlagBeforeHalt != iflagnow {
+		//EI only takes effect once the instruction after it has run
+		if cpu.EIDelay > 0 {
+			cpu.EIDelay--
+			if cpu.EIDelay == 0 {
+				cpu.InterruptsEnabled = true
+			}
+		}
+	} else if cpu.LockedUp {
+		//nothing, not even an interrupt, gets the CPU going again short of a reset
+		cpu.tick(1)
+	} else if cpu.Stopped {
+		//the rest of the system is still ticked so frames keep being produced, but
+		//nothing clocked by the CPU (like the timer) moves
+		cpu.tick(1)
+		if cpu.joypadLineLow() {
+			log.Println(PREFIX, "Waking from STOP")
+			cpu.Stopped = false
+		}
+	} else {
+		//Halt consumes 1 cpu cycle, the CPU wakes once any enabled interrupt is requested
+		//whether or not interrupts are enabled. When they are the interrupt is serviced
+		//on the next step
+		cpu.tick(1)
+		if cpu.interruptPending() {
 			cpu.Halted = false
 		}
-
-		//Halt consumes 1 cpu cycle
-		cpu.LastInstrCycle.M = 1
 	}
 
 	return cpu.LastInstrCycle.M
 }
 
+//Illegal opcodes (0xD3, 0xDB, 0xDD, 0xE3, 0xE4, 0xEB, 0xEC, 0xED, 0xF4, 0xFC and 0xFD)
+//lock the CPU up, the PC is left pointing at the opcode
+func (cpu *GbcCPU) lockUp(opcode byte) {
+	log.Printf("%s Locked up executing illegal opcode 0x%02X at %s", PREFIX, opcode, cpu.PC)
+	cpu.LockedUp = true
+}
+
+//Whether an interrupt is both requested (IF) and enabled (IE), regardless of IME
+func (cpu *GbcCPU) interruptPending() bool {
+	var ie byte = cpu.mmu.ReadByte(constants.INTERRUPT_ENABLED_FLAG_ADDR)
+	var iflag byte = cpu.mmu.ReadByte(constants.INTERRUPT_FLAG_ADDR)
+	return ie&iflag&0x1F != 0x00
+}
+
+//Interrupt vectors in priority order, the interrupt for bit n of IE/IF jumps to
+//interruptVectors[n]
+var interruptVectors [5]byte = [5]byte{
+	constants.V_BLANK_IR_ADDR,
+	constants.LCD_IR_ADDR,
+	constants.TIMER_OVERFLOW_IR_ADDR,
+	constants.SERIAL_IR_ADDR,
+	constants.JOYP_HILO_IR_ADDR,
+}
+
+//Services the highest priority pending interrupt if interrupts are enabled, returns
+//true if one was dispatched
 func (cpu *GbcCPU) CheckForInterrupts() bool {
-	if cpu.InterruptsEnabled {
-		var ie byte = cpu.mmu.ReadByte(constants.INTERRUPT_ENABLED_FLAG_ADDR)
-		var iflag byte = cpu.mmu.ReadByte(constants.INTERRUPT_FLAG_ADDR)
-		var interrupt byte = iflag & ie
-		if interrupt != 0x00 {
-			switch {
-			case interrupt&constants.V_BLANK_IRQ == constants.V_BLANK_IRQ:
-				cpu.mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, iflag&0xFE)
-				cpu.pushWordToStack(cpu.PC)
-				cpu.PC = types.Word(constants.V_BLANK_IR_ADDR)
-				cpu.InterruptsEnabled = false
-				return true
-			case interrupt&constants.LCD_IRQ == constants.LCD_IRQ:
-				cpu.mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, iflag&0xFD)
-				cpu.pushWordToStack(cpu.PC)
-				cpu.PC = types.Word(constants.LCD_IR_ADDR)
-				cpu.InterruptsEnabled = false
-				return true
-			case interrupt&constants.TIMER_OVERFLOW_IRQ == constants.TIMER_OVERFLOW_IRQ:
-				cpu.mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, iflag&0xFB)
-				cpu.pushWordToStack(cpu.PC)
-				cpu.PC = types.Word(constants.TIMER_OVERFLOW_IR_ADDR)
-				cpu.InterruptsEnabled = false
-				return true
-			case interrupt&constants.JOYP_HILO_IRQ == constants.JOYP_HILO_IRQ:
-				log.Println("JOYP!")
-				cpu.mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, iflag&0xEF)
-				cpu.pushWordToStack(cpu.PC)
-				cpu.PC = types.Word(constants.JOYP_HILO_IR_ADDR)
-				cpu.InterruptsEnabled = false
-				return true
-			default:
-				log.Fatalf("Unknown interrupt = %d", interrupt)
-			}
-		}
+	if !cpu.InterruptsEnabled || !cpu.interruptPending() {
+		return false
 	}
 
-	return false
-}
+	//dispatching takes 5 M-cycles: two waiting, two pushing the PC and one jumping
+	cpu.InterruptsEnabled = false
+	cpu.tick(2)
 
-//Checks to see if the CPU speed should change to double (CGB only)
-func (cpu *GbcCPU) SetCPUSpeed() {
-	var speedPrepRegister byte = cpu.mmu.ReadByte(mmu.CGB_DOUBLE_SPEED_PREP_REG)
-	if speedPrepRegister&0x01 == 0x01 {
-		switch cpu.Speed {
-		case 2:
-			cpu.Speed = 1
-			cpu.mmu.WriteByte(mmu.CGB_DOUBLE_SPEED_PREP_REG, 0x00)
-		case 1:
-			cpu.Speed = 2
-			cpu.mmu.WriteByte(mmu.CGB_DOUBLE_SPEED_PREP_REG, 0x80)
-		default:
-			panic(fmt.Sprint("Unsupported CPU speed ", cpu.Speed, " this should not happen!"))
+	hs, ls := utils.SplitIntoBytes(uint16(cpu.PC))
+	cpu.pushByteToStack(hs)
+
+	//the interrupt is only chosen after the upper byte of the PC has been pushed, so
+	//a push that lands on IE can change it or cancel dispatch (jumping to 0x0000)
+	var ie byte = cpu.mmu.ReadByte(constants.INTERRUPT_ENABLED_FLAG_ADDR)
+	var iflag byte = cpu.mmu.ReadByte(constants.INTERRUPT_FLAG_ADDR)
+	cpu.pushByteToStack(ls)
+
+	cpu.PC = 0x0000
+	for i, vector := range interruptVectors {
+		var bit byte = 0x01 << uint(i)
+		if ie&iflag&bit == bit {
+			cpu.mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, iflag&^bit)
+			cpu.PC = types.Word(vector)
+			break
 		}
-		log.Printf("CPU: Setting CPU speed to %dx speed", cpu.Speed)
 	}
+
+	cpu.tick(1)
+	return true
 }
 
-func (cpu *GbcCPU) Compile(instruction Instruction) Instruction {
-	switch instruction.OperandsSize {
-	case 1:
-		instruction.Operands[0] = cpu.mmu.ReadByte(cpu.PC + 1)
-	case 2:
-		instruction.Operands[0] = cpu.mmu.ReadByte(cpu.PC + 1)
-		instruction.Operands[1] = cpu.mmu.ReadByte(cpu.PC + 2)
+//the number of M-cycles the CPU is stalled for while switching speed
+const SPEED_SWITCH_CYCLES int = 2050
+
+//Checks to see if the CPU speed should change (CGB only), returns true if it did. The
+//CPU stalls while the switch happens
+func (cpu *GbcCPU) SetCPUSpeed() bool {
+	if !cpu.mmu.SpeedSwitchPrepared() {
+		return false
 	}
 
-	return instruction
-}
+	//ticked a cycle at a time as the GPU only moves one line on for each step
+	cpu.Stopped = true
+	for i := 0; i < SPEED_SWITCH_CYCLES; i++ {
+		cpu.tick(1)
+	}
+	cpu.Stopped = false
 
-func (cpu *GbcCPU) Decode(instruction byte) (Instruction, bool) {
-	if ins := Instructions[instruction]; ins == EMPTY_INSTRUCTION {
-		return ins, false
-	} else {
-		return ins, true
+	switch cpu.Speed {
+	case 2:
+		cpu.Speed = 1
+	case 1:
+		cpu.Speed = 2
+	default:
+		panic(fmt.Sprint("Unsupported CPU speed ", cpu.Speed, " this should not happen!"))
 	}
+	cpu.mmu.SetDoubleSpeed(cpu.Speed == 2)
+	log.Printf("CPU: Setting CPU speed to %dx speed", cpu.Speed)
+	return true
+}
+
+//Whether any joypad input line is low, which wakes the CPU from STOP
+func (cpu *GbcCPU) joypadLineLow() bool {
+	return cpu.mmu.ReadByte(JOYPAD_REGISTER)&0x0F != 0x0F
 }
 
-func (cpu *GbcCPU) DecodeCB(instruction byte) (Instruction, bool) {
-	return InstructionsCB[instruction], true
+//Reads the operands of the current instruction that follow the opcode
+func (cpu *GbcCPU) fetchOperands(operandsSize int) {
+	switch operandsSize {
+	case 1:
+		cpu.Operands[0] = cpu.readByte(cpu.PC + 1)
+	case 2:
+		cpu.Operands[0] = cpu.readByte(cpu.PC + 1)
+		cpu.Operands[1] = cpu.readByte(cpu.PC + 2)
+	}
 }
 
 func (cpu *GbcCPU) pushByteToStack(b byte) {
 	cpu.SP--
-	cpu.WriteByte(cpu.SP, b)
+	cpu.writeByte(cpu.SP, b)
 }
 
+//Every 16 bit push (PUSH, CALL, RST and interrupts) spends an internal M-cycle
+//before writing to the stack
 func (cpu *GbcCPU) pushWordToStack(word types.Word) {
+	cpu.tick(1)
 	hs, ls := utils.SplitIntoBytes(uint16(word))
 	cpu.pushByteToStack(hs)
 	cpu.pushByteToStack(ls)
 }
 
 func (cpu *GbcCPU) popByteFromStack() byte {
-	var b byte = cpu.ReadByte(cpu.SP)
+	var b byte = cpu.readByte(cpu.SP)
 	cpu.SP++
 	return b
 }
@@ -1390,14 +469,29 @@ func (cpu *GbcCPU) popWordFromStack() types.Word {
 	return types.Word(utils.JoinBytes(hs, ls))
 }
 
-func (cpu *GbcCPU) ReadByte(addr types.Word) byte {
+//Every memory access takes an M-cycle, the rest of the system is ticked before the
+//access happens so it sees the state it would on hardware
+func (cpu *GbcCPU) readByte(addr types.Word) byte {
+	cpu.tick(1)
 	return cpu.mmu.ReadByte(addr)
 }
 
-func (cpu *GbcCPU) WriteByte(addr types.Word, value byte) {
+func (cpu *GbcCPU) writeByte(addr types.Word, value byte) {
+	cpu.tick(1)
 	cpu.mmu.WriteByte(addr, value)
 }
 
+func (cpu *GbcCPU) tick(mcycles int) {
+	if mcycles <= 0 {
+		return
+	}
+
+	cpu.LastInstrCycle.M += mcycles
+	if cpu.ticker != nil {
+		cpu.ticker(mcycles)
+	}
+}
+
 // INSTRUCTION HELPERS
 //-----------------------------------------------------------------------
 
@@ -1630,36 +724,60 @@ func (cpu *GbcCPU) NOP() {
 }
 
 //HALT
-//Halt CPU
+//Halt CPU until an enabled interrupt is requested
 func (cpu *GbcCPU) HALT() {
-	//get and store the state of the IF FLAG now so we know when it changes
-	cpu.InterruptFlagBeforeHalt = cpu.mmu.ReadByte(constants.INTERRUPT_FLAG_ADDR)
-	cpu.Halted = true
+	if !cpu.interruptPending() {
+		cpu.Halted = true
+		return
+	}
+
+	//HALT does not stop the CPU when an interrupt is already pending. With interrupts
+	//enabled it is serviced straight away, with them disabled the CPU carries on but
+	//fails to increment the PC when reading the next opcode (the HALT bug)
+	switch {
+	case cpu.EIDelay > 0:
+		//straight after EI the bug shows up as the interrupt returning to the HALT
+		cpu.PCJumped = true
+	case !cpu.InterruptsEnabled:
+		cpu.HaltBug = true
+	}
 }
 
 //STOP
+//Enter low power mode until a joypad line goes low. If a speed switch has been prepared
+//through KEY1 (CGB only) the switch happens instead
 func (cpu *GbcCPU) Stop() {
-	log.Println("CPU: Stopping...")
-	//After a stop instruction is executed, CGB hardware should check to see if the CPU speed should change
-	cpu.SetCPUSpeed()
+	//the divider is reset either way
+	cpu.mmu.WriteByte(constants.DIV_REGISTER_ADDR, 0x00)
+
+	if cpu.SetCPUSpeed() {
+		return
+	}
+
+	log.Println(PREFIX, "Stopping until a button is pressed")
+	cpu.Stopped = true
 }
 
 //DI
 //Disable interrupts
 func (cpu *GbcCPU) DI() {
 	cpu.InterruptsEnabled = false
+	cpu.EIDelay = 0
 }
 
 //EI
-//Enable interrupts
+//Enable interrupts after the next instruction has run
 func (cpu *GbcCPU) EI() {
-	cpu.InterruptsEnabled = true
+	//a second EI while one is pending does not delay it further
+	if !cpu.InterruptsEnabled && cpu.EIDelay == 0 {
+		cpu.EIDelay = 2
+	}
 }
 
 //LD r,n
 //Load value (n) from memory address in the PC into register (r) and increment PC by 1
 func (cpu *GbcCPU) LDrn(r *byte) {
-	*r = cpu.CurrentInstruction.Operands[0]
+	*r = cpu.Operands[0]
 }
 
 //LD r,r
@@ -1672,61 +790,61 @@ func (cpu *GbcCPU) LDrr(r1 *byte, r2 *byte) {
 //Load value from register (r) into memory address located at register pair (RR)
 func (cpu *GbcCPU) LDrr_r(hs *byte, ls *byte, r *byte) {
 	var RR types.Word = types.Word(utils.JoinBytes(*hs, *ls))
-	cpu.WriteByte(RR, *r)
+	cpu.writeByte(RR, *r)
 }
 
 //LD r, rr
 //Load value from memory address located in register pair (RR) into register (r)
 func (cpu *GbcCPU) LDr_rr(hs *byte, ls *byte, r *byte) {
 	var RR types.Word = types.Word(utils.JoinBytes(*hs, *ls))
-	*r = cpu.ReadByte(RR)
+	*r = cpu.readByte(RR)
 }
 
 //LD nn,r
 //Load value from register (r) and put it in memory address (nn) taken from the next 2 bytes of memory from the PC. Increment the PC by 2
 func (cpu *GbcCPU) LDnn_r(r *byte) {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 	var resultAddr types.Word = types.Word(utils.JoinBytes(hs, ls))
-	cpu.WriteByte(resultAddr, *r)
+	cpu.writeByte(resultAddr, *r)
 }
 
 //LD r, nn
 //Load the value in memory address defined from the next two bytes relative to the PC and store it in register (r). Increment the PC by 2
 func (cpu *GbcCPU) LDr_nn(r *byte) {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 	var nn types.Word = types.Word(utils.JoinBytes(hs, ls))
-	*r = cpu.ReadByte(nn)
+	*r = cpu.readByte(nn)
 }
 
 //LD (HL),n
 //Load the value (n) from the memory address in the PC and put it in the memory address designated by register pair (HL)
 func (cpu *GbcCPU) LDhl_n() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.CurrentInstruction.Operands[0]
-	cpu.WriteByte(HL, value)
+	var value byte = cpu.Operands[0]
+	cpu.writeByte(HL, value)
 }
 
 //LD r,(C)
 //Load the value from memory addressed 0xFF00 + value in register C. Store it in register (r)
 func (cpu *GbcCPU) LDr_ffplusc(r *byte) {
 	var valueAddr types.Word = 0xFF00 + types.Word(cpu.R.C)
-	*r = cpu.ReadByte(valueAddr)
+	*r = cpu.readByte(valueAddr)
 }
 
 //LD (C),r
 //Load the value from register (r) and store it in memory addressed 0xFF00 + value in register C.
 func (cpu *GbcCPU) LDffplusc_r(r *byte) {
 	var valueAddr types.Word = 0xFF00 + types.Word(cpu.R.C)
-	cpu.WriteByte(valueAddr, *r)
+	cpu.writeByte(valueAddr, *r)
 }
 
 //LDD r, (HL)
 //Load the value from memory addressed in register pair (HL) and store it in register R. Decrement the HL registers
 func (cpu *GbcCPU) LDDr_hl(r *byte) {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	*r = cpu.ReadByte(HL)
+	*r = cpu.readByte(HL)
 
 	HL -= 1
 
@@ -1737,7 +855,7 @@ func (cpu *GbcCPU) LDDr_hl(r *byte) {
 //Load the value in register (r) and store in memory addressed in register pair (HL). Decrement the HL registers
 func (cpu *GbcCPU) LDDhl_r(r *byte) {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	cpu.WriteByte(HL, *r)
+	cpu.writeByte(HL, *r)
 
 	HL -= 1
 
@@ -1748,7 +866,7 @@ func (cpu *GbcCPU) LDDhl_r(r *byte) {
 //Load the value from memory addressed in register pair (HL) and store it in register R. Increment the HL registers
 func (cpu *GbcCPU) LDIr_hl(r *byte) {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	*r = cpu.ReadByte(HL)
+	*r = cpu.readByte(HL)
 
 	HL += 1
 
@@ -1759,7 +877,7 @@ func (cpu *GbcCPU) LDIr_hl(r *byte) {
 //Load the value in register (r) and store in memory addressed in register pair (HL). Increment the HL registers
 func (cpu *GbcCPU) LDIhl_r(r *byte) {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	cpu.WriteByte(HL, *r)
+	cpu.writeByte(HL, *r)
 
 	HL += 1
 
@@ -1768,21 +886,21 @@ func (cpu *GbcCPU) LDIhl_r(r *byte) {
 
 //LDH n, r
 func (cpu *GbcCPU) LDHn_r(r *byte) {
-	var n byte = cpu.CurrentInstruction.Operands[0]
-	cpu.WriteByte(types.Word(0xFF00)+types.Word(n), *r)
+	var n byte = cpu.Operands[0]
+	cpu.writeByte(types.Word(0xFF00)+types.Word(n), *r)
 }
 
 //LDH r, n
 //Load value (n) in register (r) and store it in memory address FF00+PC. Increment PC by 1
 func (cpu *GbcCPU) LDHr_n(r *byte) {
-	var n byte = cpu.CurrentInstruction.Operands[0]
-	*r = cpu.ReadByte((types.Word(0xFF00) + types.Word(n)))
+	var n byte = cpu.Operands[0]
+	*r = cpu.readByte((types.Word(0xFF00) + types.Word(n)))
 }
 
 //LD n, nn
 func (cpu *GbcCPU) LDn_nn(r1, r2 *byte) {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 
 	//LS nibble first
 	*r1 = hs
@@ -1791,20 +909,20 @@ func (cpu *GbcCPU) LDn_nn(r1, r2 *byte) {
 
 //LD SP, nn
 func (cpu *GbcCPU) LDSP_nn() {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 
 	cpu.SP = types.Word(utils.JoinBytes(hs, ls))
 }
 
 //LD nn, SP
 func (cpu *GbcCPU) LDnn_SP() {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 	var addr types.Word = types.Word(utils.JoinBytes(hs, ls))
 
-	cpu.WriteByte(addr+1, byte(cpu.SP&0xFF00>>8))
-	cpu.WriteByte(addr, byte(cpu.SP&0x00FF))
+	cpu.writeByte(addr+1, byte(cpu.SP&0xFF00>>8))
+	cpu.writeByte(addr, byte(cpu.SP&0x00FF))
 }
 
 //LD SP, rr
@@ -1814,7 +932,7 @@ func (cpu *GbcCPU) LDSP_hl() {
 
 //LDHL SP, n
 func (cpu *GbcCPU) LDHLSP_n() {
-	var n byte = cpu.CurrentInstruction.Operands[0]
+	var n byte = cpu.Operands[0]
 
 	var HL types.Word
 
@@ -1876,7 +994,7 @@ func (cpu *GbcCPU) AddA_r(r *byte) {
 //Add the value in memory addressed in register pair (HL) to register A
 func (cpu *GbcCPU) AddA_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 
 	cpu.R.A = cpu.addBytes(cpu.R.A, value)
 }
@@ -1884,7 +1002,7 @@ func (cpu *GbcCPU) AddA_hl() {
 //ADD A,n
 //Add the value in memory addressed PC to register A. Increment the PC by 1
 func (cpu *GbcCPU) AddA_n() {
-	var value byte = cpu.CurrentInstruction.Operands[0]
+	var value byte = cpu.Operands[0]
 	cpu.R.A = cpu.addBytes(cpu.R.A, value)
 }
 
@@ -1921,7 +1039,7 @@ func (cpu *GbcCPU) AddCA_r(r *byte) {
 //ADDC A,(HL)
 func (cpu *GbcCPU) AddCA_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var hlValue byte = cpu.ReadByte(HL)
+	var hlValue byte = cpu.readByte(HL)
 
 	var carry int = 0
 	if cpu.IsFlagSet(C) {
@@ -1953,7 +1071,7 @@ func (cpu *GbcCPU) AddCA_hl() {
 
 //ADDC A,n
 func (cpu *GbcCPU) AddCA_n() {
-	var value byte = cpu.CurrentInstruction.Operands[0]
+	var value byte = cpu.Operands[0]
 	var carry int = 0
 	if cpu.IsFlagSet(C) {
 		carry = 1
@@ -1990,14 +1108,14 @@ func (cpu *GbcCPU) SubA_r(r *byte) {
 //SUB A,hl
 func (cpu *GbcCPU) SubA_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 
 	cpu.R.A = cpu.subBytes(cpu.R.A, value)
 }
 
 //SUB A,n
 func (cpu *GbcCPU) SubA_n() {
-	var value byte = cpu.CurrentInstruction.Operands[0]
+	var value byte = cpu.Operands[0]
 	cpu.R.A = cpu.subBytes(cpu.R.A, value)
 }
 
@@ -2035,7 +1153,7 @@ func (cpu *GbcCPU) SubAC_r(r *byte) {
 //SBC A, (HL)
 func (cpu *GbcCPU) SubAC_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 
 	var un int = int(value) & 0xff
 	var tmpa int = int(cpu.R.A) & 0xff
@@ -2071,7 +1189,7 @@ func (cpu *GbcCPU) SubAC_hl() {
 
 //SBC A, n
 func (cpu *GbcCPU) SubAC_n() {
-	var value byte = cpu.CurrentInstruction.Operands[0]
+	var value byte = cpu.Operands[0]
 	var un int = int(value) & 0xff
 	var tmpa int = int(cpu.R.A) & 0xff
 	var ua int = int(cpu.R.A) & 0xff
@@ -2112,13 +1230,13 @@ func (cpu *GbcCPU) AndA_r(r *byte) {
 //AND A, (HL)
 func (cpu *GbcCPU) AndA_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 	cpu.R.A = cpu.andBytes(cpu.R.A, value)
 }
 
 //AND A, n
 func (cpu *GbcCPU) AndA_n() {
-	var value byte = cpu.CurrentInstruction.Operands[0]
+	var value byte = cpu.Operands[0]
 	cpu.R.A = cpu.andBytes(cpu.R.A, value)
 }
 
@@ -2130,13 +1248,13 @@ func (cpu *GbcCPU) OrA_r(r *byte) {
 //OR A, (HL)
 func (cpu *GbcCPU) OrA_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 	cpu.R.A = cpu.orBytes(cpu.R.A, value)
 }
 
 //OR A, n
 func (cpu *GbcCPU) OrA_n() {
-	var value byte = cpu.CurrentInstruction.Operands[0]
+	var value byte = cpu.Operands[0]
 	cpu.R.A = cpu.orBytes(cpu.R.A, value)
 }
 
@@ -2148,13 +1266,13 @@ func (cpu *GbcCPU) XorA_r(r *byte) {
 //XOR A, (HL)
 func (cpu *GbcCPU) XorA_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 	cpu.R.A = cpu.xorBytes(cpu.R.A, value)
 }
 
 //XOR A, n
 func (cpu *GbcCPU) XorA_n() {
-	var value byte = cpu.CurrentInstruction.Operands[0]
+	var value byte = cpu.Operands[0]
 	cpu.R.A = cpu.xorBytes(cpu.R.A, value)
 }
 
@@ -2166,13 +1284,13 @@ func (cpu *GbcCPU) CPA_r(r *byte) {
 //CP A, (HL)
 func (cpu *GbcCPU) CPA_hl() {
 	var hlAddr types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var hlValue byte = cpu.ReadByte(hlAddr)
+	var hlValue byte = cpu.readByte(hlAddr)
 	cpu.subBytes(cpu.R.A, hlValue)
 }
 
 //CP A, n
 func (cpu *GbcCPU) CPA_n() {
-	var value byte = cpu.CurrentInstruction.Operands[0]
+	var value byte = cpu.Operands[0]
 	cpu.subBytes(cpu.R.A, value)
 }
 
@@ -2184,9 +1302,9 @@ func (cpu *GbcCPU) Inc_r(r *byte) {
 //INC (HL)
 func (cpu *GbcCPU) Inc_hl() {
 	var hlAddr types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var hlValue byte = cpu.ReadByte(hlAddr)
+	var hlValue byte = cpu.readByte(hlAddr)
 	var result byte = cpu.incByte(hlValue)
-	cpu.WriteByte(hlAddr, result)
+	cpu.writeByte(hlAddr, result)
 }
 
 //DEC r
@@ -2197,9 +1315,9 @@ func (cpu *GbcCPU) Dec_r(r *byte) {
 //DEC (HL)
 func (cpu *GbcCPU) Dec_hl() {
 	var hlAddr types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var hlValue byte = cpu.ReadByte(hlAddr)
+	var hlValue byte = cpu.readByte(hlAddr)
 	var result byte = cpu.decByte(hlValue)
-	cpu.WriteByte(hlAddr, result)
+	cpu.writeByte(hlAddr, result)
 }
 
 // --------------- 16 bit operations ---------------
@@ -2220,7 +1338,7 @@ func (cpu *GbcCPU) Addhl_sp() {
 
 //ADD SP,n
 func (cpu *GbcCPU) Addsp_n() {
-	var n byte = cpu.CurrentInstruction.Operands[0]
+	var n byte = cpu.Operands[0]
 
 	var calculation types.Word
 
@@ -2353,9 +1471,9 @@ func (cpu *GbcCPU) Swap_r(r *byte) {
 //SWAP (HL)
 func (cpu *GbcCPU) Swap_hl() {
 	var hlAddr types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var hlValue byte = cpu.ReadByte(hlAddr)
+	var hlValue byte = cpu.readByte(hlAddr)
 	var result = cpu.swapByte(hlValue)
-	cpu.WriteByte(hlAddr, result)
+	cpu.writeByte(hlAddr, result)
 }
 
 //RLCA
@@ -2445,7 +1563,7 @@ func (cpu *GbcCPU) Rlc_r(r *byte) {
 //RLC (HL)
 func (cpu *GbcCPU) Rlc_hl() {
 	var hlAddr types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var hlValue byte = cpu.mmu.ReadByte(hlAddr)
+	var hlValue byte = cpu.readByte(hlAddr)
 
 	var bit7 bool = false
 
@@ -2472,7 +1590,7 @@ func (cpu *GbcCPU) Rlc_hl() {
 	cpu.ResetFlag(N)
 	cpu.ResetFlag(H)
 
-	cpu.mmu.WriteByte(hlAddr, calculation)
+	cpu.writeByte(hlAddr, calculation)
 }
 
 //RL r
@@ -2511,7 +1629,7 @@ func (cpu *GbcCPU) Rl_r(r *byte) {
 //RL (HL)
 func (cpu *GbcCPU) Rl_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 
 	var bit7 bool = false
 	var calculation byte = value
@@ -2540,7 +1658,7 @@ func (cpu *GbcCPU) Rl_hl() {
 
 	cpu.ResetFlag(N)
 	cpu.ResetFlag(H)
-	cpu.WriteByte(HL, calculation)
+	cpu.writeByte(HL, calculation)
 }
 
 //RRCA
@@ -2629,7 +1747,7 @@ func (cpu *GbcCPU) Rrc_r(r *byte) {
 //RRC (HL)
 func (cpu *GbcCPU) Rrc_hl() {
 	var hlAddr types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var hlValue byte = cpu.mmu.ReadByte(hlAddr)
+	var hlValue byte = cpu.readByte(hlAddr)
 	var bit0 bool = false
 
 	if hlValue&0x01 == 0x01 {
@@ -2655,7 +1773,7 @@ func (cpu *GbcCPU) Rrc_hl() {
 	cpu.ResetFlag(N)
 	cpu.ResetFlag(H)
 
-	cpu.mmu.WriteByte(hlAddr, calculation)
+	cpu.writeByte(hlAddr, calculation)
 }
 
 //RR r
@@ -2694,7 +1812,7 @@ func (cpu *GbcCPU) Rr_r(r *byte) {
 //RR (HL)
 func (cpu *GbcCPU) Rr_hl() {
 	var HLAddr types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HLAddr)
+	var value byte = cpu.readByte(HLAddr)
 
 	var bit0 bool = false
 	var calculation byte = value
@@ -2723,7 +1841,7 @@ func (cpu *GbcCPU) Rr_hl() {
 
 	cpu.ResetFlag(N)
 	cpu.ResetFlag(H)
-	cpu.WriteByte(HLAddr, calculation)
+	cpu.writeByte(HLAddr, calculation)
 }
 
 //SLA r
@@ -2758,7 +1876,7 @@ func (cpu *GbcCPU) Sla_r(r *byte) {
 //SLA (HL)
 func (cpu *GbcCPU) Sla_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 	var calculation byte = value
 	var bit7 bool = false
 
@@ -2783,7 +1901,7 @@ func (cpu *GbcCPU) Sla_hl() {
 		cpu.ResetFlag(C)
 	}
 
-	cpu.WriteByte(HL, calculation)
+	cpu.writeByte(HL, calculation)
 }
 
 //SRA r
@@ -2818,7 +1936,7 @@ func (cpu *GbcCPU) Sra_r(r *byte) {
 //SRA (HL)
 func (cpu *GbcCPU) Sra_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 	var calculation byte = value
 	var bit0 bool = false
 
@@ -2843,7 +1961,7 @@ func (cpu *GbcCPU) Sra_hl() {
 		cpu.ResetFlag(C)
 	}
 
-	cpu.WriteByte(HL, calculation)
+	cpu.writeByte(HL, calculation)
 }
 
 //SRL r
@@ -2878,7 +1996,7 @@ func (cpu *GbcCPU) Srl_r(r *byte) {
 //SRL (HL)
 func (cpu *GbcCPU) Srl_hl() {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 	var calculation byte = value
 	var bit0 bool = false
 
@@ -2903,7 +2021,7 @@ func (cpu *GbcCPU) Srl_hl() {
 		cpu.ResetFlag(C)
 	}
 
-	cpu.WriteByte(HL, calculation)
+	cpu.writeByte(HL, calculation)
 }
 
 //BIT b, r
@@ -2914,7 +2032,7 @@ func (cpu *GbcCPU) Bitb_r(b byte, r *byte) {
 //BIT b,(HL)
 func (cpu *GbcCPU) Bitb_hl(b byte) {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var value byte = cpu.ReadByte(HL)
+	var value byte = cpu.readByte(HL)
 	cpu.bitTest(b, value)
 }
 
@@ -2926,10 +2044,10 @@ func (cpu *GbcCPU) Setb_r(b byte, r *byte) {
 // SET b, (HL)
 func (cpu *GbcCPU) Setb_hl(b byte) {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var HLValue byte = cpu.ReadByte(HL)
+	var HLValue byte = cpu.readByte(HL)
 	var result byte = cpu.setBit(b, HLValue)
 
-	cpu.WriteByte(HL, result)
+	cpu.writeByte(HL, result)
 }
 
 // RES b, r
@@ -2940,16 +2058,16 @@ func (cpu *GbcCPU) Resb_r(b byte, r *byte) {
 // RES b, (HL)
 func (cpu *GbcCPU) Resb_hl(b byte) {
 	var HL types.Word = types.Word(utils.JoinBytes(cpu.R.H, cpu.R.L))
-	var HLValue byte = cpu.ReadByte(HL)
+	var HLValue byte = cpu.readByte(HL)
 	var result byte = cpu.resetBit(b, HLValue)
 
-	cpu.WriteByte(HL, result)
+	cpu.writeByte(HL, result)
 }
 
 //JP nn
 func (cpu *GbcCPU) JP_nn() {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 	cpu.PC = types.Word(utils.JoinBytes(hs, ls))
 	cpu.PCJumped = true
 }
@@ -2963,21 +2081,21 @@ func (cpu *GbcCPU) JP_hl() {
 
 //JP cc, nn
 func (cpu *GbcCPU) JPcc_nn(flag int, jumpWhen bool) {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 
 	if cpu.IsFlagSet(flag) == jumpWhen {
 		cpu.PCJumped = true
 		cpu.PC = types.Word(utils.JoinBytes(hs, ls))
-		cpu.CurrentInstruction.Cycles = 4
+		cpu.instructionCycles = 4
 	} else {
-		cpu.CurrentInstruction.Cycles = 3
+		cpu.instructionCycles = 3
 	}
 }
 
 //JR n
 func (cpu *GbcCPU) JR_n() {
-	var n byte = cpu.CurrentInstruction.Operands[0]
+	var n byte = cpu.Operands[0]
 	if n != 0x00 {
 		cpu.PC += types.Word(cpu.CurrentInstruction.OperandsSize + 1)
 
@@ -2993,7 +2111,7 @@ func (cpu *GbcCPU) JR_n() {
 
 //JR cc, nn
 func (cpu *GbcCPU) JRcc_nn(flag int, jumpWhen bool) {
-	var n byte = cpu.CurrentInstruction.Operands[0]
+	var n byte = cpu.Operands[0]
 
 	if cpu.IsFlagSet(flag) == jumpWhen {
 		if n != 0x00 {
@@ -3007,17 +2125,17 @@ func (cpu *GbcCPU) JRcc_nn(flag int, jumpWhen bool) {
 
 			cpu.PCJumped = true
 		}
-		cpu.CurrentInstruction.Cycles = 3
+		cpu.instructionCycles = 3
 	} else {
-		cpu.CurrentInstruction.Cycles = 2
+		cpu.instructionCycles = 2
 	}
 }
 
 // CALL nn
 //Push address of next instruction onto stack and then jump to address nn
 func (cpu *GbcCPU) Call_nn() {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 	var nextInstr types.Word = cpu.PC + 3
 	cpu.pushWordToStack(nextInstr)
 	cpu.PC = types.Word(utils.JoinBytes(hs, ls))
@@ -3026,17 +2144,17 @@ func (cpu *GbcCPU) Call_nn() {
 
 // CALL cc,nn
 func (cpu *GbcCPU) Callcc_nn(flag int, callWhen bool) {
-	var ls byte = cpu.CurrentInstruction.Operands[0]
-	var hs byte = cpu.CurrentInstruction.Operands[1]
+	var ls byte = cpu.Operands[0]
+	var hs byte = cpu.Operands[1]
 	var nextInstr types.Word = cpu.PC + 3
 
 	if cpu.IsFlagSet(flag) == callWhen {
 		cpu.pushWordToStack(nextInstr)
 		cpu.PC = types.Word(utils.JoinBytes(hs, ls))
 		cpu.PCJumped = true
-		cpu.CurrentInstruction.Cycles = 6
+		cpu.instructionCycles = 6
 	} else {
-		cpu.CurrentInstruction.Cycles = 3
+		cpu.instructionCycles = 3
 	}
 }
 
@@ -3048,12 +2166,14 @@ func (cpu *GbcCPU) Ret() {
 
 // RET cc
 func (cpu *GbcCPU) Retcc(flag int, returnWhen bool) {
+	//the condition is checked in an internal M-cycle before the stack is read
+	cpu.tick(1)
 	if cpu.IsFlagSet(flag) == returnWhen {
 		cpu.PC = cpu.popWordFromStack()
 		cpu.PCJumped = true
-		cpu.CurrentInstruction.Cycles = 5
+		cpu.instructionCycles = 5
 	} else {
-		cpu.CurrentInstruction.Cycles = 2
+		cpu.instructionCycles = 2
 	}
 }
 
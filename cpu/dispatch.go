@@ -0,0 +1,534 @@
+package cpu
+
+//An entry in one of the dispatch tables. Handlers are bound to a CPU when it is created
+//so register operands are resolved once, rather than on every step
+type handler struct {
+	execute      func()
+	operandsSize int
+	cycles       int
+	instruction  *Instruction
+}
+
+func newHandler(execute func(), instruction *Instruction) handler {
+	if *instruction == EMPTY_INSTRUCTION {
+		return handler{}
+	}
+	return handler{execute: execute, operandsSize: instruction.OperandsSize, cycles: instruction.Cycles, instruction: instruction}
+}
+
+//Builds the dispatch tables for the 256 opcodes and 256 CB prefixed opcodes. Illegal
+//opcodes are left without a handler
+func (cpu *GbcCPU) bindHandlers() {
+	a, b, c, d, e, h, l, f := &cpu.R.A, &cpu.R.B, &cpu.R.C, &cpu.R.D, &cpu.R.E, &cpu.R.H, &cpu.R.L, &cpu.R.F
+
+	var executes [256]func() = [256]func(){
+		0x00: cpu.NOP,                            //NOP
+		0x01: func() { cpu.LDn_nn(b, c) },        //LD BC, nn
+		0x02: func() { cpu.LDrr_r(b, c, a) },     //LD (BC), A
+		0x03: func() { cpu.Inc_rr(b, c) },        //INC BC
+		0x04: func() { cpu.Inc_r(b) },            //INC B
+		0x05: func() { cpu.Dec_r(b) },            //DEC B
+		0x06: func() { cpu.LDrn(b) },             //LD B,n
+		0x07: cpu.RLCA,                           //RLCA
+		0x08: cpu.LDnn_SP,                        //LD nn, SP
+		0x09: func() { cpu.Addhl_rr(b, c) },      //ADD HL,BC
+		0x0A: func() { cpu.LDr_rr(b, c, a) },     //LD A, (BC)
+		0x0B: func() { cpu.Dec_rr(b, c) },        //DEC BC
+		0x0C: func() { cpu.Inc_r(c) },            //INC C
+		0x0D: func() { cpu.Dec_r(c) },            //DEC C
+		0x0E: func() { cpu.LDrn(c) },             //LD C,n
+		0x0F: cpu.RRCA,                           //RRCA
+		0x10: cpu.Stop,                           //STOP
+		0x11: func() { cpu.LDn_nn(d, e) },        //LD DE, nn
+		0x12: func() { cpu.LDrr_r(d, e, a) },     //LD (DE), A
+		0x13: func() { cpu.Inc_rr(d, e) },        //INC DE
+		0x14: func() { cpu.Inc_r(d) },            //INC D
+		0x15: func() { cpu.Dec_r(d) },            //DEC D
+		0x16: func() { cpu.LDrn(d) },             //LD D,n
+		0x17: cpu.RLA,                            //RLA
+		0x18: cpu.JR_n,                           //JR n
+		0x19: func() { cpu.Addhl_rr(d, e) },      //ADD HL,DE
+		0x1A: func() { cpu.LDr_rr(d, e, a) },     //LD A, (DE)
+		0x1B: func() { cpu.Dec_rr(d, e) },        //DEC DE
+		0x1C: func() { cpu.Inc_r(e) },            //INC E
+		0x1D: func() { cpu.Dec_r(e) },            //DEC E
+		0x1E: func() { cpu.LDrn(e) },             //LD E,n
+		0x1F: cpu.RRA,                            //RRA
+		0x20: func() { cpu.JRcc_nn(Z, false) },   //JR NZ,n
+		0x21: func() { cpu.LDn_nn(h, l) },        //LD HL, nn
+		0x22: func() { cpu.LDIhl_r(a) },          //LDI (HL), A
+		0x23: func() { cpu.Inc_rr(h, l) },        //INC HL
+		0x24: func() { cpu.Inc_r(h) },            //INC H
+		0x25: func() { cpu.Dec_r(h) },            //DEC H
+		0x26: func() { cpu.LDrn(h) },             //LD H,n
+		0x27: cpu.Daa,                            //DAA
+		0x28: func() { cpu.JRcc_nn(Z, true) },    //JR Z,n
+		0x29: func() { cpu.Addhl_rr(h, l) },      //ADD HL,HL
+		0x2A: func() { cpu.LDIr_hl(a) },          //LDI A, (HL)
+		0x2B: func() { cpu.Dec_rr(h, l) },        //DEC HL
+		0x2C: func() { cpu.Inc_r(l) },            //INC L
+		0x2D: func() { cpu.Dec_r(l) },            //DEC L
+		0x2E: func() { cpu.LDrn(l) },             //LD L,n
+		0x2F: cpu.CPL,                            //CPL
+		0x30: func() { cpu.JRcc_nn(C, false) },   //JR NC,n
+		0x31: cpu.LDSP_nn,                        //LD SP, nn
+		0x32: func() { cpu.LDDhl_r(a) },          //LDD (HL), A
+		0x33: cpu.Inc_sp,                         //INC SP
+		0x34: cpu.Inc_hl,                         //INC (HL)
+		0x35: cpu.Dec_hl,                         //DEC (HL)
+		0x36: cpu.LDhl_n,                         //LD (HL), n
+		0x37: cpu.SCF,                            //SCF
+		0x38: func() { cpu.JRcc_nn(C, true) },    //JR C,n
+		0x39: cpu.Addhl_sp,                       //ADD HL,SP
+		0x3A: func() { cpu.LDDr_hl(a) },          //LDD A, (HL)
+		0x3B: cpu.Dec_sp,                         //DEC SP
+		0x3C: func() { cpu.Inc_r(a) },            //INC A
+		0x3D: func() { cpu.Dec_r(a) },            //DEC A
+		0x3E: func() { cpu.LDrn(a) },             //LD A, n
+		0x3F: cpu.CCF,                            //CCF
+		0x40: func() { cpu.LDrr(b, b) },          //LD B, B
+		0x41: func() { cpu.LDrr(b, c) },          //LD B, C
+		0x42: func() { cpu.LDrr(b, d) },          //LD B, D
+		0x43: func() { cpu.LDrr(b, e) },          //LD B, E
+		0x44: func() { cpu.LDrr(b, h) },          //LD B, H
+		0x45: func() { cpu.LDrr(b, l) },          //LD B, L
+		0x46: func() { cpu.LDr_rr(h, l, b) },     //LD B, (HL)
+		0x47: func() { cpu.LDrr(b, a) },          //LD B, A
+		0x48: func() { cpu.LDrr(c, b) },          //LD C, B
+		0x49: func() { cpu.LDrr(c, c) },          //LD C, C
+		0x4A: func() { cpu.LDrr(c, d) },          //LD C, D
+		0x4B: func() { cpu.LDrr(c, e) },          //LD C, E
+		0x4C: func() { cpu.LDrr(c, h) },          //LD C, H
+		0x4D: func() { cpu.LDrr(c, l) },          //LD C, L
+		0x4E: func() { cpu.LDr_rr(h, l, c) },     //LD C, (HL)
+		0x4F: func() { cpu.LDrr(c, a) },          //LD C, A
+		0x50: func() { cpu.LDrr(d, b) },          //LD D, B
+		0x51: func() { cpu.LDrr(d, c) },          //LD D, C
+		0x52: func() { cpu.LDrr(d, d) },          //LD D, D
+		0x53: func() { cpu.LDrr(d, e) },          //LD D, E
+		0x54: func() { cpu.LDrr(d, h) },          //LD D, H
+		0x55: func() { cpu.LDrr(d, l) },          //LD D, L
+		0x56: func() { cpu.LDr_rr(h, l, d) },     //LD D, (HL)
+		0x57: func() { cpu.LDrr(d, a) },          //LD D, A
+		0x58: func() { cpu.LDrr(e, b) },          //LD E, B
+		0x59: func() { cpu.LDrr(e, c) },          //LD E, C
+		0x5A: func() { cpu.LDrr(e, d) },          //LD E, D
+		0x5B: func() { cpu.LDrr(e, e) },          //LD E, E
+		0x5C: func() { cpu.LDrr(e, h) },          //LD E, H
+		0x5D: func() { cpu.LDrr(e, l) },          //LD E, L
+		0x5E: func() { cpu.LDr_rr(h, l, e) },     //LD E, (HL)
+		0x5F: func() { cpu.LDrr(e, a) },          //LD E, A
+		0x60: func() { cpu.LDrr(h, b) },          //LD H, B
+		0x61: func() { cpu.LDrr(h, c) },          //LD H, C
+		0x62: func() { cpu.LDrr(h, d) },          //LD H, D
+		0x63: func() { cpu.LDrr(h, e) },          //LD H, E
+		0x64: func() { cpu.LDrr(h, h) },          //LD H, H
+		0x65: func() { cpu.LDrr(h, l) },          //LD H, L
+		0x66: func() { cpu.LDr_rr(h, l, h) },     //LD H, (HL)
+		0x67: func() { cpu.LDrr(h, a) },          //LD H, A
+		0x68: func() { cpu.LDrr(l, b) },          //LD L, B
+		0x69: func() { cpu.LDrr(l, c) },          //LD L, C
+		0x6A: func() { cpu.LDrr(l, d) },          //LD L, D
+		0x6B: func() { cpu.LDrr(l, e) },          //LD L, E
+		0x6C: func() { cpu.LDrr(l, h) },          //LD L, H
+		0x6D: func() { cpu.LDrr(l, l) },          //LD L, L
+		0x6E: func() { cpu.LDr_rr(h, l, l) },     //LD L, (HL)
+		0x6F: func() { cpu.LDrr(l, a) },          //LD L, A
+		0x70: func() { cpu.LDrr_r(h, l, b) },     //LD (HL), B
+		0x71: func() { cpu.LDrr_r(h, l, c) },     //LD (HL), C
+		0x72: func() { cpu.LDrr_r(h, l, d) },     //LD (HL), D
+		0x73: func() { cpu.LDrr_r(h, l, e) },     //LD (HL), E
+		0x74: func() { cpu.LDrr_r(h, l, h) },     //LD (HL), H
+		0x75: func() { cpu.LDrr_r(h, l, l) },     //LD (HL), L
+		0x76: cpu.HALT,                           //HALT
+		0x77: func() { cpu.LDrr_r(h, l, a) },     //LD (HL), A
+		0x78: func() { cpu.LDrr(a, b) },          //LD A, B
+		0x79: func() { cpu.LDrr(a, c) },          //LD A, C
+		0x7A: func() { cpu.LDrr(a, d) },          //LD A, D
+		0x7B: func() { cpu.LDrr(a, e) },          //LD A, E
+		0x7C: func() { cpu.LDrr(a, h) },          //LD A, H
+		0x7D: func() { cpu.LDrr(a, l) },          //LD A, L
+		0x7E: func() { cpu.LDr_rr(h, l, a) },     //LD A, (HL)
+		0x7F: func() { cpu.LDrr(a, a) },          //LD A, A
+		0x80: func() { cpu.AddA_r(b) },           //ADD A, B
+		0x81: func() { cpu.AddA_r(c) },           //ADD A, C
+		0x82: func() { cpu.AddA_r(d) },           //ADD A, D
+		0x83: func() { cpu.AddA_r(e) },           //ADD A, E
+		0x84: func() { cpu.AddA_r(h) },           //ADD A, H
+		0x85: func() { cpu.AddA_r(l) },           //ADD A, L
+		0x86: cpu.AddA_hl,                        //ADD A,(HL)
+		0x87: func() { cpu.AddA_r(a) },           //ADD A, A
+		0x88: func() { cpu.AddCA_r(b) },          //ADC A, B
+		0x89: func() { cpu.AddCA_r(c) },          //ADC A, C
+		0x8A: func() { cpu.AddCA_r(d) },          //ADC A, D
+		0x8B: func() { cpu.AddCA_r(e) },          //ADC A, E
+		0x8C: func() { cpu.AddCA_r(h) },          //ADC A, H
+		0x8D: func() { cpu.AddCA_r(l) },          //ADC A, L
+		0x8E: cpu.AddCA_hl,                       //ADC A, (HL)
+		0x8F: func() { cpu.AddCA_r(a) },          //ADC A, A
+		0x90: func() { cpu.SubA_r(b) },           //SUB A, B
+		0x91: func() { cpu.SubA_r(c) },           //SUB A, C
+		0x92: func() { cpu.SubA_r(d) },           //SUB A, D
+		0x93: func() { cpu.SubA_r(e) },           //SUB A, E
+		0x94: func() { cpu.SubA_r(h) },           //SUB A, H
+		0x95: func() { cpu.SubA_r(l) },           //SUB A, L
+		0x96: cpu.SubA_hl,                        //SUB A, (HL)
+		0x97: func() { cpu.SubA_r(a) },           //SUB A, A
+		0x98: func() { cpu.SubAC_r(b) },          //SBC A, B
+		0x99: func() { cpu.SubAC_r(c) },          //SBC A, C
+		0x9A: func() { cpu.SubAC_r(d) },          //SBC A, D
+		0x9B: func() { cpu.SubAC_r(e) },          //SBC A, E
+		0x9C: func() { cpu.SubAC_r(h) },          //SBC A, H
+		0x9D: func() { cpu.SubAC_r(l) },          //SBC A, L
+		0x9E: cpu.SubAC_hl,                       //SBC A, (HL)
+		0x9F: func() { cpu.SubAC_r(a) },          //SBC A, A
+		0xA0: func() { cpu.AndA_r(b) },           //AND A, B
+		0xA1: func() { cpu.AndA_r(c) },           //AND A, C
+		0xA2: func() { cpu.AndA_r(d) },           //AND A, D
+		0xA3: func() { cpu.AndA_r(e) },           //AND A, E
+		0xA4: func() { cpu.AndA_r(h) },           //AND A, H
+		0xA5: func() { cpu.AndA_r(l) },           //AND A, L
+		0xA6: cpu.AndA_hl,                        //AND A, (HL)
+		0xA7: func() { cpu.AndA_r(a) },           //AND A, A
+		0xA8: func() { cpu.XorA_r(b) },           //XOR A, B
+		0xA9: func() { cpu.XorA_r(c) },           //XOR A, C
+		0xAA: func() { cpu.XorA_r(d) },           //XOR A, D
+		0xAB: func() { cpu.XorA_r(e) },           //XOR A, E
+		0xAC: func() { cpu.XorA_r(h) },           //XOR A, H
+		0xAD: func() { cpu.XorA_r(l) },           //XOR A, L
+		0xAE: cpu.XorA_hl,                        //XOR A,(HL)
+		0xAF: func() { cpu.XorA_r(a) },           //XOR A, A
+		0xB0: func() { cpu.OrA_r(b) },            //OR A, B
+		0xB1: func() { cpu.OrA_r(c) },            //OR A, C
+		0xB2: func() { cpu.OrA_r(d) },            //OR A, D
+		0xB3: func() { cpu.OrA_r(e) },            //OR A, E
+		0xB4: func() { cpu.OrA_r(h) },            //OR A, H
+		0xB5: func() { cpu.OrA_r(l) },            //OR A, L
+		0xB6: cpu.OrA_hl,                         //OR A,(HL)
+		0xB7: func() { cpu.OrA_r(a) },            //OR A, A
+		0xB8: func() { cpu.CPA_r(b) },            //CP A, B
+		0xB9: func() { cpu.CPA_r(c) },            //CP A, C
+		0xBA: func() { cpu.CPA_r(d) },            //CP A, D
+		0xBB: func() { cpu.CPA_r(e) },            //CP A, E
+		0xBC: func() { cpu.CPA_r(h) },            //CP A, H
+		0xBD: func() { cpu.CPA_r(l) },            //CP A, L
+		0xBE: cpu.CPA_hl,                         //CP A, (HL)
+		0xBF: func() { cpu.CPA_r(a) },            //CP A, A
+		0xC0: func() { cpu.Retcc(Z, false) },     //RET NZ
+		0xC1: func() { cpu.Pop_nn(b, c) },        //POP BC
+		0xC2: func() { cpu.JPcc_nn(Z, false) },   //JP NZ,nn
+		0xC3: cpu.JP_nn,                          //JP nn
+		0xC4: func() { cpu.Callcc_nn(Z, false) }, //CALL NZ, nn
+		0xC5: func() { cpu.Push_nn(b, c) },       //PUSH BC
+		0xC6: cpu.AddA_n,                         //ADD A,#
+		0xC7: func() { cpu.Rst(0x00) },           //RST n
+		0xC8: func() { cpu.Retcc(Z, true) },      //RET Z
+		0xC9: cpu.Ret,                            //RET
+		0xCA: func() { cpu.JPcc_nn(Z, true) },    //JP Z,nn
+		0xCC: func() { cpu.Callcc_nn(Z, true) },  //CALL Z, nn
+		0xCD: cpu.Call_nn,                        //CALL nn
+		0xCE: cpu.AddCA_n,                        //ADC A, n
+		0xCF: func() { cpu.Rst(0x08) },           //RST n
+		0xD0: func() { cpu.Retcc(C, false) },     //RET NC
+		0xD1: func() { cpu.Pop_nn(d, e) },        //POP DE
+		0xD2: func() { cpu.JPcc_nn(C, false) },   //JP NC,nn
+		0xD4: func() { cpu.Callcc_nn(C, false) }, //CALL NC, nn
+		0xD5: func() { cpu.Push_nn(d, e) },       //PUSH DE
+		0xD6: cpu.SubA_n,                         //SUB A, n
+		0xD7: func() { cpu.Rst(0x10) },           //RST n
+		0xD8: func() { cpu.Retcc(C, true) },      //RET C
+		0xD9: cpu.Ret_i,                          //RETI
+		0xDA: func() { cpu.JPcc_nn(C, true) },    //JP C,nn
+		0xDC: func() { cpu.Callcc_nn(C, true) },  //CALL C, nn
+		0xDE: cpu.SubAC_n,                        //SBC A, n
+		0xDF: func() { cpu.Rst(0x18) },           //RST n
+		0xE0: func() { cpu.LDHn_r(a) },           //LDH n, A
+		0xE1: func() { cpu.Pop_nn(h, l) },        //POP HL
+		0xE2: func() { cpu.LDffplusc_r(a) },      //LD (C),A
+		0xE5: func() { cpu.Push_nn(h, l) },       //PUSH HL
+		0xE6: cpu.AndA_n,                         //AND A, n
+		0xE7: func() { cpu.Rst(0x20) },           //RST n
+		0xE8: cpu.Addsp_n,                        //ADD SP,n
+		0xE9: cpu.JP_hl,                          //JP (HL)
+		0xEA: func() { cpu.LDnn_r(a) },           //LD (nn), A
+		0xEE: cpu.XorA_n,                         //XOR A, n
+		0xEF: func() { cpu.Rst(0x28) },           //RST n
+		0xF0: func() { cpu.LDHr_n(a) },           //LDH r, n
+		0xF1: cpu.Pop_AF,                         //POP AF
+		0xF2: func() { cpu.LDr_ffplusc(a) },      //LD A,(C)
+		0xF3: cpu.DI,                             //DI
+		0xF5: func() { cpu.Push_nn(a, f) },       //PUSH AF
+		0xF6: cpu.OrA_n,                          //OR A, n
+		0xF7: func() { cpu.Rst(0x30) },           //RST n
+		0xF8: cpu.LDHLSP_n,                       //LDHL SP, n
+		0xF9: cpu.LDSP_hl,                        //LD SP, HL
+		0xFA: func() { cpu.LDr_nn(a) },           //LD A, (nn)
+		0xFB: cpu.EI,                             //EI
+		0xFE: cpu.CPA_n,                          //CP A, n
+		0xFF: func() { cpu.Rst(0x38) },           //RST n
+	}
+
+	var executesCB [256]func() = [256]func(){
+		0x00: func() { cpu.Rlc_r(b) },        //RLC B
+		0x01: func() { cpu.Rlc_r(c) },        //RLC C
+		0x02: func() { cpu.Rlc_r(d) },        //RLC D
+		0x03: func() { cpu.Rlc_r(e) },        //RLC E
+		0x04: func() { cpu.Rlc_r(h) },        //RLC H
+		0x05: func() { cpu.Rlc_r(l) },        //RLC L
+		0x06: cpu.Rlc_hl,                     //RLC (HL)
+		0x07: func() { cpu.Rlc_r(a) },        //RLC A
+		0x08: func() { cpu.Rrc_r(b) },        //RRC B
+		0x09: func() { cpu.Rrc_r(c) },        //RRC C
+		0x0A: func() { cpu.Rrc_r(d) },        //RRC D
+		0x0B: func() { cpu.Rrc_r(e) },        //RRC E
+		0x0C: func() { cpu.Rrc_r(h) },        //RRC H
+		0x0D: func() { cpu.Rrc_r(l) },        //RRC L
+		0x0E: cpu.Rrc_hl,                     //RRC (HL)
+		0x0F: func() { cpu.Rrc_r(a) },        //RRC A
+		0x10: func() { cpu.Rl_r(b) },         //RL B
+		0x11: func() { cpu.Rl_r(c) },         //RL C
+		0x12: func() { cpu.Rl_r(d) },         //RL D
+		0x13: func() { cpu.Rl_r(e) },         //RL E
+		0x14: func() { cpu.Rl_r(h) },         //RL H
+		0x15: func() { cpu.Rl_r(l) },         //RL L
+		0x16: cpu.Rl_hl,                      //RL (HL)
+		0x17: func() { cpu.Rl_r(a) },         //RL A
+		0x18: func() { cpu.Rr_r(b) },         //RR B
+		0x19: func() { cpu.Rr_r(c) },         //RR C
+		0x1A: func() { cpu.Rr_r(d) },         //RR D
+		0x1B: func() { cpu.Rr_r(e) },         //RR E
+		0x1C: func() { cpu.Rr_r(h) },         //RR H
+		0x1D: func() { cpu.Rr_r(l) },         //RR L
+		0x1E: cpu.Rr_hl,                      //RR (HL)
+		0x1F: func() { cpu.Rr_r(a) },         //RR A
+		0x20: func() { cpu.Sla_r(b) },        //SLA B
+		0x21: func() { cpu.Sla_r(c) },        //SLA C
+		0x22: func() { cpu.Sla_r(d) },        //SLA D
+		0x23: func() { cpu.Sla_r(e) },        //SLA E
+		0x24: func() { cpu.Sla_r(h) },        //SLA H
+		0x25: func() { cpu.Sla_r(l) },        //SLA L
+		0x26: cpu.Sla_hl,                     //SLA (HL)
+		0x27: func() { cpu.Sla_r(a) },        //SLA A
+		0x28: func() { cpu.Sra_r(b) },        //SRA B
+		0x29: func() { cpu.Sra_r(c) },        //SRA C
+		0x2A: func() { cpu.Sra_r(d) },        //SRA D
+		0x2B: func() { cpu.Sra_r(e) },        //SRA E
+		0x2C: func() { cpu.Sra_r(h) },        //SRA H
+		0x2D: func() { cpu.Sra_r(l) },        //SRA L
+		0x2E: cpu.Sra_hl,                     //SRA (HL)
+		0x2F: func() { cpu.Sra_r(a) },        //SRA A
+		0x30: func() { cpu.Swap_r(b) },       //SWAP B
+		0x31: func() { cpu.Swap_r(c) },       //SWAP C
+		0x32: func() { cpu.Swap_r(d) },       //SWAP D
+		0x33: func() { cpu.Swap_r(e) },       //SWAP E
+		0x34: func() { cpu.Swap_r(h) },       //SWAP H
+		0x35: func() { cpu.Swap_r(l) },       //SWAP L
+		0x36: cpu.Swap_hl,                    //SWAP (HL)
+		0x37: func() { cpu.Swap_r(a) },       //SWAP A
+		0x38: func() { cpu.Srl_r(b) },        //SRL B
+		0x39: func() { cpu.Srl_r(c) },        //SRL C
+		0x3A: func() { cpu.Srl_r(d) },        //SRL D
+		0x3B: func() { cpu.Srl_r(e) },        //SRL E
+		0x3C: func() { cpu.Srl_r(h) },        //SRL H
+		0x3D: func() { cpu.Srl_r(l) },        //SRL L
+		0x3E: cpu.Srl_hl,                     //SRL (HL)
+		0x3F: func() { cpu.Srl_r(a) },        //SRL A
+		0x40: func() { cpu.Bitb_r(0x00, b) }, //BIT 0, B
+		0x41: func() { cpu.Bitb_r(0x00, c) }, //BIT 0, C
+		0x42: func() { cpu.Bitb_r(0x00, d) }, //BIT 0, D
+		0x43: func() { cpu.Bitb_r(0x00, e) }, //BIT 0, E
+		0x44: func() { cpu.Bitb_r(0x00, h) }, //BIT 0, H
+		0x45: func() { cpu.Bitb_r(0x00, l) }, //BIT 0, L
+		0x46: func() { cpu.Bitb_hl(0x00) },   //BIT 0, (HL)
+		0x47: func() { cpu.Bitb_r(0x00, a) }, //BIT 0, A
+		0x48: func() { cpu.Bitb_r(0x01, b) }, //BIT 1, B
+		0x49: func() { cpu.Bitb_r(0x01, c) }, //BIT 1, C
+		0x4A: func() { cpu.Bitb_r(0x01, d) }, //BIT 1, D
+		0x4B: func() { cpu.Bitb_r(0x01, e) }, //BIT 1, E
+		0x4C: func() { cpu.Bitb_r(0x01, h) }, //BIT 1, H
+		0x4D: func() { cpu.Bitb_r(0x01, l) }, //BIT 1, L
+		0x4E: func() { cpu.Bitb_hl(0x01) },   //BIT 1, (HL)
+		0x4F: func() { cpu.Bitb_r(0x01, a) }, //BIT 1, A
+		0x50: func() { cpu.Bitb_r(0x02, b) }, //BIT 2, B
+		0x51: func() { cpu.Bitb_r(0x02, c) }, //BIT 2, C
+		0x52: func() { cpu.Bitb_r(0x02, d) }, //BIT 2, D
+		0x53: func() { cpu.Bitb_r(0x02, e) }, //BIT 2, E
+		0x54: func() { cpu.Bitb_r(0x02, h) }, //BIT 2, H
+		0x55: func() { cpu.Bitb_r(0x02, l) }, //BIT 2, L
+		0x56: func() { cpu.Bitb_hl(0x02) },   //BIT 2, (HL)
+		0x57: func() { cpu.Bitb_r(0x02, a) }, //BIT 2, A
+		0x58: func() { cpu.Bitb_r(0x03, b) }, //BIT 3, B
+		0x59: func() { cpu.Bitb_r(0x03, c) }, //BIT 3, C
+		0x5A: func() { cpu.Bitb_r(0x03, d) }, //BIT 3, D
+		0x5B: func() { cpu.Bitb_r(0x03, e) }, //BIT 3, E
+		0x5C: func() { cpu.Bitb_r(0x03, h) }, //BIT 3, H
+		0x5D: func() { cpu.Bitb_r(0x03, l) }, //BIT 3, L
+		0x5E: func() { cpu.Bitb_hl(0x03) },   //BIT 3, (HL)
+		0x5F: func() { cpu.Bitb_r(0x03, a) }, //BIT 3, A
+		0x60: func() { cpu.Bitb_r(0x04, b) }, //BIT 4, B
+		0x61: func() { cpu.Bitb_r(0x04, c) }, //BIT 4, C
+		0x62: func() { cpu.Bitb_r(0x04, d) }, //BIT 4, D
+		0x63: func() { cpu.Bitb_r(0x04, e) }, //BIT 4, E
+		0x64: func() { cpu.Bitb_r(0x04, h) }, //BIT 4, H
+		0x65: func() { cpu.Bitb_r(0x04, l) }, //BIT 4, L
+		0x66: func() { cpu.Bitb_hl(0x04) },   //BIT 4, (HL)
+		0x67: func() { cpu.Bitb_r(0x04, a) }, //BIT 4, A
+		0x68: func() { cpu.Bitb_r(0x05, b) }, //BIT 5, B
+		0x69: func() { cpu.Bitb_r(0x05, c) }, //BIT 5, C
+		0x6A: func() { cpu.Bitb_r(0x05, d) }, //BIT 5, D
+		0x6B: func() { cpu.Bitb_r(0x05, e) }, //BIT 5, E
+		0x6C: func() { cpu.Bitb_r(0x05, h) }, //BIT 5, H
+		0x6D: func() { cpu.Bitb_r(0x05, l) }, //BIT 5, L
+		0x6E: func() { cpu.Bitb_hl(0x05) },   //BIT 5, (HL)
+		0x6F: func() { cpu.Bitb_r(0x05, a) }, //BIT 5, A
+		0x70: func() { cpu.Bitb_r(0x06, b) }, //BIT 6, B
+		0x71: func() { cpu.Bitb_r(0x06, c) }, //BIT 6, C
+		0x72: func() { cpu.Bitb_r(0x06, d) }, //BIT 6, D
+		0x73: func() { cpu.Bitb_r(0x06, e) }, //BIT 6, E
+		0x74: func() { cpu.Bitb_r(0x06, h) }, //BIT 6, H
+		0x75: func() { cpu.Bitb_r(0x06, l) }, //BIT 6, L
+		0x76: func() { cpu.Bitb_hl(0x06) },   //BIT 6, (HL)
+		0x77: func() { cpu.Bitb_r(0x06, a) }, //BIT 6, A
+		0x78: func() { cpu.Bitb_r(0x07, b) }, //BIT 7, B
+		0x79: func() { cpu.Bitb_r(0x07, c) }, //BIT 7, C
+		0x7A: func() { cpu.Bitb_r(0x07, d) }, //BIT 7, D
+		0x7B: func() { cpu.Bitb_r(0x07, e) }, //BIT 7, E
+		0x7C: func() { cpu.Bitb_r(0x07, h) }, //BIT 7, H
+		0x7D: func() { cpu.Bitb_r(0x07, l) }, //BIT 7, L
+		0x7E: func() { cpu.Bitb_hl(0x07) },   //BIT 7, (HL)
+		0x7F: func() { cpu.Bitb_r(0x07, a) }, //BIT 7, A
+		0x80: func() { cpu.Resb_r(0x00, b) }, //RES 0, B
+		0x81: func() { cpu.Resb_r(0x00, c) }, //RES 0, C
+		0x82: func() { cpu.Resb_r(0x00, d) }, //RES 0, D
+		0x83: func() { cpu.Resb_r(0x00, e) }, //RES 0, E
+		0x84: func() { cpu.Resb_r(0x00, h) }, //RES 0, H
+		0x85: func() { cpu.Resb_r(0x00, l) }, //RES 0, L
+		0x86: func() { cpu.Resb_hl(0x00) },   //RES 0,(HL)
+		0x87: func() { cpu.Resb_r(0x00, a) }, //RES 0, A
+		0x88: func() { cpu.Resb_r(0x01, b) }, //RES 1, B
+		0x89: func() { cpu.Resb_r(0x01, c) }, //RES 1, C
+		0x8A: func() { cpu.Resb_r(0x01, d) }, //RES 1, D
+		0x8B: func() { cpu.Resb_r(0x01, e) }, //RES 1, E
+		0x8C: func() { cpu.Resb_r(0x01, h) }, //RES 1, H
+		0x8D: func() { cpu.Resb_r(0x01, l) }, //RES 1, L
+		0x8E: func() { cpu.Resb_hl(0x01) },   //RES 1,(HL)
+		0x8F: func() { cpu.Resb_r(0x01, a) }, //RES 1, A
+		0x90: func() { cpu.Resb_r(0x02, b) }, //RES 2, B
+		0x91: func() { cpu.Resb_r(0x02, c) }, //RES 2, C
+		0x92: func() { cpu.Resb_r(0x02, d) }, //RES 2, D
+		0x93: func() { cpu.Resb_r(0x02, e) }, //RES 2, E
+		0x94: func() { cpu.Resb_r(0x02, h) }, //RES 2, H
+		0x95: func() { cpu.Resb_r(0x02, l) }, //RES 2, L
+		0x96: func() { cpu.Resb_hl(0x02) },   //RES 2,(HL)
+		0x97: func() { cpu.Resb_r(0x02, a) }, //RES 2, A
+		0x98: func() { cpu.Resb_r(0x03, b) }, //RES 3, B
+		0x99: func() { cpu.Resb_r(0x03, c) }, //RES 3, C
+		0x9A: func() { cpu.Resb_r(0x03, d) }, //RES 3, D
+		0x9B: func() { cpu.Resb_r(0x03, e) }, //RES 3, E
+		0x9C: func() { cpu.Resb_r(0x03, h) }, //RES 3, H
+		0x9D: func() { cpu.Resb_r(0x03, l) }, //RES 3, L
+		0x9E: func() { cpu.Resb_hl(0x03) },   //RES 3,(HL)
+		0x9F: func() { cpu.Resb_r(0x03, a) }, //RES 3, A
+		0xA0: func() { cpu.Resb_r(0x04, b) }, //RES 4, B
+		0xA1: func() { cpu.Resb_r(0x04, c) }, //RES 4, C
+		0xA2: func() { cpu.Resb_r(0x04, d) }, //RES 4, D
+		0xA3: func() { cpu.Resb_r(0x04, e) }, //RES 4, E
+		0xA4: func() { cpu.Resb_r(0x04, h) }, //RES 4, H
+		0xA5: func() { cpu.Resb_r(0x04, l) }, //RES 4, L
+		0xA6: func() { cpu.Resb_hl(0x04) },   //RES 4,(HL)
+		0xA7: func() { cpu.Resb_r(0x04, a) }, //RES 4, A
+		0xA8: func() { cpu.Resb_r(0x05, b) }, //RES 5, B
+		0xA9: func() { cpu.Resb_r(0x05, c) }, //RES 5, C
+		0xAA: func() { cpu.Resb_r(0x05, d) }, //RES 5, D
+		0xAB: func() { cpu.Resb_r(0x05, e) }, //RES 5, E
+		0xAC: func() { cpu.Resb_r(0x05, h) }, //RES 5, H
+		0xAD: func() { cpu.Resb_r(0x05, l) }, //RES 5, L
+		0xAE: func() { cpu.Resb_hl(0x05) },   //RES 5,(HL)
+		0xAF: func() { cpu.Resb_r(0x05, a) }, //RES 5, A
+		0xB0: func() { cpu.Resb_r(0x06, b) }, //RES 6, B
+		0xB1: func() { cpu.Resb_r(0x06, c) }, //RES 6, C
+		0xB2: func() { cpu.Resb_r(0x06, d) }, //RES 6, D
+		0xB3: func() { cpu.Resb_r(0x06, e) }, //RES 6, E
+		0xB4: func() { cpu.Resb_r(0x06, h) }, //RES 6, H
+		0xB5: func() { cpu.Resb_r(0x06, l) }, //RES 6, L
+		0xB6: func() { cpu.Resb_hl(0x06) },   //RES 6,(HL)
+		0xB7: func() { cpu.Resb_r(0x06, a) }, //RES 6, A
+		0xB8: func() { cpu.Resb_r(0x07, b) }, //RES 7, B
+		0xB9: func() { cpu.Resb_r(0x07, c) }, //RES 7, C
+		0xBA: func() { cpu.Resb_r(0x07, d) }, //RES 7, D
+		0xBB: func() { cpu.Resb_r(0x07, e) }, //RES 7, E
+		0xBC: func() { cpu.Resb_r(0x07, h) }, //RES 7, H
+		0xBD: func() { cpu.Resb_r(0x07, l) }, //RES 7, L
+		0xBE: func() { cpu.Resb_hl(0x07) },   //RES 7,(HL)
+		0xBF: func() { cpu.Resb_r(0x07, a) }, //RES 7, A
+		0xC0: func() { cpu.Setb_r(0x00, b) }, //SET 0, B
+		0xC1: func() { cpu.Setb_r(0x00, c) }, //SET 0, C
+		0xC2: func() { cpu.Setb_r(0x00, d) }, //SET 0, D
+		0xC3: func() { cpu.Setb_r(0x00, e) }, //SET 0, E
+		0xC4: func() { cpu.Setb_r(0x00, h) }, //SET 0, H
+		0xC5: func() { cpu.Setb_r(0x00, l) }, //SET 0, L
+		0xC6: func() { cpu.Setb_hl(0x00) },   //SET 0, (HL)
+		0xC7: func() { cpu.Setb_r(0x00, a) }, //SET 0, A
+		0xC8: func() { cpu.Setb_r(0x01, b) }, //SET 1, B
+		0xC9: func() { cpu.Setb_r(0x01, c) }, //SET 1, C
+		0xCA: func() { cpu.Setb_r(0x01, d) }, //SET 1, D
+		0xCB: func() { cpu.Setb_r(0x01, e) }, //SET 1, E
+		0xCC: func() { cpu.Setb_r(0x01, h) }, //SET 1, H
+		0xCD: func() { cpu.Setb_r(0x01, l) }, //SET 1, L
+		0xCE: func() { cpu.Setb_hl(0x01) },   //SET 1, (HL)
+		0xCF: func() { cpu.Setb_r(0x01, a) }, //SET 1, A
+		0xD0: func() { cpu.Setb_r(0x02, b) }, //SET 2, B
+		0xD1: func() { cpu.Setb_r(0x02, c) }, //SET 2, C
+		0xD2: func() { cpu.Setb_r(0x02, d) }, //SET 2, D
+		0xD3: func() { cpu.Setb_r(0x02, e) }, //SET 2, E
+		0xD4: func() { cpu.Setb_r(0x02, h) }, //SET 2, H
+		0xD5: func() { cpu.Setb_r(0x02, l) }, //SET 2, L
+		0xD6: func() { cpu.Setb_hl(0x02) },   //SET 2, (HL)
+		0xD7: func() { cpu.Setb_r(0x02, a) }, //SET 2, A
+		0xD8: func() { cpu.Setb_r(0x03, b) }, //SET 3, B
+		0xD9: func() { cpu.Setb_r(0x03, c) }, //SET 3, C
+		0xDA: func() { cpu.Setb_r(0x03, d) }, //SET 3, D
+		0xDB: func() { cpu.Setb_r(0x03, e) }, //SET 3, E
+		0xDC: func() { cpu.Setb_r(0x03, h) }, //SET 3, H
+		0xDD: func() { cpu.Setb_r(0x03, l) }, //SET 3, L
+		0xDE: func() { cpu.Setb_hl(0x03) },   //SET 3, (HL)
+		0xDF: func() { cpu.Setb_r(0x03, a) }, //SET 3, A
+		0xE0: func() { cpu.Setb_r(0x04, b) }, //SET 4, B
+		0xE1: func() { cpu.Setb_r(0x04, c) }, //SET 4, C
+		0xE2: func() { cpu.Setb_r(0x04, d) }, //SET 4, D
+		0xE3: func() { cpu.Setb_r(0x04, e) }, //SET 4, E
+		0xE4: func() { cpu.Setb_r(0x04, h) }, //SET 4, H
+		0xE5: func() { cpu.Setb_r(0x04, l) }, //SET 4, L
+		0xE6: func() { cpu.Setb_hl(0x04) },   //SET 4, (HL)
+		0xE7: func() { cpu.Setb_r(0x04, a) }, //SET 4, A
+		0xE8: func() { cpu.Setb_r(0x05, b) }, //SET 5, B
+		0xE9: func() { cpu.Setb_r(0x05, c) }, //SET 5, C
+		0xEA: func() { cpu.Setb_r(0x05, d) }, //SET 5, D
+		0xEB: func() { cpu.Setb_r(0x05, e) }, //SET 5, E
+		0xEC: func() { cpu.Setb_r(0x05, h) }, //SET 5, H
+		0xED: func() { cpu.Setb_r(0x05, l) }, //SET 5, L
+		0xEE: func() { cpu.Setb_hl(0x05) },   //SET 5, (HL)
+		0xEF: func() { cpu.Setb_r(0x05, a) }, //SET 5, A
+		0xF0: func() { cpu.Setb_r(0x06, b) }, //SET 6, B
+		0xF1: func() { cpu.Setb_r(0x06, c) }, //SET 6, C
+		0xF2: func() { cpu.Setb_r(0x06, d) }, //SET 6, D
+		0xF3: func() { cpu.Setb_r(0x06, e) }, //SET 6, E
+		0xF4: func() { cpu.Setb_r(0x06, h) }, //SET 6, H
+		0xF5: func() { cpu.Setb_r(0x06, l) }, //SET 6, L
+		0xF6: func() { cpu.Setb_hl(0x06) },   //SET 6, (HL)
+		0xF7: func() { cpu.Setb_r(0x06, a) }, //SET 6, A
+		0xF8: func() { cpu.Setb_r(0x07, b) }, //SET 7, B
+		0xF9: func() { cpu.Setb_r(0x07, c) }, //SET 7, C
+		0xFA: func() { cpu.Setb_r(0x07, d) }, //SET 7, D
+		0xFB: func() { cpu.Setb_r(0x07, e) }, //SET 7, E
+		0xFC: func() { cpu.Setb_r(0x07, h) }, //SET 7, H
+		0xFD: func() { cpu.Setb_r(0x07, l) }, //SET 7, L
+		0xFE: func() { cpu.Setb_hl(0x07) },   //SET 7, (HL)
+		0xFF: func() { cpu.Setb_r(0x07, a) }, //SET 7, A
+	}
+
+	for i := 0; i < 256; i++ {
+		cpu.handlers[i] = newHandler(executes[i], &Instructions[i])
+		cpu.handlersCB[i] = newHandler(executesCB[i], &InstructionsCB[i])
+	}
+}
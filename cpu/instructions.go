@@ -18,12 +18,12 @@ var EMPTY_INSTRUCTION Instruction = Instruction{}
 
 var Instructions []Instruction = []Instruction{
 	Instruction{0x00, "NOP", 0, 1, [2]byte{}},
-	Instruction{0x01, "LD  BC,(a16)", 2, 3, [2]byte{}},
+	Instruction{0x01, "LD  BC,d16", 2, 3, [2]byte{}},
 	Instruction{0x02, "LD  (BC),A", 0, 2, [2]byte{}},
 	Instruction{0x03, "INC  BC", 0, 2, [2]byte{}},
 	Instruction{0x04, "INC  B", 0, 1, [2]byte{}},
 	Instruction{0x05, "DEC  B", 0, 1, [2]byte{}},
-	Instruction{0x06, "LD  B,n", 1, 2, [2]byte{}},
+	Instruction{0x06, "LD  B,d8", 1, 2, [2]byte{}},
 	Instruction{0x07, "RLCA", 0, 1, [2]byte{}},
 	Instruction{0x08, "LD  (a16),SP", 2, 5, [2]byte{}},
 	Instruction{0x09, "ADD  HL,BC", 0, 2, [2]byte{}},
@@ -31,15 +31,15 @@ var Instructions []Instruction = []Instruction{
 	Instruction{0x0B, "DEC  BC", 0, 2, [2]byte{}},
 	Instruction{0x0C, "INC  C", 0, 1, [2]byte{}},
 	Instruction{0x0D, "DEC  C", 0, 1, [2]byte{}},
-	Instruction{0x0E, "LD  C,n", 1, 2, [2]byte{}},
+	Instruction{0x0E, "LD  C,d8", 1, 2, [2]byte{}},
 	Instruction{0x0F, "RRCA", 0, 1, [2]byte{}},
 	Instruction{0x10, "STOP", 1, 0, [2]byte{}},
-	Instruction{0x11, "LD  DE,(a16)", 2, 3, [2]byte{}},
+	Instruction{0x11, "LD  DE,d16", 2, 3, [2]byte{}},
 	Instruction{0x12, "LD  (DE),A", 0, 2, [2]byte{}},
 	Instruction{0x13, "INC  DE", 0, 2, [2]byte{}},
 	Instruction{0x14, "INC  D", 0, 1, [2]byte{}},
 	Instruction{0x15, "DEC  D", 0, 1, [2]byte{}},
-	Instruction{0x16, "LD  D,n", 1, 2, [2]byte{}},
+	Instruction{0x16, "LD  D,d8", 1, 2, [2]byte{}},
 	Instruction{0x17, "RLA", 0, 1, [2]byte{}},
 	Instruction{0x18, "JR  r8", 1, 3, [2]byte{}},
 	Instruction{0x19, "ADD  HL,DE", 0, 2, [2]byte{}},
@@ -47,7 +47,7 @@ var Instructions []Instruction = []Instruction{
 	Instruction{0x1B, "DEC  DE", 0, 2, [2]byte{}},
 	Instruction{0x1C, "INC  E", 0, 1, [2]byte{}},
 	Instruction{0x1D, "DEC  E", 0, 1, [2]byte{}},
-	Instruction{0x1E, "LD E,n", 1, 2, [2]byte{}},
+	Instruction{0x1E, "LD  E,d8", 1, 2, [2]byte{}},
 	Instruction{0x1F, "RRA", 0, 1, [2]byte{}},
 	Instruction{0x20, "JR  NZ,r8", 1, 0, [2]byte{}},
 	Instruction{0x21, "LD  HL,d16", 2, 3, [2]byte{}},
@@ -55,7 +55,7 @@ var Instructions []Instruction = []Instruction{
 	Instruction{0x23, "INC  HL", 0, 2, [2]byte{}},
 	Instruction{0x24, "INC  H", 0, 1, [2]byte{}},
 	Instruction{0x25, "DEC  H", 0, 1, [2]byte{}},
-	Instruction{0x26, "LD H,n", 1, 2, [2]byte{}},
+	Instruction{0x26, "LD  H,d8", 1, 2, [2]byte{}},
 	Instruction{0x27, "DAA", 0, 1, [2]byte{}},
 	Instruction{0x28, "JR  Z,r8", 1, 0, [2]byte{}},
 	Instruction{0x29, "ADD  HL,HL", 0, 2, [2]byte{}},
@@ -63,10 +63,10 @@ var Instructions []Instruction = []Instruction{
 	Instruction{0x2B, "DEC  HL", 0, 2, [2]byte{}},
 	Instruction{0x2C, "INC  L", 0, 1, [2]byte{}},
 	Instruction{0x2D, "DEC  L", 0, 1, [2]byte{}},
-	Instruction{0x2E, "LD L,n", 1, 2, [2]byte{}},
+	Instruction{0x2E, "LD  L,d8", 1, 2, [2]byte{}},
 	Instruction{0x2F, "CPL", 0, 1, [2]byte{}},
 	Instruction{0x30, "JR  NC,r8", 1, 0, [2]byte{}},
-	Instruction{0x31, "LD SP, d16", 2, 3, [2]byte{}},
+	Instruction{0x31, "LD  SP,d16", 2, 3, [2]byte{}},
 	Instruction{0x32, "LD  (HL-),A", 0, 2, [2]byte{}},
 	Instruction{0x33, "INC  SP", 0, 2, [2]byte{}},
 	Instruction{0x34, "INC  (HL)", 0, 3, [2]byte{}},
@@ -216,7 +216,7 @@ var Instructions []Instruction = []Instruction{
 	Instruction{0xC4, "CALL  NZ,a16", 2, 0, [2]byte{}},
 	Instruction{0xC5, "PUSH BC", 0, 4, [2]byte{}},
 	Instruction{0xC6, "ADD  A,d8", 1, 2, [2]byte{}},
-	Instruction{0xC7, "RST n", 0, 4, [2]byte{}},
+	Instruction{0xC7, "RST  $00", 0, 4, [2]byte{}},
 	Instruction{0xC8, "RET Z", 0, 0, [2]byte{}},
 	Instruction{0xC9, "RET", 0, 4, [2]byte{}},
 	Instruction{0xCA, "JP  Z,a16", 2, 0, [2]byte{}},
@@ -224,7 +224,7 @@ var Instructions []Instruction = []Instruction{
 	Instruction{0xCC, "CALL  Z,a16", 2, 0, [2]byte{}},
 	Instruction{0xCD, "CALL a16", 2, 6, [2]byte{}},
 	Instruction{0xCE, "ADC  A,d8", 1, 2, [2]byte{}},
-	Instruction{0xCF, "RST n", 0, 4, [2]byte{}},
+	Instruction{0xCF, "RST  $08", 0, 4, [2]byte{}},
 	Instruction{0xD0, "RET NC", 0, 0, [2]byte{}},
 	Instruction{0xD1, "POP  DE", 0, 3, [2]byte{}},
 	Instruction{0xD2, "JP  NC,a16", 2, 0, [2]byte{}},
@@ -232,7 +232,7 @@ var Instructions []Instruction = []Instruction{
 	Instruction{0xD4, "CALL  NC,a16", 2, 0, [2]byte{}},
 	Instruction{0xD5, "PUSH DE", 0, 4, [2]byte{}},
 	Instruction{0xD6, "SUB  d8", 1, 2, [2]byte{}},
-	Instruction{0xD7, "RST n", 0, 4, [2]byte{}},
+	Instruction{0xD7, "RST  $10", 0, 4, [2]byte{}},
 	Instruction{0xD8, "RET C", 0, 0, [2]byte{}},
 	Instruction{0xD9, "RETI", 0, 4, [2]byte{}},
 	Instruction{0xDA, "JP  C,a16", 2, 0, [2]byte{}},
@@ -240,7 +240,7 @@ var Instructions []Instruction = []Instruction{
 	Instruction{0xDC, "CALL  C,a16", 2, 0, [2]byte{}},
 	EMPTY_INSTRUCTION,
 	Instruction{0xDE, "SBC  A,d8", 1, 2, [2]byte{}},
-	Instruction{0xDF, "RST  n", 0, 4, [2]byte{}},
+	Instruction{0xDF, "RST  $18", 0, 4, [2]byte{}},
 	Instruction{0xE0, "LDH  (a8),A", 1, 3, [2]byte{}},
 	Instruction{0xE1, "POP  HL", 0, 3, [2]byte{}},
 	Instruction{0xE2, "LD  (C),A", 0, 2, [2]byte{}},
@@ -248,7 +248,7 @@ var Instructions []Instruction = []Instruction{
 	EMPTY_INSTRUCTION,
 	Instruction{0xE5, "PUSH HL", 0, 4, [2]byte{}},
 	Instruction{0xE6, "AND  d8", 1, 2, [2]byte{}},
-	Instruction{0xE7, "RST n", 0, 4, [2]byte{}},
+	Instruction{0xE7, "RST  $20", 0, 4, [2]byte{}},
 	Instruction{0xE8, "ADD  SP,r8", 1, 4, [2]byte{}},
 	Instruction{0xE9, "JP  (HL)", 0, 1, [2]byte{}},
 	Instruction{0xEA, "LD  (a16),A", 2, 4, [2]byte{}},
@@ -256,7 +256,7 @@ var Instructions []Instruction = []Instruction{
 	EMPTY_INSTRUCTION,
 	EMPTY_INSTRUCTION,
 	Instruction{0xEE, "XOR  d8", 1, 2, [2]byte{}},
-	Instruction{0xEF, "RST n", 0, 4, [2]byte{}},
+	Instruction{0xEF, "RST  $28", 0, 4, [2]byte{}},
 	Instruction{0xF0, "LDH  A,(a8)", 1, 3, [2]byte{}},
 	Instruction{0xF1, "POP  AF", 0, 3, [2]byte{}},
 	Instruction{0xF2, "LD  A,(C)", 0, 2, [2]byte{}},
@@ -264,7 +264,7 @@ var Instructions []Instruction = []Instruction{
 	EMPTY_INSTRUCTION,
 	Instruction{0xF5, "PUSH AF", 0, 4, [2]byte{}},
 	Instruction{0xF6, "OR  d8", 1, 2, [2]byte{}},
-	Instruction{0xF7, "RST n", 0, 4, [2]byte{}},
+	Instruction{0xF7, "RST  $30", 0, 4, [2]byte{}},
 	Instruction{0xF8, "LD  HL,SP+r8", 1, 3, [2]byte{}},
 	Instruction{0xF9, "LD  SP,HL", 0, 2, [2]byte{}},
 	Instruction{0xFA, "LD  A,(a16)", 2, 4, [2]byte{}},
@@ -272,7 +272,7 @@ var Instructions []Instruction = []Instruction{
 	EMPTY_INSTRUCTION,
 	EMPTY_INSTRUCTION,
 	Instruction{0xFE, "CP  d8", 1, 2, [2]byte{}},
-	Instruction{0xFF, "RST n", 0, 4, [2]byte{}},
+	Instruction{0xFF, "RST  $38", 0, 4, [2]byte{}},
 }
 
 var InstructionsCB []Instruction = []Instruction{
@@ -0,0 +1,224 @@
+package cpu
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/constants"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Directory holding the SM83 single step tests (https://github.com/SingleStepTests/sm83),
+//one JSON file per opcode named like "00.json" or "cb 00.json" (optionally gzipped).
+//The tests are skipped when this is not set
+const SM83_TESTS_ENV string = "SM83_TESTS"
+
+//HALT and STOP depend on the rest of the system so are not run
+var sm83SkippedFiles map[string]bool = map[string]bool{"10": true, "76": true}
+
+//the most failures reported for a single opcode before moving on
+const sm83MaxFailures int = 5
+
+type sm83State struct {
+	PC  uint16   `json:"pc"`
+	SP  uint16   `json:"sp"`
+	A   byte     `json:"a"`
+	B   byte     `json:"b"`
+	C   byte     `json:"c"`
+	D   byte     `json:"d"`
+	E   byte     `json:"e"`
+	F   byte     `json:"f"`
+	H   byte     `json:"h"`
+	L   byte     `json:"l"`
+	IME byte     `json:"ime"`
+	IE  byte     `json:"ie"`
+	EI  *byte    `json:"ei"`
+	RAM [][2]int `json:"ram"`
+}
+
+type sm83Test struct {
+	Name    string          `json:"name"`
+	Initial sm83State       `json:"initial"`
+	Final   sm83State       `json:"final"`
+	Cycles  [][]interface{} `json:"cycles"`
+}
+
+func TestSM83SingleStep(t *testing.T) {
+	dir := os.Getenv(SM83_TESTS_ENV)
+	if dir == "" {
+		t.Skip("Set " + SM83_TESTS_ENV + " to the directory of the SM83 JSON tests to run them")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json*"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("No SM83 tests found in %s (%v)", dir, err)
+	}
+	sort.Strings(files)
+
+	c, m := newTestCPU()
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".gz"), ".json")
+		if sm83SkippedFiles[name] {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			tests, err := loadSM83Tests(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var failures int
+			for _, test := range tests {
+				if err := runSM83Test(c, m, test); err != nil {
+					t.Errorf("%s: %v", test.Name, err)
+					if failures++; failures >= sm83MaxFailures {
+						t.Fatalf("Giving up on %s after %d failures", name, failures)
+					}
+				}
+			}
+		})
+	}
+}
+
+func loadSM83Tests(file string) ([]sm83Test, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var tests []sm83Test
+	err = json.NewDecoder(r).Decode(&tests)
+	return tests, err
+}
+
+//Runs a single instruction from the initial state and compares the result against the
+//final state and the bus activity of each M-cycle. The tests start with the PC pointing
+//at the opcode, so its fetch is the first cycle
+func runSM83Test(c *GbcCPU, m *MockMMU, test sm83Test) error {
+	in := test.Initial
+	m.memory[constants.INTERRUPT_ENABLED_FLAG_ADDR] = in.IE
+	for _, cell := range in.RAM {
+		m.memory[cell[0]] = byte(cell[1])
+	}
+	m.accesses = m.accesses[:0]
+	m.ticked = false
+
+	c.PC, c.SP = types.Word(in.PC), types.Word(in.SP)
+	c.R = Registers{A: in.A, B: in.B, C: in.C, D: in.D, E: in.E, F: in.F, H: in.H, L: in.L}
+	c.InterruptsEnabled = in.IME == 1
+	c.EIDelay = 0
+	c.Halted, c.Stopped, c.LockedUp, c.HaltBug, c.PCJumped = false, false, false, false, false
+
+	var cycles int = c.Step()
+	err := compareSM83Result(c, m, test, cycles)
+
+	//only the memory the test touched needs clearing for the next one
+	for _, cell := range in.RAM {
+		m.memory[cell[0]] = 0
+	}
+	for _, a := range m.accesses {
+		m.memory[a.address] = 0
+	}
+	m.memory[constants.INTERRUPT_ENABLED_FLAG_ADDR] = 0
+	return err
+}
+
+func compareSM83Result(c *GbcCPU, m *MockMMU, test sm83Test, cycles int) error {
+	out := test.Final
+	var diffs []string
+	check := func(name string, got, expected int) {
+		if got != expected {
+			diffs = append(diffs, fmt.Sprintf("%s = %X, expected %X", name, got, expected))
+		}
+	}
+
+	check("PC", int(c.PC), int(out.PC))
+	check("SP", int(c.SP), int(out.SP))
+	check("A", int(c.R.A), int(out.A))
+	check("F", int(c.R.F), int(out.F))
+	check("B", int(c.R.B), int(out.B))
+	check("C", int(c.R.C), int(out.C))
+	check("D", int(c.R.D), int(out.D))
+	check("E", int(c.R.E), int(out.E))
+	check("H", int(c.R.H), int(out.H))
+	check("L", int(c.R.L), int(out.L))
+
+	//EI only sets IME after the next instruction, some versions of the tests track
+	//that separately as "ei"
+	if out.EI != nil {
+		check("IME", boolToInt(c.InterruptsEnabled), int(out.IME))
+		check("EI", boolToInt(c.EIDelay > 0), int(*out.EI))
+	} else {
+		check("IME", boolToInt(c.InterruptsEnabled || c.EIDelay > 0), int(out.IME))
+	}
+
+	for _, cell := range out.RAM {
+		check(fmt.Sprintf("(%04X)", cell[0]), int(m.memory[cell[0]]), cell[1])
+	}
+
+	check("M-cycles", cycles, len(test.Cycles))
+
+	var expected []busAccess
+	for _, cycle := range test.Cycles {
+		if access, ok := parseSM83Cycle(cycle); ok {
+			expected = append(expected, access)
+		}
+	}
+	if fmt.Sprint(m.accesses) != fmt.Sprint(expected) {
+		diffs = append(diffs, fmt.Sprintf("bus accesses %v, expected %v", m.accesses, expected))
+	}
+
+	if len(diffs) > 0 {
+		return errors.New(strings.Join(diffs, ", "))
+	}
+	return nil
+}
+
+//A cycle is either null or [address, value, pins] where pins is "r-m" for a read,
+//"-wm" for a write and "---" when the bus is idle
+func parseSM83Cycle(cycle []interface{}) (busAccess, bool) {
+	if len(cycle) != 3 {
+		return busAccess{}, false
+	}
+	address, aok := cycle[0].(float64)
+	value, vok := cycle[1].(float64)
+	pins, pok := cycle[2].(string)
+	if !aok || !vok || !pok || len(pins) < 2 {
+		return busAccess{}, false
+	}
+
+	switch {
+	case pins[0] == 'r':
+		return busAccess{types.Word(address), byte(value), false}, true
+	case pins[1] == 'w':
+		return busAccess{types.Word(address), byte(value), true}, true
+	}
+	return busAccess{}, false
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package cpu
+
+import (
+	"github.com/djhworld/gomeboycolor/state"
+)
+
+func (cpu *GbcCPU) SaveState(s *state.Writer) {
+	s.Word(cpu.PC)
+	s.Word(cpu.SP)
+	s.Bytes([]byte{cpu.R.A, cpu.R.B, cpu.R.C, cpu.R.D, cpu.R.E, cpu.R.H, cpu.R.L, cpu.R.F})
+	s.Bool(cpu.InterruptsEnabled)
+	s.Bool(cpu.Halted)
+	s.Bool(cpu.Stopped)
+	s.Bool(cpu.LockedUp)
+	s.Bool(cpu.HaltBug)
+	s.Int(cpu.EIDelay)
+	s.Int(cpu.Speed)
+}
+
+func (cpu *GbcCPU) LoadState(s *state.Reader) {
+	cpu.PC = s.Word()
+	cpu.SP = s.Word()
+
+	var r [8]byte
+	s.Bytes(r[:])
+	cpu.R = Registers{A: r[0], B: r[1], C: r[2], D: r[3], E: r[4], H: r[5], L: r[6], F: r[7]}
+
+	cpu.InterruptsEnabled = s.Bool()
+	cpu.Halted = s.Bool()
+	cpu.Stopped = s.Bool()
+	cpu.LockedUp = s.Bool()
+	cpu.HaltBug = s.Bool()
+	cpu.EIDelay = s.Int()
+	cpu.Speed = s.Int()
+	if cpu.Speed != 1 && cpu.Speed != 2 {
+		s.Invalid("unsupported CPU speed %d", cpu.Speed)
+		cpu.Speed = 1
+	}
+
+	cpu.PCJumped = false
+	cpu.LastInstrCycle.Reset()
+}
@@ -0,0 +1,208 @@
+//Disassembles SM83 machine code using the instruction tables from the cpu package
+package disasm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/djhworld/gomeboycolor/cpu"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Reads the memory being disassembled, e.g. the MMU's ReadByte
+type Reader func(addr types.Word) byte
+
+type Instruction struct {
+	Address types.Word
+	Bank    int
+	Bytes   []byte
+	Text    string
+	Label   string     //label at Address, if there is one
+	Target  types.Word //where a jump, call or RST goes, valid when Branch is set
+	Branch  bool
+	Illegal bool
+}
+
+//Formats the instruction as "BB:AAAA  bytes  text"
+func (i Instruction) String() string {
+	var bytes []string = make([]string, len(i.Bytes))
+	for n, b := range i.Bytes {
+		bytes[n] = fmt.Sprintf("%02X", b)
+	}
+	return fmt.Sprintf("%02X:%04X  %-8s  %s", i.Bank, uint16(i.Address), strings.Join(bytes, " "), i.Text)
+}
+
+func (i Instruction) Length() int {
+	return len(i.Bytes)
+}
+
+type Disassembler struct {
+	//labels substituted for addresses, may be nil
+	Symbols *SymbolTable
+
+	//returns the ROM bank mapped at an address, may be nil in which case
+	//everything is treated as being in bank 0
+	Bank func(addr types.Word) int
+}
+
+func NewDisassembler(symbols *SymbolTable, bank func(addr types.Word) int) *Disassembler {
+	var d *Disassembler = new(Disassembler)
+	d.Symbols = symbols
+	d.Bank = bank
+	return d
+}
+
+//Decodes the instruction at addr
+func (d *Disassembler) Decode(read Reader, addr types.Word) Instruction {
+	return d.decode(func(a types.Word) (byte, bool) {
+		return read(a), true
+	}, addr)
+}
+
+//Decodes count instructions starting from start
+func (d *Disassembler) Range(read Reader, start types.Word, count int) []Instruction {
+	var result []Instruction = make([]Instruction, 0, count)
+	var addr types.Word = start
+	for n := 0; n < count; n++ {
+		i := d.Decode(read, addr)
+		result = append(result, i)
+		addr += types.Word(i.Length())
+	}
+	return result
+}
+
+//Decodes all of data as if it were loaded at origin. An instruction cut short by the
+//end of data is rendered as raw bytes
+func (d *Disassembler) Bytes(data []byte, origin types.Word) []Instruction {
+	read := func(a types.Word) (byte, bool) {
+		var offset int = int(a - origin)
+		if offset >= len(data) {
+			return 0, false
+		}
+		return data[offset], true
+	}
+
+	var result []Instruction
+	for offset := 0; offset < len(data); {
+		i := d.decode(read, origin+types.Word(offset))
+		result = append(result, i)
+		offset += i.Length()
+	}
+	return result
+}
+
+func (d *Disassembler) decode(read func(types.Word) (byte, bool), addr types.Word) Instruction {
+	var i Instruction
+	i.Address = addr
+	i.Bank = d.bankAt(addr)
+	i.Label, _ = d.Symbols.Lookup(i.Bank, addr)
+
+	opcode, _ := read(addr)
+	i.Bytes = []byte{opcode}
+
+	var instruction cpu.Instruction = cpu.Instructions[opcode]
+	if opcode == 0xCB {
+		if b, ok := read(addr + 1); ok {
+			i.Bytes = append(i.Bytes, b)
+			i.Text = mnemonic(cpu.InstructionsCB[b].Description)
+		} else {
+			i.Text = dataByte(opcode)
+		}
+		return i
+	}
+
+	if instruction == cpu.EMPTY_INSTRUCTION {
+		i.Text = dataByte(opcode)
+		i.Illegal = true
+		return i
+	}
+
+	var operands [2]byte
+	for n := 0; n < instruction.OperandsSize; n++ {
+		b, ok := read(addr + types.Word(n+1))
+		if !ok {
+			i.Bytes = i.Bytes[:1]
+			i.Text = dataByte(opcode)
+			return i
+		}
+		operands[n] = b
+		i.Bytes = append(i.Bytes, b)
+	}
+
+	i.Text = d.operands(&i, mnemonic(instruction.Description), operands)
+	return i
+}
+
+//Replaces the operand placeholders in text (d8, d16, a8, a16 and r8) with their values
+func (d *Disassembler) operands(i *Instruction, text string, operands [2]byte) string {
+	var next types.Word = i.Address + types.Word(len(i.Bytes))
+	var word types.Word = types.Word(operands[1])<<8 | types.Word(operands[0])
+	var branch bool = isBranch(text)
+
+	switch {
+	case strings.Contains(text, "d16"):
+		return strings.Replace(text, "d16", d.address(word), 1)
+	case strings.Contains(text, "a16"):
+		if branch {
+			i.Target, i.Branch = word, true
+		}
+		return strings.Replace(text, "a16", d.address(word), 1)
+	case strings.Contains(text, "d8"):
+		return strings.Replace(text, "d8", fmt.Sprintf("$%02X", operands[0]), 1)
+	case strings.Contains(text, "a8"):
+		return strings.Replace(text, "a8", d.address(0xFF00+types.Word(operands[0])), 1)
+	case strings.Contains(text, "SP+r8"):
+		return strings.Replace(text, "SP+r8", "SP"+signed(operands[0], true), 1)
+	case strings.Contains(text, "r8") && branch:
+		i.Target, i.Branch = next+types.Word(int8(operands[0])), true
+		return strings.Replace(text, "r8", d.address(i.Target), 1)
+	case strings.Contains(text, "r8"):
+		return strings.Replace(text, "r8", signed(operands[0], false), 1)
+	case strings.HasPrefix(text, "STOP"):
+		//STOP is followed by a byte that is ignored
+		return "STOP"
+	case strings.HasPrefix(text, "RST"):
+		i.Target, i.Branch = types.Word(i.Bytes[0]&0x38), true
+		return "RST " + d.address(i.Target)
+	}
+	return text
+}
+
+//Renders addr as a label if there is one, otherwise as hex
+func (d *Disassembler) address(addr types.Word) string {
+	if name, ok := d.Symbols.Lookup(d.bankAt(addr), addr); ok {
+		return name
+	}
+	return fmt.Sprintf("$%04X", uint16(addr))
+}
+
+func (d *Disassembler) bankAt(addr types.Word) int {
+	if d.Bank == nil {
+		return 0
+	}
+	return d.Bank(addr)
+}
+
+//The table descriptions are padded with extra spaces, e.g. "LD  A,(a16)"
+func mnemonic(description string) string {
+	return strings.Join(strings.Fields(description), " ")
+}
+
+func isBranch(text string) bool {
+	return strings.HasPrefix(text, "JP ") || strings.HasPrefix(text, "JR ") || strings.HasPrefix(text, "CALL ")
+}
+
+func signed(b byte, explicitPlus bool) string {
+	var offset int = int(int8(b))
+	switch {
+	case offset < 0:
+		return fmt.Sprintf("-$%02X", -offset)
+	case explicitPlus:
+		return fmt.Sprintf("+$%02X", offset)
+	}
+	return fmt.Sprintf("$%02X", offset)
+}
+
+func dataByte(b byte) string {
+	return fmt.Sprintf("DB $%02X", b)
+}
@@ -0,0 +1,79 @@
+package disasm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func texts(instructions []Instruction) []string {
+	var result []string
+	for _, i := range instructions {
+		result = append(result, i.Text)
+	}
+	return result
+}
+
+func TestBytesResolvesOperands(t *testing.T) {
+	d := NewDisassembler(nil, nil)
+	result := d.Bytes([]byte{
+		0x31, 0xFE, 0xFF, //LD SP,$FFFE
+		0x3E, 0x80, //LD A,$80
+		0xE0, 0x40, //LDH ($FF40),A
+		0xCB, 0x7C, //BIT 7,H
+		0x20, 0xFB, //JR NZ,$0106
+		0xF8, 0xFE, //LD HL,SP-$02
+		0xD3, //illegal
+		0xC3, //truncated JP
+	}, 0x0100)
+
+	assert.Equal(t, []string{
+		"LD SP,$FFFE",
+		"LD A,$80",
+		"LDH ($FF40),A",
+		"BIT 7,H",
+		"JR NZ,$0106",
+		"LD HL,SP-$02",
+		"DB $D3",
+		"DB $C3",
+	}, texts(result))
+
+	assert.True(t, result[4].Branch)
+	assert.Equal(t, types.Word(0x0106), result[4].Target)
+	assert.True(t, result[6].Illegal)
+	assert.Equal(t, "00:0109  20 FB     JR NZ,$0106", result[4].String())
+}
+
+func TestSymbolsAreSubstituted(t *testing.T) {
+	symbols, err := LoadSymbols(strings.NewReader(`; File generated by rgblink
+00:0150 Main
+02:4000 Bank2Func
+01:4000 Bank1Func
+00:ff40 rLCDC
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, symbols.Len())
+
+	var bank int = 2
+	d := NewDisassembler(symbols, func(addr types.Word) int {
+		if addr >= 0x4000 && addr < 0x8000 {
+			return bank
+		}
+		return 0
+	})
+
+	result := d.Bytes([]byte{0xCD, 0x00, 0x40, 0xE0, 0x40, 0xC3, 0x50, 0x01}, 0x0150)
+	assert.Equal(t, []string{"CALL Bank2Func", "LDH (rLCDC),A", "JP Main"}, texts(result))
+	assert.Equal(t, "Main", result[0].Label)
+
+	bank = 1
+	assert.Equal(t, "CALL Bank1Func", d.Bytes([]byte{0xCD, 0x00, 0x40}, 0x0150)[0].Text)
+}
+
+func TestLoadSymbolsRejectsMalformedLines(t *testing.T) {
+	_, err := LoadSymbols(strings.NewReader("00:0150 Main\n0150 Broken\n"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
@@ -0,0 +1,146 @@
+package disasm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+type symbolKey struct {
+	bank int
+	addr types.Word
+}
+
+//Labels for addresses, as written by RGBDS (rgblink -n) and no$gmb. Each line of a
+//symbol file is of the form "BB:AAAA Name" where BB is the bank and AAAA the address
+type SymbolTable struct {
+	labels    map[symbolKey]string
+	anyBank   map[types.Word]string
+	addresses map[string]symbolKey
+}
+
+func NewSymbolTable() *SymbolTable {
+	var s *SymbolTable = new(SymbolTable)
+	s.labels = make(map[symbolKey]string)
+	s.anyBank = make(map[types.Word]string)
+	s.addresses = make(map[string]symbolKey)
+	return s
+}
+
+//Loads a symbol file from disk
+func LoadSymbolFile(filename string) (*SymbolTable, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadSymbols(f)
+}
+
+//Parses symbols from r. Blank lines, ";" comments and no$gmb "[section]" headers
+//are skipped
+func LoadSymbols(r io.Reader) (*SymbolTable, error) {
+	var s *SymbolTable = NewSymbolTable()
+	scanner := bufio.NewScanner(r)
+
+	var lineNo int
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "[") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, errors.New(fmt.Sprintf("Symbol file line %d: expected \"BB:AAAA Name\" but got %q", lineNo, line))
+		}
+
+		bank, addr, err := parseLocation(fields[0])
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Symbol file line %d: %v", lineNo, err))
+		}
+		s.Add(bank, addr, fields[1])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func parseLocation(s string) (int, types.Word, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, 0, errors.New(fmt.Sprintf("Invalid symbol location %q", s))
+	}
+
+	bank, err := strconv.ParseUint(parts[0], 16, 16)
+	if err != nil {
+		return 0, 0, errors.New(fmt.Sprintf("Invalid bank in symbol location %q", s))
+	}
+
+	addr, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return 0, 0, errors.New(fmt.Sprintf("Invalid address in symbol location %q", s))
+	}
+
+	return int(bank), types.Word(addr), nil
+}
+
+//Adds a label for an address. When several labels share an address the first one
+//added is used for disassembly
+func (s *SymbolTable) Add(bank int, addr types.Word, name string) {
+	key := symbolKey{bank, addr}
+	if _, ok := s.labels[key]; !ok {
+		s.labels[key] = name
+	}
+	if _, ok := s.anyBank[addr]; !ok {
+		s.anyBank[addr] = name
+	}
+	s.addresses[name] = key
+}
+
+//Returns the label for an address in the given bank. Banked RAM is not tracked by
+//the disassembler, so labels for addresses outside of ROM are matched in any bank
+func (s *SymbolTable) Lookup(bank int, addr types.Word) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+
+	if name, ok := s.labels[symbolKey{bank, addr}]; ok {
+		return name, true
+	}
+
+	if addr >= 0x8000 {
+		name, ok := s.anyBank[addr]
+		return name, ok
+	}
+	return "", false
+}
+
+//Returns the bank and address of a label
+func (s *SymbolTable) Address(name string) (int, types.Word, bool) {
+	if s == nil {
+		return 0, 0, false
+	}
+	a, ok := s.addresses[name]
+	return a.bank, a.addr, ok
+}
+
+func (s *SymbolTable) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.labels)
+}
@@ -0,0 +1,143 @@
+package gbc
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/cartridge"
+	"github.com/djhworld/gomeboycolor/config"
+	"github.com/djhworld/gomeboycolor/gpu"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Overrides the directory holding the dmg-acid2 and cgb-acid2 test ROMs
+//(https://github.com/mattcurrie/dmg-acid2, https://github.com/mattcurrie/cgb-acid2) along
+//with their reference images, named "dmg-acid2.gb", "dmg-acid2.png", "cgb-acid2.gbc" and
+//"cgb-acid2.png"
+const ACID2_TESTS_ENV string = "ACID2_TESTS"
+
+//Where the acid2 ROMs and reference images are kept in the repo, see the README there
+const ACID2_TESTDATA string = "testdata/acid2"
+
+//the acid2 ROMs draw their image straight away then loop forever
+const ACID2_FRAMES int = 30
+
+type acid2Test struct {
+	rom       string
+	reference string
+	color     bool
+}
+
+var acid2Tests []acid2Test = []acid2Test{
+	{"dmg-acid2.gb", "dmg-acid2.png", false},
+	{"cgb-acid2.gbc", "cgb-acid2.png", true},
+}
+
+func TestAcid2(t *testing.T) {
+	dir := os.Getenv(ACID2_TESTS_ENV)
+	if dir == "" {
+		dir = ACID2_TESTDATA
+	}
+
+	for _, test := range acid2Tests {
+		for _, r := range []gpu.Renderer{gpu.SCANLINE_RENDERER, gpu.FIFO_RENDERER} {
+			test, r := test, r
+			t.Run(fmt.Sprintf("%s/%s", test.rom, r), func(t *testing.T) {
+				if _, err := os.Stat(filepath.Join(dir, test.rom)); os.IsNotExist(err) {
+					t.Skip(test.rom + " is not in " + dir)
+				}
+				reference, err := loadReferenceImage(filepath.Join(dir, test.reference))
+				if err != nil {
+					t.Fatal(err)
+				}
+				screen, err := runAcid2(filepath.Join(dir, test.rom), test.color, r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := compareScreen(screen, reference, test.color); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func runAcid2(romFile string, color bool, r gpu.Renderer) (*types.Screen, error) {
+	rom, err := ioutil.ReadFile(romFile)
+	if err != nil {
+		return nil, err
+	}
+	cart, err := cartridge.NewCartridge(filepath.Base(romFile), rom)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &config.Config{Title: TITLE, ScreenSize: 1, SkipBoot: true, ColorMode: color, PixelFIFO: r == gpu.FIFO_RENDERER}
+	gbc := newTestGomeboyColorFor(cart, conf)
+	for i := 0; i < ACID2_FRAMES; i++ {
+		gbc.doFrame()
+		gbc.cpuClockAcc = 0
+	}
+	return gbc.gpu.Screen(), nil
+}
+
+func loadReferenceImage(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+//The DMG reference uses different greys so shades are compared, CGB colours are compared
+//at the 5 bits per channel the hardware has
+func compareScreen(screen *types.Screen, reference image.Image, color bool) error {
+	bounds := reference.Bounds()
+	if bounds.Dx() != gpu.DISPLAY_WIDTH || bounds.Dy() != gpu.DISPLAY_HEIGHT {
+		return errors.New(fmt.Sprintf("reference image is %dx%d", bounds.Dx(), bounds.Dy()))
+	}
+
+	var mismatches int
+	var first string
+	for y := 0; y < gpu.DISPLAY_HEIGHT; y++ {
+		for x := 0; x < gpu.DISPLAY_WIDTH; x++ {
+			r, g, b, _ := reference.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			expected := types.RGB{Red: byte(r >> 8), Green: byte(g >> 8), Blue: byte(b >> 8)}
+			got := screen[y][x]
+
+			var same bool
+			if color {
+				same = got.Red>>3 == expected.Red>>3 && got.Green>>3 == expected.Green>>3 && got.Blue>>3 == expected.Blue>>3
+			} else {
+				same = dmgShade(got) == 3-(int(expected.Red)+42)/85
+			}
+			if !same {
+				if mismatches == 0 {
+					first = fmt.Sprintf("(%d,%d) is %v, expected %v", x, y, got, expected)
+				}
+				mismatches++
+			}
+		}
+	}
+
+	if mismatches > 0 {
+		return errors.New(fmt.Sprintf("%d pixels differ from the reference, first at %s", mismatches, first))
+	}
+	return nil
+}
+
+func dmgShade(c types.RGB) int {
+	for i, shade := range gpu.GreyPalette {
+		if c == shade {
+			return i
+		}
+	}
+	return -1
+}
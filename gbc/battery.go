@@ -0,0 +1,118 @@
+package gbc
+
+import (
+	"bytes"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/djhworld/gomeboycolor/cartridge"
+	"github.com/djhworld/gomeboycolor/saves"
+)
+
+//Writes battery backed cartridge RAM to a save store on its own goroutine,
+//so flushing a save never stalls the emulation loop
+type batterySaver struct {
+	store     saves.Store
+	game      string
+	title     string
+	interval  time.Duration
+	lastFlush time.Time
+
+	mutex   sync.Mutex
+	stopped bool
+	pending chan [][]byte
+	done    chan bool
+}
+
+func newBatterySaver(store saves.Store, game, title string, interval time.Duration) *batterySaver {
+	var b *batterySaver = new(batterySaver)
+	b.store = store
+	b.game = game
+	b.title = title
+	b.interval = interval
+	b.lastFlush = time.Now()
+	b.pending = make(chan [][]byte, 1)
+	b.done = make(chan bool)
+	go b.run()
+	return b
+}
+
+func (b *batterySaver) run() {
+	for banks := range b.pending {
+		if err := writeBatterySave(b.store, b.game, b.title, banks); err != nil {
+			log.Printf("Could not save cartridge RAM for: %s (%v)", b.game, err)
+		}
+	}
+	b.done <- true
+}
+
+//Flushes the cartridge RAM if it has changed and either the game has committed
+//the save (by disabling RAM) or the flush interval has elapsed
+func (b *batterySaver) Check(mbc cartridge.MemoryBankController) {
+	if !mbc.IsRamDirty() {
+		return
+	}
+
+	intervalElapsed := b.interval > 0 && time.Since(b.lastFlush) >= b.interval
+	if mbc.IsRamCommitted() || intervalElapsed {
+		if banks := mbc.SnapshotRam(); banks != nil {
+			b.Flush(banks)
+		}
+	}
+}
+
+//Queues banks to be written, replacing any write that has not started yet
+func (b *batterySaver) Flush(banks [][]byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.stopped {
+		return
+	}
+
+	select {
+	case <-b.pending:
+	default:
+	}
+	b.pending <- banks
+	b.lastFlush = time.Now()
+}
+
+//Waits for any queued write to complete and stops the saver
+func (b *batterySaver) Stop() {
+	b.mutex.Lock()
+	if b.stopped {
+		b.mutex.Unlock()
+		return
+	}
+	b.stopped = true
+	close(b.pending)
+	b.mutex.Unlock()
+
+	<-b.done
+}
+
+//Serializes the RAM banks up front so a failure part way through never reaches
+//the store. The save is only considered complete once the writer has been closed
+//without error
+func writeBatterySave(store saves.Store, game, title string, banks [][]byte) error {
+	var buf bytes.Buffer
+	s := cartridge.NewSave()
+	s.Title = title
+	if err := s.Save(&buf, banks); err != nil {
+		return err
+	}
+
+	w, err := store.Create(game)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
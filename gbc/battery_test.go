@@ -0,0 +1,98 @@
+package gbc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/cartridge"
+	"github.com/stretchrcom/testify/assert"
+)
+
+type recordingStore struct {
+	mutex sync.Mutex
+	saves [][]byte
+}
+
+func (s *recordingStore) Open(game string) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *recordingStore) Create(game string) (io.WriteCloser, error) {
+	return &recordingWriter{store: s}, nil
+}
+
+func (s *recordingStore) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.saves)
+}
+
+type recordingWriter struct {
+	store *recordingStore
+	buf   bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (w *recordingWriter) Close() error {
+	w.store.mutex.Lock()
+	defer w.store.mutex.Unlock()
+	w.store.saves = append(w.store.saves, w.buf.Bytes())
+	return nil
+}
+
+func newTestMBC() *cartridge.MBC3 {
+	return cartridge.NewMBC3(make([]byte, 0x8000), 0x8000, 0x8000, true)
+}
+
+func TestBatterySaverFlushesWhenRamDisabled(t *testing.T) {
+	store := new(recordingStore)
+	saver := newBatterySaver(store, "game", "TITLE", 0)
+	mbc := newTestMBC()
+
+	saver.Check(mbc)
+	mbc.Write(0xA000, 0x42)
+	saver.Check(mbc)
+	assert.True(t, mbc.IsRamDirty(), "RAM should not be flushed until it is committed")
+
+	//game disables RAM to commit the save
+	mbc.Write(0x0000, 0x00)
+	saver.Check(mbc)
+	saver.Stop()
+
+	assert.False(t, mbc.IsRamDirty())
+	assert.Equal(t, 1, store.count())
+
+	banks, err := cartridge.NewSave().Load(bytes.NewReader(store.saves[0]), 4)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(0x42), banks[0][0])
+}
+
+func TestBatterySaverFlushesOnInterval(t *testing.T) {
+	store := new(recordingStore)
+	saver := newBatterySaver(store, "game", "TITLE", 1)
+	mbc := newTestMBC()
+
+	mbc.Write(0xA000, 0x42)
+	saver.Check(mbc)
+	saver.Stop()
+
+	assert.Equal(t, 1, store.count())
+}
+
+func TestBatterySaverIgnoresFlushesAfterStop(t *testing.T) {
+	store := new(recordingStore)
+	saver := newBatterySaver(store, "game", "TITLE", 1)
+	saver.Stop()
+
+	mbc := newTestMBC()
+	mbc.Write(0xA000, 0x42)
+	saver.Check(mbc)
+
+	assert.Equal(t, 0, store.count())
+}
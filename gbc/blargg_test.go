@@ -0,0 +1,98 @@
+package gbc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/cartridge"
+	"github.com/djhworld/gomeboycolor/config"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Directory holding blargg's test ROMs (https://github.com/retrio/gb-test-roms), tests are
+//looked up by their path in it e.g. "mem_timing-2/mem_timing.gb".
+//The tests are skipped when this is not set
+const BLARGG_TESTS_ENV string = "BLARGG_TESTS"
+
+//blargg tests that report through cartridge RAM are given this long to finish
+const BLARGG_FRAMES int = 60 * 60
+
+//While a test runs 0xA000 holds 0x80, once it is done it holds the result code (0 is a pass).
+//0xA001-0xA003 hold a signature so junk in RAM isn't mistaken for a result, the text the
+//test printed starts at 0xA004
+const BLARGG_RESULT_ADDR types.Word = 0xA000
+const BLARGG_RUNNING byte = 0x80
+
+var blarggSignature [3]byte = [3]byte{0xDE, 0xB0, 0x61}
+
+var blarggTests []string = []string{
+	"mem_timing-2/mem_timing.gb",
+}
+
+func TestBlargg(t *testing.T) {
+	dir := os.Getenv(BLARGG_TESTS_ENV)
+	if dir == "" {
+		t.Skip("Set " + BLARGG_TESTS_ENV + " to the directory of blargg's test ROMs to run them")
+	}
+
+	for _, test := range blarggTests {
+		test := test
+		t.Run(test, func(t *testing.T) {
+			if err := runBlargg(filepath.Join(dir, test)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func runBlargg(romFile string) error {
+	rom, err := ioutil.ReadFile(romFile)
+	if err != nil {
+		return err
+	}
+	cart, err := cartridge.NewCartridge(filepath.Base(romFile), rom)
+	if err != nil {
+		return err
+	}
+
+	conf := &config.Config{Title: TITLE, ScreenSize: 1, SkipBoot: true}
+	gbc := newTestGomeboyColorFor(cart, conf)
+	for i := 0; i < BLARGG_FRAMES; i++ {
+		gbc.doFrame()
+		gbc.cpuClockAcc = 0
+
+		if result, done := blarggResult(gbc); done {
+			if result != 0 {
+				return errors.New(fmt.Sprintf("test failed with code %d: %s", result, blarggText(gbc)))
+			}
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("test did not finish within %d frames", BLARGG_FRAMES))
+}
+
+func blarggResult(gbc *GomeboyColor) (byte, bool) {
+	for i, b := range blarggSignature {
+		if gbc.mmu.Peek(BLARGG_RESULT_ADDR+types.Word(i+1)) != b {
+			return 0, false
+		}
+	}
+	result := gbc.mmu.Peek(BLARGG_RESULT_ADDR)
+	return result, result != BLARGG_RUNNING
+}
+
+func blarggText(gbc *GomeboyColor) string {
+	var text []byte
+	for addr := BLARGG_RESULT_ADDR + 4; addr < 0xC000; addr++ {
+		b := gbc.mmu.Peek(addr)
+		if b == 0 {
+			break
+		}
+		text = append(text, b)
+	}
+	return string(text)
+}
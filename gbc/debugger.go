@@ -25,12 +25,13 @@ import (
 type DebugCommandHandler func(*GomeboyColor, ...string)
 
 type DebugOptions struct {
-	debuggerOn   bool
-	breakWhen    types.Word
-	watches      map[types.Word]byte
-	debugFuncMap map[string]DebugCommandHandler
-	debugHelpStr []string
-	stepDump     bool
+	debuggerOn    bool
+	breakWhen     types.Word
+	breakOnLockup bool
+	watches       map[types.Word]byte
+	debugFuncMap  map[string]DebugCommandHandler
+	debugHelpStr  []string
+	stepDump      bool
 }
 
 func (g *DebugOptions) help() {
@@ -45,8 +46,10 @@ func (g *DebugOptions) Init(cpuDumpOnStep bool) {
 	g.debugFuncMap = make(map[string]DebugCommandHandler)
 	g.watches = make(map[types.Word]byte)
 	g.stepDump = cpuDumpOnStep
+	g.breakOnLockup = true
 	g.AddDebugFunc("p", "Print CPU state", func(gbc *GomeboyColor, remaining ...string) {
 		fmt.Println(gbc.cpu)
+		fmt.Println("Next:", gbc.disassemble(gbc.cpu.PC))
 	})
 
 	g.AddDebugFunc("r", "Reset", func(gbc *GomeboyColor, remaining ...string) {
@@ -119,7 +122,7 @@ func (g *DebugOptions) Init(cpuDumpOnStep bool) {
 		for i := 0; i < noOfSteps; i++ {
 			gbc.Step()
 			if g.stepDump {
-				fmt.Println(i, ":", gbc.cpu)
+				fmt.Println(i, ":", gbc.traceLine())
 			}
 			g.checkWatches(gbc)
 		}
@@ -135,7 +138,7 @@ func (g *DebugOptions) Init(cpuDumpOnStep bool) {
 
 		var arg string = remaining[0]
 
-		if bp, err := ToMemoryAddress(arg); err != nil {
+		if bp, err := gbc.resolveAddress(arg); err != nil {
 			fmt.Println("Could not parse memory address argument:", arg)
 			fmt.Println("\t", err)
 		} else {
@@ -144,6 +147,55 @@ func (g *DebugOptions) Init(cpuDumpOnStep bool) {
 		}
 	})
 
+	g.AddDebugFunc("bl", "Toggle breaking when the CPU locks up", func(gbc *GomeboyColor, remaining ...string) {
+		g.breakOnLockup = !g.breakOnLockup
+		fmt.Println("Break on CPU lockup:", g.breakOnLockup)
+	})
+
+	g.AddDebugFunc("u", "Disassemble [address or label] [count]", func(gbc *GomeboyColor, remaining ...string) {
+		var addr types.Word = gbc.cpu.PC
+		var count int = 10
+		if len(remaining) > 0 {
+			a, err := gbc.resolveAddress(remaining[0])
+			if err != nil {
+				fmt.Println("Could not parse memory address: ", remaining[0])
+				return
+			}
+			addr = a
+		}
+		if len(remaining) > 1 {
+			val, err := strconv.ParseInt(remaining[1], 10, 64)
+			if err != nil || val <= 0 {
+				fmt.Println("Could not parse instruction count: ", remaining[1])
+				return
+			}
+			count = int(val)
+		}
+
+		for _, i := range gbc.disasm.Range(gbc.mmu.Peek, addr, count) {
+			if i.Label != "" {
+				fmt.Println(i.Label + ":")
+			}
+			if i.Address == gbc.cpu.PC {
+				fmt.Println("=>", i)
+			} else {
+				fmt.Println("  ", i)
+			}
+		}
+	})
+
+	g.AddDebugFunc("sym", "Load labels from a symbol file", func(gbc *GomeboyColor, remaining ...string) {
+		if len(remaining) == 0 {
+			fmt.Println("You must provide a symbol file to load!")
+			return
+		}
+
+		if err := gbc.LoadSymbols(remaining[0]); err != nil {
+			fmt.Println("Could not load symbol file:", remaining[0])
+			fmt.Println("\t", err)
+		}
+	})
+
 	g.AddDebugFunc("reg", "Set register", func(gbc *GomeboyColor, remaining ...string) {
 		if len(remaining) < 2 {
 			fmt.Println("You must provide a register and value!")
@@ -205,7 +257,7 @@ func (g *DebugOptions) Init(cpuDumpOnStep bool) {
 
 		fmt.Printf("%s\t\t", lb)
 		for w := lb; w <= hb; w++ {
-			fmt.Print(utils.ByteToString(gbc.mmu.ReadByte(w)), " ")
+			fmt.Print(utils.ByteToString(gbc.mmu.Peek(w)), " ")
 		}
 		fmt.Println()
 
@@ -237,7 +289,7 @@ func (g *DebugOptions) Init(cpuDumpOnStep bool) {
 		}
 
 		fmt.Println("Writing", utils.ByteToString(value), "to", toAddr)
-		gbc.mmu.WriteByte(toAddr, value)
+		gbc.mmu.Poke(toAddr, value)
 	})
 
 	g.AddDebugFunc("w", "Set memory location to watch for changes", func(gbc *GomeboyColor, remaining ...string) {
@@ -253,7 +305,7 @@ func (g *DebugOptions) Init(cpuDumpOnStep bool) {
 			fmt.Println("\t", err)
 		} else {
 			fmt.Println("Watching memory address:", m)
-			value := gbc.mmu.ReadByte(m)
+			value := gbc.mmu.Peek(m)
 			g.watches[m] = value
 		}
 	})
@@ -270,7 +322,7 @@ func (g *DebugOptions) AddDebugFunc(command string, description string, f DebugC
 
 func (g *DebugOptions) checkWatches(gbc *GomeboyColor) {
 	for k, oldVal := range g.watches {
-		currentValue := gbc.mmu.ReadByte(k)
+		currentValue := gbc.mmu.Peek(k)
 		if oldVal != currentValue {
 			fmt.Println("Data at memory address", k, "has changed from", utils.ByteToString(oldVal), "to", utils.ByteToString(currentValue))
 			fmt.Println("Last operation:", gbc.cpu)
@@ -0,0 +1,51 @@
+package gbc
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/djhworld/gomeboycolor/disasm"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Loads labels from an RGBDS or no$gmb symbol file for the debugger and trace output
+func (gbc *GomeboyColor) LoadSymbols(filename string) error {
+	symbols, err := disasm.LoadSymbolFile(filename)
+	if err != nil {
+		return err
+	}
+	log.Printf("Loaded %d symbols from %s", symbols.Len(), filename)
+	gbc.disasm.Symbols = symbols
+	return nil
+}
+
+//Disassembles the instruction at addr as it is currently mapped
+func (gbc *GomeboyColor) disassemble(addr types.Word) disasm.Instruction {
+	return gbc.disasm.Decode(gbc.mmu.Peek, addr)
+}
+
+//Looks up a label from the loaded symbols, falling back to parsing s as a hex address
+func (gbc *GomeboyColor) resolveAddress(s string) (types.Word, error) {
+	if _, addr, ok := gbc.disasm.Symbols.Address(s); ok {
+		return addr, nil
+	}
+	return ToMemoryAddress(s)
+}
+
+//The ROM bank mapped at addr, addresses outside of switchable ROM are in bank 0
+func (gbc *GomeboyColor) romBankAt(addr types.Word) int {
+	if addr >= 0x4000 && addr < 0x8000 {
+		return gbc.cart.MBC.CurrentROMBank()
+	}
+	return 0
+}
+
+//The instruction about to be executed followed by the CPU state
+func (gbc *GomeboyColor) traceLine() string {
+	var i disasm.Instruction = gbc.disassemble(gbc.cpu.PC)
+	var line string = fmt.Sprintf("%-36s SP: %s  %s  %s", i, gbc.cpu.SP, gbc.cpu.R, gbc.cpu.FlagsString())
+	if i.Label != "" {
+		return i.Label + ":\n\t " + line
+	}
+	return line
+}
@@ -0,0 +1,58 @@
+package gbc
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//A loop mixing loads, arithmetic, CB prefixed instructions, calls and the stack
+var benchmarkProgram []byte = []byte{
+	0x21, 0x00, 0xC0, //LD HL, 0xC000
+	0x06, 0x40, //LD B, 0x40
+	0x7E,       //LD A, (HL)
+	0x80,       //ADD A, B
+	0x07,       //RLCA
+	0xCB, 0x37, //SWAP A
+	0x22,       //LD (HL+), A
+	0xCB, 0x5F, //BIT 3, A
+	0xCD, 0x15, 0xC2, //CALL 0xC215
+	0x05,       //DEC B
+	0x20, 0xF2, //JR NZ, 0xC205
+	0x18, 0xEB, //JR 0xC200
+	0xC5, //PUSH BC
+	0xA9, //XOR C
+	0xC1, //POP BC
+	0xC9, //RET
+}
+
+func newBenchmarkGomeboyColor(b *testing.B) *GomeboyColor {
+	gbc := newTestGomeboyColor(b, "BENCHMARK")
+	for i, op := range benchmarkProgram {
+		gbc.mmu.WriteByte(0xC200+types.Word(i), op)
+	}
+	gbc.cpu.PC = 0xC200
+	gbc.cpu.InterruptsEnabled = false
+	return gbc
+}
+
+//Time to run one frame of benchmarkProgram
+func BenchmarkFrame(b *testing.B) {
+	gbc := newBenchmarkGomeboyColor(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gbc.doFrame()
+		gbc.cpuClockAcc = 0
+	}
+}
+
+//Time to run one instruction of benchmarkProgram
+func BenchmarkStep(b *testing.B) {
+	gbc := newBenchmarkGomeboyColor(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gbc.Step()
+	}
+}
@@ -11,35 +11,47 @@ import (
 	"github.com/djhworld/gomeboycolor/cartridge"
 	"github.com/djhworld/gomeboycolor/config"
 	"github.com/djhworld/gomeboycolor/cpu"
+	"github.com/djhworld/gomeboycolor/disasm"
 	"github.com/djhworld/gomeboycolor/gpu"
 	"github.com/djhworld/gomeboycolor/inputoutput"
 	"github.com/djhworld/gomeboycolor/mmu"
 	"github.com/djhworld/gomeboycolor/saves"
 	"github.com/djhworld/gomeboycolor/timer"
+	"github.com/djhworld/gomeboycolor/trace"
 	"github.com/djhworld/gomeboycolor/types"
 	"github.com/djhworld/gomeboycolor/utils"
 )
 
+//the number of dots (4MHz clock cycles) the LCD takes to draw a frame
 const FRAME_CYCLES = 70224
 const TITLE string = "gomeboycolor"
 
 var VERSION string
 
 type GomeboyColor struct {
-	gpu          *gpu.GPU
-	cpu          *cpu.GbcCPU
-	mmu          *mmu.GbcMMU
-	io           inputoutput.IOHandler
-	apu          *apu.APU
-	timer        *timer.Timer
-	debugOptions *DebugOptions
-	config       *config.Config
-	cart         *cartridge.Cartridge
-	saveStore    saves.Store
-	cpuClockAcc  int
-	stepCount    int
-	inBootMode   bool
-	stopped      bool
+	gpu           *gpu.GPU
+	cpu           *cpu.GbcCPU
+	mmu           *mmu.GbcMMU
+	io            inputoutput.IOHandler
+	apu           *apu.APU
+	timer         *timer.Timer
+	debugOptions  *DebugOptions
+	disasm        *disasm.Disassembler
+	config        *config.Config
+	cart          *cartridge.Cartridge
+	saveStore     saves.Store
+	batterySaver  *batterySaver
+	queued        chan func()
+	rewind        *rewindBuffer
+	rewinding     int32
+	movie         movieDriver
+	lockupHandler func(err *CPULockupError)
+	tracer        *trace.Writer
+	frameCount    int
+	cpuClockAcc   int
+	stepCount     int
+	inBootMode    bool
+	stopped       bool
 }
 
 func Init(cart *cartridge.Cartridge, saveStore saves.Store, conf *config.Config, ioHandler inputoutput.IOHandler) (*GomeboyColor, error) {
@@ -56,6 +68,13 @@ func Init(cart *cartridge.Cartridge, saveStore saves.Store, conf *config.Config,
 
 	gbc.mmu.LoadCartridge(gbc.cart)
 
+	if gbc.config.SymbolFile != "" {
+		if err := gbc.LoadSymbols(gbc.config.SymbolFile); err != nil {
+			log.Println("Error loading symbol file:", err)
+			return nil, err
+		}
+	}
+
 	gbc.debugOptions.Init(gbc.config.DumpState)
 	if gbc.config.Debug {
 		log.Println("Emulator will start in debug mode")
@@ -74,15 +93,21 @@ func Init(cart *cartridge.Cartridge, saveStore saves.Store, conf *config.Config,
 	r, err := gbc.saveStore.Open(cart.ID)
 	if err == nil {
 		gbc.mmu.LoadCartridgeRam(r)
+		r.Close()
 	} else {
 		log.Printf("Could not load a save state for: %s (%v)", cart.ID, err)
 	}
-	defer r.Close()
+	gbc.batterySaver = newBatterySaver(gbc.saveStore, cart.ID, cart.Title, gbc.config.BatterySaveInterval)
 
 	gbc.gpu.LinkScreen(gbc.io.GetScreenOutputChannel())
 
 	gbc.setupBoot()
 
+	if gbc.config.RewindBudget > 0 {
+		log.Printf("Keeping up to %d bytes of rewind history", gbc.config.RewindBudget)
+		gbc.rewind = newRewindBuffer(gbc.config.RewindBudget, REWIND_KEYFRAME_INTERVAL)
+	}
+
 	err = gbc.io.Init(gbc.config.Title, gbc.config.ScreenSize, gbc.onClose)
 	if err != nil {
 		log.Fatalln("io init failure\n\t", err)
@@ -95,14 +120,55 @@ func Init(cart *cartridge.Cartridge, saveStore saves.Store, conf *config.Config,
 }
 
 func (gbc *GomeboyColor) Run() {
-
 	for !gbc.stopped {
-		if !gbc.debugOptions.debuggerOn {
-			gbc.doFrame()
-		} else {
-			gbc.doFrameWithDebug()
+		gbc.runFrame()
+	}
+}
+
+func (gbc *GomeboyColor) runFrame() {
+	if gbc.rewind != nil && gbc.isRewinding() {
+		gbc.rewindFrame()
+		gbc.runQueued()
+		return
+	}
+
+	if gbc.movie != nil {
+		gbc.movie.beforeFrame(gbc)
+	}
+
+	if !gbc.debugOptions.debuggerOn {
+		gbc.doFrame()
+	} else {
+		gbc.doFrameWithDebug()
+	}
+	gbc.cpuClockAcc = 0
+	gbc.frameCount++
+
+	if gbc.movie != nil {
+		gbc.movie.afterFrame(gbc)
+	}
+
+	if gbc.rewind != nil {
+		gbc.rewind.Push(gbc)
+	}
+	gbc.batterySaver.Check(gbc.cart.MBC)
+	gbc.runQueued()
+}
+
+//Queues f to be run on the emulation goroutine once the current frame has finished,
+//this is how other goroutines should save or load states while the emulator is running
+func (gbc *GomeboyColor) BetweenFrames(f func()) {
+	gbc.queued <- f
+}
+
+func (gbc *GomeboyColor) runQueued() {
+	for {
+		select {
+		case f := <-gbc.queued:
+			f()
+		default:
+			return
 		}
-		gbc.cpuClockAcc = 0
 	}
 }
 
@@ -110,27 +176,53 @@ func (gbc *GomeboyColor) RunIO() {
 	gbc.io.Run()
 }
 
+//Executes a single instruction, the rest of the system is stepped by tick as the
+//instruction runs
 func (gbc *GomeboyColor) Step() {
-	cycles := gbc.cpu.Step()
-	//GPU is unaffected by CPU speed changes
-	gbc.gpu.Step(cycles)
-	gbc.cpuClockAcc += cycles
+	var lockedUp bool = gbc.cpu.LockedUp
+	gbc.cpu.Step()
+	gbc.stepCount++
 
-	//these are affected by CPU speed changes
-	gbc.timer.Step(cycles / gbc.cpu.Speed)
+	//DMA holds the CPU up while the rest of the system carries on
+	for stall := gbc.mmu.TakeDMAStall(); stall > 0; stall = gbc.mmu.TakeDMAStall() {
+		gbc.tick(stall)
+	}
 
-	gbc.stepCount++
+	if gbc.cpu.LockedUp && !lockedUp {
+		err := gbc.reportLockup()
+		if gbc.debugOptions.debuggerOn && gbc.debugOptions.breakOnLockup {
+			gbc.pause(err.Error())
+		}
+	}
 
 	gbc.checkBootModeStatus()
 }
 
+//Advances the rest of the system by the given number of CPU M-cycles, the CPU calls this
+//as each memory access happens
+func (gbc *GomeboyColor) tick(mcycles int) {
+	//the LCD runs off its own clock so is unaffected by CPU speed changes
+	var dots int = mcycles * 4 / gbc.cpu.Speed
+	gbc.gpu.Step(dots)
+	gbc.cpuClockAcc += dots
+	gbc.mmu.StepDMA(mcycles)
+
+	//the timer is clocked by the CPU, so stops along with it
+	if !gbc.cpu.Stopped {
+		gbc.timer.Step(mcycles)
+	}
+}
+
 func (gbc *GomeboyColor) Reset() {
 	log.Println("Resetting system")
 	gbc.cpu.Reset()
 	gbc.gpu.Reset()
 	gbc.mmu.Reset()
+	gbc.cart.MBC.Reset()
 	gbc.apu.Reset()
+	gbc.timer.Reset()
 	gbc.io.GetKeyHandler().Reset()
+	gbc.cpuClockAcc = 0
 	gbc.setupBoot()
 }
 
@@ -142,18 +234,27 @@ func newGomeboyColor(cart *cartridge.Cartridge, conf *config.Config, saveStore s
 	gbc.saveStore = saveStore
 	gbc.io = ioHandler
 	gbc.debugOptions = new(DebugOptions)
+	gbc.disasm = disasm.NewDisassembler(nil, gbc.romBankAt)
 	gbc.mmu = mmu.NewGbcMMU()
+	gbc.mmu.SetAccessLocking(!conf.UnlockedVRAM)
 	gbc.cpu = cpu.NewCPU(gbc.mmu)
 	gbc.stopped = false
+	gbc.queued = make(chan func(), 16)
 
 	gbc.gpu = gpu.NewGPU()
+	if conf.PixelFIFO {
+		gbc.gpu.SetRenderer(gpu.FIFO_RENDERER)
+	}
+	gbc.setDMGColours()
 	gbc.apu = apu.NewAPU()
 	gbc.timer = timer.NewTimer()
 
 	//mmu will process interrupt requests from GPU (i.e. it will set appropriate flags)
 	gbc.gpu.LinkIRQHandler(gbc.mmu)
+	gbc.gpu.LinkModeChangeHandler(gbc.mmu.LCDModeChanged)
 	gbc.timer.LinkIRQHandler(gbc.mmu)
 	gbc.io.GetKeyHandler().LinkIRQHandler(gbc.mmu)
+	gbc.cpu.LinkTicker(gbc.tick)
 
 	gbc.mmu.ConnectPeripheral(gbc.apu, 0xFF10, 0xFF3F)
 	gbc.mmu.ConnectPeripheral(gbc.gpu, 0x8000, 0x9FFF)
@@ -175,11 +276,11 @@ func (gbc *GomeboyColor) doFrame() {
 func (gbc *GomeboyColor) doFrameWithDebug() {
 	for gbc.cpuClockAcc < FRAME_CYCLES {
 		if gbc.cpu.PC == gbc.debugOptions.breakWhen {
-			gbc.pause()
+			gbc.pause(fmt.Sprint("PC == ", gbc.debugOptions.breakWhen))
 		}
 
 		if gbc.config.DumpState && !gbc.cpu.Halted {
-			fmt.Println("\t ", gbc.cpu)
+			fmt.Println("\t ", gbc.traceLine())
 		}
 		gbc.Step()
 	}
@@ -226,6 +327,7 @@ func (gbc *GomeboyColor) setHardwareMode(isColor bool) {
 		gbc.gpu.RunningColorGBHardware = false
 		gbc.mmu.RunningColorGBHardware = false
 	}
+	gbc.setDMGColours()
 }
 
 func (gbc *GomeboyColor) setupWithoutBoot() {
@@ -276,15 +378,28 @@ func (gbc *GomeboyColor) setupWithoutBoot() {
 }
 
 func (gbc *GomeboyColor) onClose() {
-	//TODO need to figure this bit out (handle errors?)
-	w, _ := gbc.saveStore.Create(gbc.cart.ID)
-	defer w.Close()
-	gbc.mmu.SaveCartridgeRam(w)
+	//let any background save finish first so it cannot overwrite the final one
+	gbc.batterySaver.Stop()
+	if err := gbc.SaveRAM(); err != nil {
+		log.Printf("Could not save cartridge RAM for: %s (%v)", gbc.cart.ID, err)
+	}
+	if err := gbc.StopTrace(); err != nil {
+		log.Println("Could not write trace:", err)
+	}
 	gbc.stopped = true
 }
 
-func (gbc *GomeboyColor) pause() {
-	log.Println("DEBUGGER: Breaking because PC ==", gbc.debugOptions.breakWhen)
+//Writes the battery backed cartridge RAM to the save store
+func (gbc *GomeboyColor) SaveRAM() error {
+	banks := gbc.cart.MBC.SnapshotRam()
+	if banks == nil {
+		return nil
+	}
+	return writeBatterySave(gbc.saveStore, gbc.cart.ID, gbc.cart.Title, banks)
+}
+
+func (gbc *GomeboyColor) pause(reason string) {
+	log.Println("DEBUGGER: Breaking because", reason)
 	b := bufio.NewWriter(os.Stdout)
 	r := bufio.NewReader(os.Stdin)
 
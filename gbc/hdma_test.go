@@ -0,0 +1,61 @@
+package gbc
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func setupHDMA(gbc *GomeboyColor, blocks int) {
+	for i := 0; i < blocks*16; i++ {
+		gbc.mmu.WriteByte(0xC000+types.Word(i), byte(i+1))
+	}
+	gbc.mmu.WriteByte(0xFF51, 0xC0)
+	gbc.mmu.WriteByte(0xFF52, 0x00)
+	gbc.mmu.WriteByte(0xFF53, 0x80)
+	gbc.mmu.WriteByte(0xFF54, 0x00)
+}
+
+func TestGeneralPurposeHDMA(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "HDMA")
+	gbc.mmu.WriteByte(0xFF40, 0x00)
+	setupHDMA(gbc, 2)
+
+	gbc.mmu.WriteByte(0xFF55, 0x01)
+	assert.Equal(t, byte(0x01), gbc.gpu.Read(0x8000))
+	assert.Equal(t, byte(0x20), gbc.gpu.Read(0x801F))
+	assert.Equal(t, byte(0xFF), gbc.mmu.ReadByte(0xFF55))
+	assert.Equal(t, 16, gbc.mmu.TakeDMAStall())
+	assert.Equal(t, 0, gbc.mmu.TakeDMAStall())
+}
+
+func TestHBlankHDMA(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "HDMA")
+	gbc.mmu.WriteByte(0xFF40, 0x00)
+	gbc.tick(1)
+	gbc.mmu.WriteByte(0xFF40, 0x91)
+	setupHDMA(gbc, 3)
+
+	gbc.mmu.WriteByte(0xFF55, 0x82)
+	assert.Equal(t, byte(0x02), gbc.mmu.ReadByte(0xFF55))
+	assert.Equal(t, byte(0x00), gbc.gpu.Read(0x8000))
+
+	//one block is copied at the start of each HBlank
+	for gbc.mmu.ReadByte(0xFF55) == 0x02 {
+		gbc.tick(1)
+	}
+	assert.Equal(t, byte(0x01), gbc.mmu.ReadByte(0xFF55))
+	assert.Equal(t, byte(0x10), gbc.gpu.Read(0x800F))
+	assert.Equal(t, byte(0x00), gbc.gpu.Read(0x8010))
+	assert.Equal(t, 8, gbc.mmu.TakeDMAStall())
+
+	//clearing bit 7 stops it with the remaining length left readable
+	gbc.mmu.WriteByte(0xFF55, 0x00)
+	assert.Equal(t, byte(0x81), gbc.mmu.ReadByte(0xFF55))
+	for i := 0; i < 456; i++ {
+		gbc.tick(1)
+	}
+	assert.Equal(t, byte(0x00), gbc.gpu.Read(0x8010))
+	assert.Equal(t, 0, gbc.mmu.TakeDMAStall())
+}
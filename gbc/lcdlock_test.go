@@ -0,0 +1,58 @@
+package gbc
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func tickUntilMode(gbc *GomeboyColor, mode byte) {
+	for gbc.gpu.Read(0xFF41)&0x03 != mode {
+		gbc.tick(1)
+	}
+}
+
+func TestVRAMAndOAMLockedByLCDMode(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "LCDLOCK")
+	gbc.mmu.WriteByte(0xFF40, 0x00)
+	gbc.mmu.WriteByte(0x8000, 0x11)
+	gbc.mmu.WriteByte(0xFE00, 0x22)
+	gbc.tick(1)
+	gbc.mmu.WriteByte(0xFF40, 0x91)
+
+	//OAM belongs to the PPU while it is scanning
+	tickUntilMode(gbc, 2)
+	assert.Equal(t, byte(0xFF), gbc.mmu.ReadByte(0xFE00))
+	assert.Equal(t, byte(0x11), gbc.mmu.ReadByte(0x8000))
+
+	//and both while it is drawing
+	tickUntilMode(gbc, 3)
+	assert.Equal(t, byte(0xFF), gbc.mmu.ReadByte(0xFE00))
+	assert.Equal(t, byte(0xFF), gbc.mmu.ReadByte(0x8000))
+	gbc.mmu.WriteByte(0x8000, 0x33)
+	gbc.mmu.WriteByte(0xFE00, 0x44)
+
+	tickUntilMode(gbc, 0)
+	assert.Equal(t, byte(0x11), gbc.mmu.ReadByte(0x8000))
+	assert.Equal(t, byte(0x22), gbc.mmu.ReadByte(0xFE00))
+
+	gbc.mmu.SetAccessLocking(false)
+	tickUntilMode(gbc, 3)
+	gbc.mmu.WriteByte(0x8000, 0x33)
+	assert.Equal(t, byte(0x33), gbc.mmu.ReadByte(0x8000))
+}
+
+func TestToolsSeeThroughLCDLock(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "LCDLOCK")
+	gbc.mmu.WriteByte(0xFF40, 0x00)
+	gbc.mmu.WriteByte(0x8000, 0x11)
+	gbc.tick(1)
+	gbc.mmu.WriteByte(0xFF40, 0x91)
+
+	//a debugger can look at and edit VRAM while the PPU is drawing
+	tickUntilMode(gbc, 3)
+	assert.Equal(t, byte(0x11), gbc.mmu.Peek(0x8000))
+	gbc.mmu.Poke(0x8000, 0x33)
+	assert.Equal(t, byte(0x33), gbc.mmu.Peek(0x8000))
+	assert.Equal(t, byte(0xFF), gbc.mmu.ReadByte(0x8000))
+}
@@ -0,0 +1,54 @@
+package gbc
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Reported when the CPU locks up after executing an illegal opcode. The emulator keeps
+//running with the CPU doing nothing until it is reset, as hardware does
+type CPULockupError struct {
+	PC     types.Word
+	Bank   int //ROM bank mapped at PC, -1 if the PC is outside of ROM
+	Opcode byte
+}
+
+func (e *CPULockupError) Error() string {
+	if e.Bank < 0 {
+		return fmt.Sprintf("CPU locked up executing illegal opcode 0x%02X at %s", e.Opcode, e.PC)
+	}
+	return fmt.Sprintf("CPU locked up executing illegal opcode 0x%02X at %s (ROM bank %d)", e.Opcode, e.PC, e.Bank)
+}
+
+//Sets a function to be called when the CPU locks up. It is called on the emulation
+//goroutine so should hand off anything slow
+func (gbc *GomeboyColor) OnCPULockup(f func(err *CPULockupError)) {
+	gbc.lockupHandler = f
+}
+
+func (gbc *GomeboyColor) lockupError() *CPULockupError {
+	var err *CPULockupError = new(CPULockupError)
+	err.PC = gbc.cpu.PC
+	err.Opcode = gbc.mmu.Peek(gbc.cpu.PC)
+
+	switch {
+	case err.PC < 0x4000:
+		err.Bank = 0
+	case err.PC < 0x8000:
+		err.Bank = gbc.cart.MBC.CurrentROMBank()
+	default:
+		err.Bank = -1
+	}
+	return err
+}
+
+func (gbc *GomeboyColor) reportLockup() *CPULockupError {
+	err := gbc.lockupError()
+	log.Println(err)
+	if gbc.lockupHandler != nil {
+		gbc.lockupHandler(err)
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+package gbc
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestIllegalOpcodeLocksUpCPU(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "LOCKUP")
+	var lockups []*CPULockupError
+	gbc.OnCPULockup(func(err *CPULockupError) {
+		lockups = append(lockups, err)
+	})
+
+	runProgram(gbc, 2,
+		0x00, //NOP
+		0xD3, //illegal
+	)
+
+	//interrupts should not get the CPU going again
+	gbc.cpu.InterruptsEnabled = true
+	gbc.mmu.WriteByte(0xFFFF, 0x1F)
+	gbc.mmu.WriteByte(0xFF0F, 0x1F)
+	for i := 0; i < 100; i++ {
+		gbc.Step()
+	}
+
+	assert.True(t, gbc.cpu.LockedUp)
+	assert.Equal(t, types.Word(0xC201), gbc.cpu.PC)
+	assert.Equal(t, 1, len(lockups))
+	assert.Equal(t, &CPULockupError{PC: 0xC201, Bank: -1, Opcode: 0xD3}, lockups[0])
+
+	gbc.Reset()
+	assert.False(t, gbc.cpu.LockedUp)
+}
@@ -0,0 +1,83 @@
+package gbc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/cartridge"
+	"github.com/djhworld/gomeboycolor/config"
+)
+
+//Directory holding the built mooneye-gb test suite (https://github.com/Gekkio/mooneye-gb),
+//tests are looked up by their path in it e.g. "acceptance/oam_dma_start.gb".
+//The tests are skipped when this is not set
+const MOONEYE_TESTS_ENV string = "MOONEYE_TESTS"
+
+//mooneye tests finish well within this and then loop forever
+const MOONEYE_FRAMES int = 120
+
+//A passing test loads the fibonacci sequence into B, C, D, E, H and L
+var mooneyePass [6]byte = [6]byte{3, 5, 8, 13, 21, 34}
+
+var mooneyeTests []string = []string{
+	"acceptance/ei_sequence.gb",
+	"acceptance/ei_timing.gb",
+	"acceptance/halt_ime0_ei.gb",
+	"acceptance/halt_ime0_nointr_timing.gb",
+	"acceptance/halt_ime1_timing.gb",
+	"acceptance/halt_ime1_timing2-GS.gb",
+	"acceptance/interrupts/ie_push.gb",
+	"acceptance/intr_timing.gb",
+	"acceptance/oam_dma/basic.gb",
+	"acceptance/oam_dma/reg_read.gb",
+	"acceptance/oam_dma/sources-GS.gb",
+	"acceptance/oam_dma_restart.gb",
+	"acceptance/oam_dma_start.gb",
+	"acceptance/oam_dma_timing.gb",
+	"acceptance/rapid_di_ei.gb",
+}
+
+func TestMooneye(t *testing.T) {
+	dir := os.Getenv(MOONEYE_TESTS_ENV)
+	if dir == "" {
+		t.Skip("Set " + MOONEYE_TESTS_ENV + " to the directory of the built mooneye-gb tests to run them")
+	}
+
+	for _, test := range mooneyeTests {
+		test := test
+		t.Run(test, func(t *testing.T) {
+			if err := runMooneye(filepath.Join(dir, test)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func runMooneye(romFile string) error {
+	rom, err := ioutil.ReadFile(romFile)
+	if err != nil {
+		return err
+	}
+	cart, err := cartridge.NewCartridge(filepath.Base(romFile), rom)
+	if err != nil {
+		return err
+	}
+
+	conf := &config.Config{Title: TITLE, ScreenSize: 1, SkipBoot: true}
+	gbc := newTestGomeboyColorFor(cart, conf)
+	for i := 0; i < MOONEYE_FRAMES; i++ {
+		gbc.doFrame()
+		gbc.cpuClockAcc = 0
+	}
+
+	r := gbc.cpu.R
+	var result [6]byte = [6]byte{r.B, r.C, r.D, r.E, r.H, r.L}
+	if result != mooneyePass {
+		return errors.New(fmt.Sprintf("test failed, registers B-L were %v", result))
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package gbc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/djhworld/gomeboycolor/state"
+)
+
+//identifies the start of a movie file
+const MOVIE_MAGIC string = "GBCMOVIE"
+
+//must be incremented whenever the layout of a movie file changes
+const MOVIE_VERSION int = 2
+
+//number of frames between the machine checksums stored in a movie
+const MOVIE_CHECKSUM_INTERVAL int = 60
+
+//largest embedded save state that will be read back from a movie
+const MAX_MOVIE_STATE_SIZE int = 16 << 20
+
+var NotAMovie error = errors.New("Not a movie")
+var UnsupportedMovieVersion error = errors.New("Movie version is unsupported")
+var MovieROMMismatch error = errors.New("Movie was recorded with a different ROM")
+var MovieAlreadyRunning error = errors.New("A movie is already being recorded or played")
+
+//A button press or release, applied at the start of Frame
+type MovieEvent struct {
+	Frame  int
+	Button int
+	Down   bool
+}
+
+//A recording of every input given to the emulator.
+//
+//Movies start either from power-on or from StartState, an embedded save state.
+//Battery backed RAM survives power-on so movies starting there keep a copy of it in
+//StartRAM. Checksums[i] is the checksum of the machine after i*MOVIE_CHECKSUM_INTERVAL frames
+type Movie struct {
+	ROMHash    string
+	StartState []byte
+	StartRAM   []byte
+	Frames     int
+	Events     []MovieEvent
+	Checksums  []uint32
+}
+
+func (m *Movie) Save(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	s := state.NewWriter(b)
+
+	s.Bytes([]byte(MOVIE_MAGIC))
+	s.Int(MOVIE_VERSION)
+	s.String(m.ROMHash)
+	s.Data(m.StartState)
+	s.Data(m.StartRAM)
+	s.Int(m.Frames)
+
+	s.Int(len(m.Events))
+	for _, e := range m.Events {
+		s.Int(e.Frame)
+		s.Byte(byte(e.Button))
+		s.Bool(e.Down)
+	}
+
+	s.Int(len(m.Checksums))
+	for _, c := range m.Checksums {
+		s.Int(int(c))
+	}
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return b.Flush()
+}
+
+func LoadMovie(r io.Reader) (*Movie, error) {
+	s := state.NewReader(bufio.NewReader(r))
+
+	magic := make([]byte, len(MOVIE_MAGIC))
+	s.Bytes(magic)
+	if s.Err() != nil || string(magic) != MOVIE_MAGIC {
+		return nil, NotAMovie
+	}
+
+	if v := s.Int(); s.Err() == nil && v != MOVIE_VERSION {
+		return nil, UnsupportedMovieVersion
+	}
+
+	var m *Movie = new(Movie)
+	m.ROMHash = s.String()
+	if start := s.Data(MAX_MOVIE_STATE_SIZE); len(start) > 0 {
+		m.StartState = start
+	}
+	if ram := s.Data(MAX_MOVIE_STATE_SIZE); len(ram) > 0 {
+		m.StartRAM = ram
+	}
+	m.Frames = s.Int()
+
+	events := s.Int()
+	for i := 0; i < events && s.Err() == nil; i++ {
+		e := MovieEvent{Frame: s.Int(), Button: int(s.Byte()), Down: s.Bool()}
+		if e.Button > 7 || e.Frame < 0 || e.Frame > m.Frames {
+			s.Invalid("movie event %d is out of range", i)
+		}
+		m.Events = append(m.Events, e)
+	}
+
+	checksums := s.Int()
+	for i := 0; i < checksums && s.Err() == nil; i++ {
+		m.Checksums = append(m.Checksums, uint32(s.Int()))
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//Called by the run loop around each frame while a movie is being recorded or played
+type movieDriver interface {
+	beforeFrame(gbc *GomeboyColor)
+	afterFrame(gbc *GomeboyColor)
+}
+
+func (gbc *GomeboyColor) checksum() uint32 {
+	var buf bytes.Buffer
+	gbc.saveComponents(state.NewWriter(&buf))
+	return crc32.ChecksumIEEE(buf.Bytes())
+}
+
+//Records input given through the KeyHandler. Input is held back until the start of
+//the next frame so playback can apply it at exactly the same point
+type MovieRecorder struct {
+	movie *Movie
+
+	mutex   sync.Mutex
+	pending []MovieEvent
+}
+
+//Starts recording a movie, either from power-on (which resets the machine) or
+//from the current state of the machine.
+//
+//This must not be called while a frame is being emulated, use BetweenFrames
+//when starting a recording from another goroutine.
+func (gbc *GomeboyColor) RecordMovie(fromPowerOn bool) (*MovieRecorder, error) {
+	if gbc.movie != nil {
+		return nil, MovieAlreadyRunning
+	}
+
+	var r *MovieRecorder = new(MovieRecorder)
+	r.movie = &Movie{ROMHash: gbc.cart.ROMHash}
+
+	if fromPowerOn {
+		gbc.Reset()
+		var buf bytes.Buffer
+		if err := gbc.cart.MBC.SaveRam(&buf); err != nil {
+			return nil, err
+		}
+		if buf.Len() > 0 {
+			r.movie.StartRAM = buf.Bytes()
+		}
+	} else {
+		var buf bytes.Buffer
+		if err := gbc.SaveState(&buf); err != nil {
+			return nil, err
+		}
+		r.movie.StartState = buf.Bytes()
+	}
+	r.movie.Checksums = append(r.movie.Checksums, gbc.checksum())
+
+	gbc.io.GetKeyHandler().Intercept(r.input)
+	gbc.movie = r
+	log.Println("Recording movie")
+	return r, nil
+}
+
+func (r *MovieRecorder) input(button int, down bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.pending = append(r.pending, MovieEvent{Button: button, Down: down})
+}
+
+func (r *MovieRecorder) beforeFrame(gbc *GomeboyColor) {
+	r.mutex.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.mutex.Unlock()
+
+	for _, e := range pending {
+		e.Frame = r.movie.Frames
+		gbc.io.GetKeyHandler().SetButton(e.Button, e.Down)
+		r.movie.Events = append(r.movie.Events, e)
+	}
+}
+
+func (r *MovieRecorder) afterFrame(gbc *GomeboyColor) {
+	r.movie.Frames++
+	if r.movie.Frames%MOVIE_CHECKSUM_INTERVAL == 0 {
+		r.movie.Checksums = append(r.movie.Checksums, gbc.checksum())
+	}
+}
+
+//Stops recording and returns the movie, input goes straight to the machine again.
+//
+//This must not be called while a frame is being emulated.
+func (gbc *GomeboyColor) StopRecording() *Movie {
+	r, ok := gbc.movie.(*MovieRecorder)
+	if !ok {
+		return nil
+	}
+
+	gbc.io.GetKeyHandler().Intercept(nil)
+	gbc.movie = nil
+	log.Printf("Recorded movie of %d frames", r.movie.Frames)
+	return r.movie
+}
+
+//Plays a movie back, ignoring input from the IOHandler until it has finished
+type MoviePlayer struct {
+	movie    *Movie
+	frame    int
+	next     int
+	finished chan bool
+
+	//frame at which the machine was found to differ from the recording (-1 if it has not)
+	desyncFrame int
+}
+
+//Starts playing a movie back from its beginning. Frontend input is ignored until
+//the movie finishes or StopPlayback is called.
+//
+//This must not be called while a frame is being emulated, use BetweenFrames
+//when starting playback from another goroutine.
+func (gbc *GomeboyColor) PlayMovie(m *Movie) (*MoviePlayer, error) {
+	if gbc.movie != nil {
+		return nil, MovieAlreadyRunning
+	}
+
+	if m.ROMHash != gbc.cart.ROMHash {
+		return nil, MovieROMMismatch
+	}
+
+	if m.StartState != nil {
+		if err := gbc.LoadState(bytes.NewReader(m.StartState)); err != nil {
+			return nil, err
+		}
+	} else {
+		gbc.Reset()
+		if m.StartRAM != nil {
+			if err := gbc.cart.MBC.LoadRam(bytes.NewReader(m.StartRAM)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var p *MoviePlayer = new(MoviePlayer)
+	p.movie = m
+	p.finished = make(chan bool)
+	p.desyncFrame = -1
+	p.check(gbc)
+
+	gbc.io.GetKeyHandler().Intercept(func(button int, down bool) {})
+	gbc.movie = p
+	log.Printf("Playing movie of %d frames", m.Frames)
+	return p, nil
+}
+
+func (p *MoviePlayer) beforeFrame(gbc *GomeboyColor) {
+	for ; p.next < len(p.movie.Events) && p.movie.Events[p.next].Frame == p.frame; p.next++ {
+		e := p.movie.Events[p.next]
+		gbc.io.GetKeyHandler().SetButton(e.Button, e.Down)
+	}
+}
+
+func (p *MoviePlayer) afterFrame(gbc *GomeboyColor) {
+	p.frame++
+	if p.frame%MOVIE_CHECKSUM_INTERVAL == 0 {
+		p.check(gbc)
+	}
+
+	if p.frame >= p.movie.Frames {
+		gbc.StopPlayback()
+	}
+}
+
+//Compares the machine against the checksum recorded for the current frame
+func (p *MoviePlayer) check(gbc *GomeboyColor) {
+	i := p.frame / MOVIE_CHECKSUM_INTERVAL
+	if p.desyncFrame >= 0 || i >= len(p.movie.Checksums) {
+		return
+	}
+
+	if gbc.checksum() != p.movie.Checksums[i] {
+		log.Printf("Movie playback desynced at frame %d", p.frame)
+		p.desyncFrame = p.frame
+	}
+}
+
+//Returns the frame at which playback stopped matching the recording, if it has
+func (p *MoviePlayer) Desynced() (int, bool) {
+	return p.desyncFrame, p.desyncFrame >= 0
+}
+
+//Closed once the movie has finished playing
+func (p *MoviePlayer) Finished() <-chan bool {
+	return p.finished
+}
+
+//Stops playing a movie back and gives input back to the IOHandler.
+//
+//This must not be called while a frame is being emulated.
+func (gbc *GomeboyColor) StopPlayback() {
+	p, ok := gbc.movie.(*MoviePlayer)
+	if !ok {
+		return
+	}
+
+	gbc.io.GetKeyHandler().Intercept(nil)
+	gbc.movie = nil
+	close(p.finished)
+	log.Printf("Movie playback stopped after %d frames", p.frame)
+}
@@ -0,0 +1,136 @@
+package gbc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/inputoutput"
+	"github.com/stretchrcom/testify/assert"
+)
+
+//key codes from the control scheme used by newTestGomeboyColor
+const (
+	testKeyA      = 5
+	testKeyB      = 6
+	testKeyStart  = 7
+	testKeySelect = 8
+)
+
+func recordTestMovie(t *testing.T, gbc *GomeboyColor, fromPowerOn bool) *Movie {
+	_, err := gbc.RecordMovie(fromPowerOn)
+	assert.Nil(t, err)
+
+	keys := gbc.io.GetKeyHandler()
+	for frame := 0; frame < 130; frame++ {
+		switch frame {
+		case 10:
+			keys.KeyDown(testKeyA)
+		case 50:
+			keys.KeyUp(testKeyA)
+		case 70:
+			keys.KeyDown(testKeyStart)
+			keys.KeyDown(testKeySelect)
+		}
+		gbc.runFrame()
+	}
+
+	return gbc.StopRecording()
+}
+
+func playTestMovie(t *testing.T, gbc *GomeboyColor, m *Movie) *MoviePlayer {
+	p, err := gbc.PlayMovie(m)
+	assert.Nil(t, err)
+
+	for frame := 0; frame < m.Frames; frame++ {
+		//input from the frontend is ignored during playback
+		gbc.io.GetKeyHandler().KeyDown(testKeyB)
+		gbc.runFrame()
+	}
+
+	select {
+	case <-p.Finished():
+	default:
+		t.Error("movie should have finished")
+	}
+	return p
+}
+
+func TestMovieRecordAndPlayback(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "MOVIETEST")
+	gbc.runFrame()
+	m := recordTestMovie(t, gbc, false)
+	expected := componentState(gbc)
+
+	assert.Equal(t, 130, m.Frames)
+	assert.Equal(t, []MovieEvent{
+		{Frame: 10, Button: inputoutput.BUTTON_A, Down: true},
+		{Frame: 50, Button: inputoutput.BUTTON_A, Down: false},
+		{Frame: 70, Button: inputoutput.BUTTON_START, Down: true},
+		{Frame: 70, Button: inputoutput.BUTTON_SELECT, Down: true},
+	}, m.Events)
+	assert.Equal(t, 3, len(m.Checksums))
+
+	var buf bytes.Buffer
+	assert.Nil(t, m.Save(&buf))
+	loaded, err := LoadMovie(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, m, loaded)
+
+	other := newTestGomeboyColor(t, "MOVIETEST")
+	p := playTestMovie(t, other, loaded)
+	_, desynced := p.Desynced()
+	assert.False(t, desynced)
+	assert.Equal(t, expected, componentState(other))
+}
+
+func TestMovieFromPowerOn(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "MOVIETEST")
+	for i := 0; i < 5; i++ {
+		gbc.runFrame()
+	}
+	m := recordTestMovie(t, gbc, true)
+	assert.Nil(t, m.StartState)
+	expected := componentState(gbc)
+
+	other := newTestGomeboyColor(t, "MOVIETEST")
+	playTestMovie(t, other, m)
+	assert.Equal(t, expected, componentState(other))
+}
+
+func TestMoviePlaysBackOnTheRecordingMachine(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "MOVIETEST")
+	gbc.mmu.WriteByte(0xA010, 0x24)
+	gbc.mmu.WriteByte(0x4000, 0x02)
+	m := recordTestMovie(t, gbc, true)
+	assert.NotNil(t, m.StartRAM)
+	expected := componentState(gbc)
+
+	//change the cartridge's bank registers and battery RAM before playing back
+	gbc.mmu.WriteByte(0x4000, 0x00)
+	gbc.mmu.WriteByte(0xA010, 0x99)
+	gbc.mmu.WriteByte(0x4000, 0x01)
+
+	p := playTestMovie(t, gbc, m)
+	_, desynced := p.Desynced()
+	assert.False(t, desynced)
+	assert.Equal(t, expected, componentState(gbc))
+	assert.Equal(t, byte(0x24), gbc.mmu.ReadByte(0xA010))
+}
+
+func TestMovieDetectsDesync(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "MOVIETEST")
+	m := recordTestMovie(t, gbc, false)
+	m.Events = m.Events[1:]
+
+	p := playTestMovie(t, newTestGomeboyColor(t, "MOVIETEST"), m)
+	frame, desynced := p.Desynced()
+	assert.True(t, desynced)
+	assert.Equal(t, MOVIE_CHECKSUM_INTERVAL, frame)
+}
+
+func TestMovieRefusesOtherROMs(t *testing.T) {
+	m := recordTestMovie(t, newTestGomeboyColor(t, "MOVIETEST"), true)
+
+	_, err := newTestGomeboyColor(t, "OTHERGAME").PlayMovie(m)
+	assert.Equal(t, MovieROMMismatch, err)
+}
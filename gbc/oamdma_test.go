@@ -0,0 +1,78 @@
+package gbc
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestOAMDMATiming(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "OAMDMA")
+	gbc.mmu.WriteByte(0xFF40, 0x00)
+	for i := 0; i < 0xA0; i++ {
+		gbc.mmu.WriteByte(0xC000+types.Word(i), byte(i+1))
+	}
+	gbc.mmu.WriteByte(0xFF80, 0x42)
+
+	gbc.mmu.WriteByte(0xFF46, 0xC0)
+	assert.Equal(t, byte(0xC0), gbc.mmu.ReadByte(0xFF46))
+
+	//OAM is still accessible for a cycle while the transfer starts up
+	gbc.tick(1)
+	assert.Equal(t, byte(0x00), gbc.mmu.ReadByte(0xFE00))
+
+	gbc.tick(1)
+	assert.Equal(t, byte(0xFF), gbc.mmu.ReadByte(0xFE00))
+	assert.Equal(t, byte(0x42), gbc.mmu.ReadByte(0xFF80))
+
+	//the bus being read from returns whatever the DMA is copying
+	gbc.tick(5)
+	assert.Equal(t, byte(0x05), gbc.mmu.ReadByte(0x0150))
+	gbc.mmu.WriteByte(0xC000, 0x99)
+	assert.Equal(t, byte(0x01), gbc.gpu.Read(0xFE00))
+	assert.Equal(t, byte(0x00), gbc.mmu.ReadByte(0x8000))
+
+	gbc.tick(154)
+	assert.Equal(t, byte(0xFF), gbc.mmu.ReadByte(0xFE00))
+	gbc.tick(1)
+	assert.Equal(t, byte(0x01), gbc.mmu.ReadByte(0xFE00))
+	assert.Equal(t, byte(0xA0), gbc.mmu.ReadByte(0xFE9F))
+	assert.Equal(t, byte(0x01), gbc.mmu.ReadByte(0xC000))
+}
+
+func TestOAMDMAFromEchoRAM(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "OAMDMA")
+	gbc.mmu.WriteByte(0xFF40, 0x00)
+	gbc.mmu.WriteByte(0xDE10, 0x24)
+
+	gbc.mmu.WriteByte(0xFF46, 0xFE)
+	gbc.tick(162)
+	assert.Equal(t, byte(0xFE), gbc.mmu.ReadByte(0xFF46))
+	assert.Equal(t, byte(0x24), gbc.gpu.Read(0xFE10))
+}
+
+func TestPeekIgnoresOAMDMA(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "OAMDMA")
+	gbc.mmu.WriteByte(0xFF40, 0x00)
+	gbc.mmu.WriteByte(0xC000, 0x24)
+	gbc.mmu.WriteByte(0xC050, 0x42)
+
+	gbc.mmu.WriteByte(0xFF46, 0xC0)
+	gbc.tick(4)
+	assert.Equal(t, byte(0xFF), gbc.mmu.ReadByte(0xFE00))
+	assert.Equal(t, byte(0x24), gbc.mmu.Peek(0xFE00))
+	assert.Equal(t, byte(0x42), gbc.mmu.Peek(0xC050))
+	assert.Equal(t, byte(0xE0), gbc.mmu.Peek(0x0102)) //LDH in the test ROM
+}
+
+func TestEchoRAMIsWorkingRAM(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "OAMDMA")
+	gbc.mmu.WriteByte(0xC000, 0x11)
+	gbc.mmu.WriteByte(0xC200, 0x22)
+	gbc.mmu.WriteByte(0xFDFF, 0x33)
+
+	assert.Equal(t, byte(0x11), gbc.mmu.ReadByte(0xE000))
+	assert.Equal(t, byte(0x22), gbc.mmu.ReadByte(0xE200))
+	assert.Equal(t, byte(0x33), gbc.mmu.ReadByte(0xDDFF))
+}
@@ -0,0 +1,65 @@
+package gbc
+
+import (
+	"github.com/djhworld/gomeboycolor/config"
+	"github.com/djhworld/gomeboycolor/gpu"
+	"github.com/djhworld/gomeboycolor/inputoutput"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Picks the shades DMG games are drawn in. A colour Gameboy colours them in the way its
+//boot ROM does, otherwise the configured preset is used
+func (gbc *GomeboyColor) setDMGColours() {
+	if gbc.config.ColorMode && !gbc.cart.IsColourGB {
+		gbc.gpu.SetDMGColours(gbc.colourisation().Palettes())
+		return
+	}
+
+	var palette gpu.Palette = dmgPalette(gbc.config)
+	gbc.gpu.SetDMGColours(palette, palette, palette)
+}
+
+func dmgPalette(conf *config.Config) gpu.Palette {
+	switch conf.DMGPalette {
+	case config.GREEN_PALETTE:
+		return gpu.GreenPalette
+	case config.POCKET_PALETTE:
+		return gpu.PocketPalette
+	case config.CUSTOM_PALETTE:
+		var palette gpu.Palette
+		for i, colour := range conf.CustomPalette {
+			palette[i] = types.RGB{Red: byte(colour >> 16), Green: byte(colour >> 8), Blue: byte(colour)}
+		}
+		return palette
+	}
+	return gpu.DefaultPalette()
+}
+
+//A direction held with A, B or neither overrides the colours picked from the title,
+//which are only looked up for games published by Nintendo
+func (gbc *GomeboyColor) colourisation() gpu.Colourisation {
+	keys := gbc.io.GetKeyHandler()
+	for direction := inputoutput.BUTTON_UP; direction <= inputoutput.BUTTON_RIGHT; direction++ {
+		if keys.IsPressed(direction) {
+			switch {
+			case keys.IsPressed(inputoutput.BUTTON_A):
+				return gpu.ButtonColourisations[direction][1]
+			case keys.IsPressed(inputoutput.BUTTON_B):
+				return gpu.ButtonColourisations[direction][2]
+			}
+			return gpu.ButtonColourisations[direction][0]
+		}
+	}
+
+	header := gbc.cart.Header
+	var licensee byte = header[0x4B]
+	if licensee != 0x01 && !(licensee == 0x33 && header[0x44] == '0' && header[0x45] == '1') {
+		return gpu.DefaultColourisation
+	}
+
+	var checksum byte
+	for _, b := range header[0x34:0x44] {
+		checksum += b
+	}
+	return gpu.TitleColourisation(checksum, header[0x37])
+}
@@ -0,0 +1,75 @@
+package gbc
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/cartridge"
+	"github.com/djhworld/gomeboycolor/config"
+	"github.com/djhworld/gomeboycolor/gpu"
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func newDMGCartGomeboyColor(t *testing.T, title string, licensee byte) *GomeboyColor {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0134:0x0144], title)
+	rom[0x014B] = licensee
+	copy(rom[0x0100:], []byte{0x18, 0xFE}) //JR -2
+
+	cart, err := cartridge.NewCartridge(title, rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &config.Config{Title: TITLE, ScreenSize: 1, SkipBoot: true, ColorMode: true}
+	return newTestGomeboyColorFor(cart, conf)
+}
+
+func TestColourisationByTitle(t *testing.T) {
+	var red types.RGB = types.RGB{Red: 248, Green: 128, Blue: 128}
+
+	gbc := newDMGCartGomeboyColor(t, "POKEMON RED", 0x01)
+	bg, _, _ := gbc.colourisation().Palettes()
+	assert.Equal(t, red, bg[1])
+
+	//only games published by Nintendo are recognised
+	gbc = newDMGCartGomeboyColor(t, "POKEMON RED", 0x08)
+	assert.Equal(t, gpu.DefaultColourisation, gbc.colourisation())
+
+	//titles sharing a checksum are told apart by their fourth letter
+	gbc = newDMGCartGomeboyColor(t, "POKEMON BLUE", 0x01)
+	assert.Equal(t, gpu.TitleColourisation(0x61, 'E'), gbc.colourisation())
+	assert.Equal(t, gpu.DefaultColourisation, gpu.TitleColourisation(0x61, 'Z'))
+
+	gbc = newDMGCartGomeboyColor(t, "UNKNOWN GAME", 0x01)
+	assert.Equal(t, gpu.DefaultColourisation, gbc.colourisation())
+}
+
+func TestColourisationButtonCombos(t *testing.T) {
+	gbc := newDMGCartGomeboyColor(t, "POKEMON RED", 0x01)
+	gbc.io.GetKeyHandler().KeyDown(3)
+	assert.Equal(t, gpu.ButtonColourisations[2][0], gbc.colourisation())
+	gbc.io.GetKeyHandler().KeyDown(6)
+	assert.Equal(t, gpu.ButtonColourisations[2][2], gbc.colourisation())
+}
+
+func TestDMGPalettePresets(t *testing.T) {
+	assert.Equal(t, gpu.GreyPalette, dmgPalette(&config.Config{}))
+	assert.Equal(t, gpu.GreenPalette, dmgPalette(&config.Config{DMGPalette: config.GREEN_PALETTE}))
+
+	conf := &config.Config{DMGPalette: config.CUSTOM_PALETTE, CustomPalette: [4]uint32{0xFF0000, 0x00FF00, 0x0000FF, 0x102030}}
+	palette := dmgPalette(conf)
+	assert.Equal(t, types.RGB{Red: 255}, palette[0])
+	assert.Equal(t, types.RGB{Red: 0x10, Green: 0x20, Blue: 0x30}, palette[3])
+}
+
+func TestDMGPaletteDefaultFollowsGBColours(t *testing.T) {
+	saved := gpu.GBColours
+	defer func() { gpu.GBColours = saved }()
+	gpu.GBColours = []types.RGB{{Red: 255}, {Green: 255}, {Blue: 255}, {}}
+
+	var expected gpu.Palette = gpu.Palette{{Red: 255}, {Green: 255}, {Blue: 255}, {}}
+	assert.Equal(t, expected, dmgPalette(&config.Config{}))
+	assert.Equal(t, expected, dmgPalette(&config.Config{DMGPalette: config.GREY_PALETTE}))
+	assert.Equal(t, gpu.GreenPalette, dmgPalette(&config.Config{DMGPalette: config.GREEN_PALETTE}))
+}
@@ -0,0 +1,227 @@
+package gbc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"sync/atomic"
+
+	"github.com/djhworld/gomeboycolor/state"
+)
+
+//number of frames between full snapshots in the rewind buffer
+const REWIND_KEYFRAME_INTERVAL int = 60
+
+//A keyframe with the frames captured after it, each stored as a diff against the keyframe.
+//Groups are always evicted as a whole since the diffs are useless without their keyframe
+type rewindGroup struct {
+	keyframe []byte
+	deltas   [][]byte
+	size     int
+}
+
+//Keeps a per frame history of the machine within a memory budget.
+//
+//Every frame is a snapshot of the machine (without a save state header) that is XORed
+//against the most recent keyframe and run length encoded, so the parts of the machine
+//that have not changed take up almost no space. Keyframes are encoded the same way
+//against an empty snapshot.
+type rewindBuffer struct {
+	budget   int
+	interval int
+	groups   []*rewindGroup
+	size     int
+
+	snapshot bytes.Buffer
+	keyframe []byte
+	frame    []byte
+}
+
+func newRewindBuffer(budget, interval int) *rewindBuffer {
+	var r *rewindBuffer = new(rewindBuffer)
+	r.budget = budget
+	r.interval = interval
+	return r
+}
+
+//Number of frames that can be stepped back to
+func (r *rewindBuffer) Frames() int {
+	frames := 0
+	for _, g := range r.groups {
+		frames += 1 + len(g.deltas)
+	}
+	return frames
+}
+
+//Size in bytes of the encoded history
+func (r *rewindBuffer) Size() int {
+	return r.size
+}
+
+//Captures the current frame of the machine
+func (r *rewindBuffer) Push(gbc *GomeboyColor) {
+	r.snapshot.Reset()
+	gbc.saveComponents(state.NewWriter(&r.snapshot))
+	current := r.snapshot.Bytes()
+
+	var last *rewindGroup
+	if len(r.groups) > 0 {
+		last = r.groups[len(r.groups)-1]
+	}
+
+	if last == nil || len(last.deltas)+1 >= r.interval {
+		encoded := encodeDelta(nil, current)
+		r.groups = append(r.groups, &rewindGroup{keyframe: encoded, size: len(encoded)})
+		r.size += len(encoded)
+		r.keyframe = append(r.keyframe[:0], current...)
+	} else {
+		encoded := encodeDelta(r.keyframe, current)
+		last.deltas = append(last.deltas, encoded)
+		last.size += len(encoded)
+		r.size += len(encoded)
+	}
+
+	for r.size > r.budget && len(r.groups) > 1 {
+		r.size -= r.groups[0].size
+		r.groups[0] = nil
+		r.groups = r.groups[1:]
+	}
+}
+
+//Discards the newest n frames and restores the machine to the frame before them,
+//the oldest frame is never discarded. Returns the number of frames stepped back
+func (r *rewindBuffer) StepBack(gbc *GomeboyColor, n int) int {
+	stepped := 0
+	for ; stepped < n && r.Frames() > 1; stepped++ {
+		last := r.groups[len(r.groups)-1]
+		if len(last.deltas) > 0 {
+			delta := last.deltas[len(last.deltas)-1]
+			last.deltas = last.deltas[:len(last.deltas)-1]
+			last.size -= len(delta)
+			r.size -= len(delta)
+		} else {
+			r.size -= last.size
+			r.groups = r.groups[:len(r.groups)-1]
+		}
+	}
+
+	if stepped == 0 {
+		return 0
+	}
+
+	last := r.groups[len(r.groups)-1]
+	r.keyframe = decodeDelta(r.keyframe[:0], nil, last.keyframe)
+	snapshot := r.keyframe
+	if len(last.deltas) > 0 {
+		r.frame = decodeDelta(r.frame[:0], r.keyframe, last.deltas[len(last.deltas)-1])
+		snapshot = r.frame
+	}
+
+	s := state.NewReader(bytes.NewReader(snapshot))
+	gbc.loadComponents(s)
+	if err := s.Err(); err != nil {
+		log.Println("Rewind snapshot could not be restored:", err)
+	}
+
+	return stepped
+}
+
+//Encodes the XOR of current against base (missing bytes of base count as zero) as runs of
+//unchanged bytes followed by literal runs of changed bytes, each run prefixed by its length
+func encodeDelta(base, current []byte) []byte {
+	var out []byte = make([]byte, 0, 64)
+	var varint [binary.MaxVarintLen64]byte
+	put := func(v int) {
+		n := binary.PutUvarint(varint[:], uint64(v))
+		out = append(out, varint[:n]...)
+	}
+	xor := func(i int) byte {
+		if i < len(base) {
+			return current[i] ^ base[i]
+		}
+		return current[i]
+	}
+
+	put(len(current))
+	for i := 0; i < len(current); {
+		start := i
+		for i < len(current) && xor(i) == 0 {
+			i++
+		}
+		put(i - start)
+
+		start = i
+		for i < len(current) && xor(i) != 0 {
+			i++
+		}
+		put(i - start)
+		for j := start; j < i; j++ {
+			out = append(out, xor(j))
+		}
+	}
+
+	return out
+}
+
+//Reverses encodeDelta, appending the result to out
+func decodeDelta(out, base, delta []byte) []byte {
+	r := bytes.NewReader(delta)
+	get := func() int {
+		v, _ := binary.ReadUvarint(r)
+		return int(v)
+	}
+	baseAt := func(i int) byte {
+		if i < len(base) {
+			return base[i]
+		}
+		return 0
+	}
+
+	length := get()
+	for len(out) < length {
+		unchanged := get()
+		for j := 0; j < unchanged; j++ {
+			out = append(out, baseAt(len(out)))
+		}
+
+		changed := get()
+		for j := 0; j < changed; j++ {
+			b, _ := r.ReadByte()
+			out = append(out, baseAt(len(out))^b)
+		}
+	}
+
+	return out
+}
+
+//Turns rewinding on or off, while it is on the emulator steps backwards through
+//its history one frame at a time instead of running. Safe to call from any goroutine
+func (gbc *GomeboyColor) SetRewinding(rewinding bool) {
+	var v int32 = 0
+	if rewinding {
+		v = 1
+	}
+	atomic.StoreInt32(&gbc.rewinding, v)
+}
+
+func (gbc *GomeboyColor) isRewinding() bool {
+	return atomic.LoadInt32(&gbc.rewinding) == 1
+}
+
+//Steps the machine back up to n frames, returning how many frames it went back.
+//Emulation resumes from that point, the frames that were stepped over are forgotten.
+//
+//This must not be called while a frame is being emulated, use BetweenFrames
+//when rewinding from another goroutine.
+func (gbc *GomeboyColor) RewindFrames(n int) int {
+	if gbc.rewind == nil {
+		return 0
+	}
+	return gbc.rewind.StepBack(gbc, n)
+}
+
+//Shows the previous frame of history, called in place of emulating a frame while rewinding
+func (gbc *GomeboyColor) rewindFrame() {
+	gbc.RewindFrames(1)
+	gbc.io.GetScreenOutputChannel() <- gbc.gpu.Screen()
+}
@@ -0,0 +1,73 @@
+package gbc
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDeltaRoundTrip(t *testing.T) {
+	base := []byte{1, 2, 3, 4, 5, 6}
+	for _, current := range [][]byte{
+		{1, 2, 3, 4, 5, 6},
+		{1, 9, 3, 4, 9, 9},
+		{1, 2, 3},
+		{1, 2, 3, 4, 5, 6, 7, 0, 8},
+		{},
+	} {
+		assert.Equal(t, current, decodeDelta([]byte{}, base, encodeDelta(base, current)))
+		assert.Equal(t, current, decodeDelta([]byte{}, nil, encodeDelta(nil, current)))
+	}
+}
+
+func TestRewindRestoresEarlierFrames(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "REWINDTEST")
+	gbc.rewind = newRewindBuffer(1<<30, 4)
+
+	var history [][]byte
+	for i := 0; i < 10; i++ {
+		gbc.mmu.WriteByte(0xC100, byte(i))
+		gbc.runFrame()
+		history = append(history, componentState(gbc))
+	}
+
+	assert.Equal(t, 5, gbc.RewindFrames(5))
+	assert.Equal(t, history[4], componentState(gbc))
+	assert.Equal(t, byte(4), gbc.mmu.ReadByte(0xC100))
+
+	//emulation resumes from the rewound frame
+	gbc.runFrame()
+	assert.Equal(t, 6, gbc.rewind.Frames())
+
+	assert.Equal(t, 5, gbc.RewindFrames(100), "the oldest frame is always kept")
+	assert.Equal(t, history[0], componentState(gbc))
+}
+
+func TestRewindStaysWithinBudget(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "REWINDTEST")
+	gbc.rewind = newRewindBuffer(1<<30, 4)
+	for i := 0; i < 8; i++ {
+		gbc.runFrame()
+	}
+
+	budget := gbc.rewind.Size() / 2
+	gbc.rewind = newRewindBuffer(budget, 4)
+	for i := 0; i < 40; i++ {
+		gbc.runFrame()
+		assert.True(t, gbc.rewind.Size() <= budget || gbc.rewind.Frames() <= 4)
+	}
+	assert.True(t, gbc.rewind.Frames() < 40)
+}
+
+func BenchmarkRewindPush(b *testing.B) {
+	gbc := newTestGomeboyColor(&testing.T{}, "REWINDTEST")
+	gbc.rewind = newRewindBuffer(64<<20, REWIND_KEYFRAME_INTERVAL)
+	gbc.doFrame()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gbc.mmu.WriteByte(0xC100+types.Word(i%0x0F00), byte(i))
+		gbc.rewind.Push(gbc)
+	}
+}
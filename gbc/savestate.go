@@ -0,0 +1,195 @@
+package gbc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"time"
+
+	"github.com/djhworld/gomeboycolor/state"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//identifies the start of a save state
+const STATE_MAGIC string = "GBCSTATE"
+
+//must be incremented whenever the layout of a save state changes
+const STATE_VERSION int = 11
+
+const THUMBNAIL_WIDTH int = 80
+const THUMBNAIL_HEIGHT int = 72
+
+var NotASaveState error = errors.New("Not a save state")
+var UnsupportedStateVersion error = errors.New("Save state version is unsupported")
+var StateCartridgeMismatch error = errors.New("Save state was created for a different cartridge")
+
+//A half size copy of the screen at the time a save state was made
+type Thumbnail [THUMBNAIL_HEIGHT][THUMBNAIL_WIDTH]types.RGB
+
+//Scales the screen down by averaging each 2x2 block of pixels
+func NewThumbnail(screen *types.Screen) *Thumbnail {
+	var t *Thumbnail = new(Thumbnail)
+	for y := 0; y < THUMBNAIL_HEIGHT; y++ {
+		for x := 0; x < THUMBNAIL_WIDTH; x++ {
+			var r, g, b int
+			for _, p := range []types.RGB{screen[y*2][x*2], screen[y*2][x*2+1], screen[y*2+1][x*2], screen[y*2+1][x*2+1]} {
+				r, g, b = r+int(p.Red), g+int(p.Green), b+int(p.Blue)
+			}
+			t[y][x] = types.RGB{Red: byte(r / 4), Green: byte(g / 4), Blue: byte(b / 4)}
+		}
+	}
+	return t
+}
+
+func (t *Thumbnail) Image() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, THUMBNAIL_WIDTH, THUMBNAIL_HEIGHT))
+	for y := range t {
+		for x, c := range t[y] {
+			img.Set(x, y, color.RGBA{R: c.Red, G: c.Green, B: c.Blue, A: 0xFF})
+		}
+	}
+	return img
+}
+
+type StateHeader struct {
+	Version     int
+	CartridgeID string
+	Title       string
+	SavedAt     time.Time
+	Thumbnail   Thumbnail
+}
+
+func writeStateHeader(s *state.Writer, h *StateHeader) {
+	s.Bytes([]byte(STATE_MAGIC))
+	s.Int(h.Version)
+	s.String(h.CartridgeID)
+	s.String(h.Title)
+	s.Int(int(h.SavedAt.UnixNano()))
+	for y := range h.Thumbnail {
+		for _, c := range h.Thumbnail[y] {
+			s.Bytes([]byte{c.Red, c.Green, c.Blue})
+		}
+	}
+}
+
+func readStateHeader(s *state.Reader) (*StateHeader, error) {
+	magic := make([]byte, len(STATE_MAGIC))
+	s.Bytes(magic)
+	if err := s.Err(); err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	} else if err != nil || string(magic) != STATE_MAGIC {
+		return nil, NotASaveState
+	}
+
+	var h *StateHeader = new(StateHeader)
+	h.Version = s.Int()
+	if s.Err() == nil && h.Version != STATE_VERSION {
+		log.Printf("Save state version %d cannot be loaded by this version of %s (expected %d)", h.Version, TITLE, STATE_VERSION)
+		return nil, UnsupportedStateVersion
+	}
+
+	h.CartridgeID = s.String()
+	h.Title = s.String()
+	h.SavedAt = time.Unix(0, int64(s.Int()))
+
+	var pixel [3]byte
+	for y := range h.Thumbnail {
+		for x := range h.Thumbnail[y] {
+			s.Bytes(pixel[:])
+			h.Thumbnail[y][x] = types.RGB{Red: pixel[0], Green: pixel[1], Blue: pixel[2]}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+//Reads just the header of a save state, e.g. to show its thumbnail before loading it
+func ReadStateHeader(r io.Reader) (*StateHeader, error) {
+	return readStateHeader(state.NewReader(bufio.NewReader(r)))
+}
+
+//Writes a snapshot of the whole machine.
+//
+//This must not be called while a frame is being emulated, use BetweenFrames
+//when saving from another goroutine.
+func (gbc *GomeboyColor) SaveState(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	s := state.NewWriter(b)
+
+	writeStateHeader(s, &StateHeader{
+		Version:     STATE_VERSION,
+		CartridgeID: gbc.cart.ID,
+		Title:       gbc.cart.Title,
+		SavedAt:     time.Now(),
+		Thumbnail:   *NewThumbnail(gbc.gpu.Screen()),
+	})
+	gbc.saveComponents(s)
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return b.Flush()
+}
+
+//Restores a snapshot written by SaveState. Snapshots of other cartridges are
+//refused, and the machine is left untouched if the snapshot cannot be loaded.
+//
+//This must not be called while a frame is being emulated, use BetweenFrames
+//when loading from another goroutine.
+func (gbc *GomeboyColor) LoadState(r io.Reader) error {
+	s := state.NewReader(bufio.NewReader(r))
+
+	h, err := readStateHeader(s)
+	if err != nil {
+		return err
+	}
+
+	if h.CartridgeID != gbc.cart.ID {
+		log.Printf("Refusing to load save state for %q into %q", h.Title, gbc.cart.Title)
+		return StateCartridgeMismatch
+	}
+
+	//keep the current state around in case the snapshot turns out to be corrupt
+	var backup bytes.Buffer
+	gbc.saveComponents(state.NewWriter(&backup))
+
+	gbc.loadComponents(s)
+	if err := s.Err(); err != nil {
+		log.Println("Could not load save state, restoring previous state:", err)
+		gbc.loadComponents(state.NewReader(&backup))
+		return err
+	}
+
+	return nil
+}
+
+func (gbc *GomeboyColor) saveComponents(s *state.Writer) {
+	gbc.cpu.SaveState(s)
+	gbc.mmu.SaveState(s)
+	gbc.gpu.SaveState(s)
+	gbc.timer.SaveState(s)
+	gbc.apu.SaveState(s)
+	gbc.io.GetKeyHandler().SaveState(s)
+	gbc.cart.MBC.SaveState(s)
+	s.Int(gbc.cpuClockAcc)
+	s.Bool(gbc.inBootMode)
+}
+
+func (gbc *GomeboyColor) loadComponents(s *state.Reader) {
+	gbc.cpu.LoadState(s)
+	gbc.mmu.LoadState(s)
+	gbc.gpu.LoadState(s)
+	gbc.timer.LoadState(s)
+	gbc.apu.LoadState(s)
+	gbc.io.GetKeyHandler().LoadState(s)
+	gbc.cart.MBC.LoadState(s)
+	gbc.cpuClockAcc = s.Int()
+	gbc.inBootMode = s.Bool()
+}
@@ -0,0 +1,171 @@
+package gbc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/cartridge"
+	"github.com/djhworld/gomeboycolor/config"
+	"github.com/djhworld/gomeboycolor/inputoutput"
+	"github.com/djhworld/gomeboycolor/state"
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+type testIO struct {
+	keyHandler *inputoutput.KeyHandler
+	screen     chan *types.Screen
+}
+
+func (i *testIO) Init(title string, screenSize int, onCloseHandler func()) error {
+	return nil
+}
+
+func (i *testIO) GetKeyHandler() *inputoutput.KeyHandler {
+	return i.keyHandler
+}
+
+func (i *testIO) GetScreenOutputChannel() chan *types.Screen {
+	return i.screen
+}
+
+func (i *testIO) GetAvgFrameRate() float32 {
+	return 0
+}
+
+func (i *testIO) Run() {
+}
+
+//Builds an emulator running a ROM that copies the button row of the joypad to 0xC000
+//forever, with frames being thrown away
+func newTestGomeboyColor(t testing.TB, title string) *GomeboyColor {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0134:0x0142], title)
+	rom[0x0147] = cartridge.MBC_3_RAM_BATT
+	rom[0x0149] = 0x03
+	copy(rom[0x0100:], []byte{
+		0x3E, 0x10, //LD A, 0x10
+		0xE0, 0x00, //LDH (0x00), A
+		0xF0, 0x00, //LDH A, (0x00)
+		0xEA, 0x00, 0xC0, //LD (0xC000), A
+		0x18, 0xF5, //JR 0x0100
+	})
+
+	cart, err := cartridge.NewCartridge(title, rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &config.Config{Title: TITLE, ScreenSize: 1, SkipBoot: true, ColorMode: true}
+	return newTestGomeboyColorFor(cart, conf)
+}
+
+//Builds an emulator for cart that throws its frames away
+func newTestGomeboyColorFor(cart *cartridge.Cartridge, conf *config.Config) *GomeboyColor {
+	io := &testIO{keyHandler: new(inputoutput.KeyHandler), screen: make(chan *types.Screen, 1)}
+	io.keyHandler.Init(inputoutput.ControlScheme{UP: 1, DOWN: 2, LEFT: 3, RIGHT: 4, A: 5, B: 6, START: 7, SELECT: 8})
+	go func() {
+		for range io.screen {
+		}
+	}()
+
+	store := new(recordingStore)
+	gbc := newGomeboyColor(cart, conf, store, io)
+	gbc.batterySaver = newBatterySaver(store, cart.ID, cart.Title, 0)
+	gbc.mmu.LoadCartridge(cart)
+	gbc.gpu.LinkScreen(io.screen)
+	gbc.setupBoot()
+	return gbc
+}
+
+//Saves the machine without a header so two snapshots can be compared
+func componentState(gbc *GomeboyColor) []byte {
+	var buf bytes.Buffer
+	gbc.saveComponents(state.NewWriter(&buf))
+	return buf.Bytes()
+}
+
+func TestSaveStateRoundTrip(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "STATETEST")
+	gbc.doFrame()
+	gbc.mmu.WriteByte(0xC100, 0x42)
+	gbc.mmu.WriteByte(0xA010, 0x24)
+	gbc.io.GetKeyHandler().KeyDown(5)
+
+	var buf bytes.Buffer
+	assert.Nil(t, gbc.SaveState(&buf))
+	expected := componentState(gbc)
+
+	gbc.cpuClockAcc = 0
+	gbc.doFrame()
+	gbc.mmu.WriteByte(0xC100, 0x00)
+	gbc.mmu.WriteByte(0xA010, 0x00)
+	gbc.io.GetKeyHandler().KeyUp(5)
+	assert.NotEqual(t, expected, componentState(gbc))
+
+	assert.Nil(t, gbc.LoadState(&buf))
+	assert.Equal(t, expected, componentState(gbc))
+	assert.Equal(t, byte(0x42), gbc.mmu.ReadByte(0xC100))
+	assert.Equal(t, byte(0x24), gbc.mmu.ReadByte(0xA010))
+}
+
+func TestLoadStateRefusesOtherCartridges(t *testing.T) {
+	other := newTestGomeboyColor(t, "OTHERGAME")
+	var buf bytes.Buffer
+	assert.Nil(t, other.SaveState(&buf))
+
+	gbc := newTestGomeboyColor(t, "STATETEST")
+	assert.Equal(t, StateCartridgeMismatch, gbc.LoadState(&buf))
+}
+
+func TestLoadStateKeepsMachineWhenTruncated(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "STATETEST")
+	var buf bytes.Buffer
+	assert.Nil(t, gbc.SaveState(&buf))
+
+	gbc.mmu.WriteByte(0xC100, 0x42)
+	expected := componentState(gbc)
+
+	assert.NotNil(t, gbc.LoadState(bytes.NewReader(buf.Bytes()[:buf.Len()/2])))
+	assert.Equal(t, expected, componentState(gbc))
+}
+
+func TestReadStateHeader(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "STATETEST")
+	var buf bytes.Buffer
+	assert.Nil(t, gbc.SaveState(&buf))
+
+	h, err := ReadStateHeader(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, STATE_VERSION, h.Version)
+	assert.Equal(t, gbc.cart.ID, h.CartridgeID)
+	assert.Equal(t, gbc.cart.Title, h.Title)
+
+	_, err = ReadStateHeader(bytes.NewReader([]byte("not a state")))
+	assert.Equal(t, NotASaveState, err)
+}
+
+func TestLoadStateLeavesBatteryRAMClean(t *testing.T) {
+	other := newTestGomeboyColor(t, "STATETEST")
+	other.mmu.WriteByte(0xA010, 0x24)
+	var buf bytes.Buffer
+	assert.Nil(t, other.SaveState(&buf))
+
+	gbc := newTestGomeboyColor(t, "STATETEST")
+	assert.Nil(t, gbc.LoadState(&buf))
+	assert.Equal(t, byte(0x24), gbc.mmu.ReadByte(0xA010))
+	assert.False(t, gbc.cart.MBC.IsRamDirty(), "loading a state should not overwrite the battery save")
+}
+
+func TestLoadStateRefusesBanksTheCartridgeLacks(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "STATETEST")
+	var buf bytes.Buffer
+	s := state.NewWriter(&buf)
+	s.Int(2) //the test ROM only has banks 0 and 1
+	s.Int(0)
+	s.Bool(true)
+
+	r := state.NewReader(&buf)
+	gbc.cart.MBC.LoadState(r)
+	assert.NotNil(t, r.Err())
+}
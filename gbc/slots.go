@@ -0,0 +1,203 @@
+package gbc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"log"
+	"time"
+
+	"github.com/djhworld/gomeboycolor/saves"
+	"github.com/djhworld/gomeboycolor/state"
+)
+
+const STATE_SLOTS int = 10
+
+//identifies the start of a save state slot
+const SLOT_MAGIC string = "GBCSLOT"
+
+//largest thumbnail that will be read back from a slot
+const MAX_THUMBNAIL_SIZE int = 1 << 20
+
+var InvalidSlot error = errors.New(fmt.Sprintf("Save state slots are numbered 0 to %d", STATE_SLOTS-1))
+var NotASlot error = errors.New("Not a save state slot")
+var NothingToUndo error = errors.New("No save state has been loaded")
+
+//Describes what is stored in a slot without loading the state itself
+type SlotInfo struct {
+	Slot      int
+	SavedAt   time.Time
+	Version   string
+	Thumbnail []byte //PNG encoded
+}
+
+//Manages numbered save state slots for a game.
+//
+//Each slot holds a PNG thumbnail of the screen, the time it was saved and the
+//emulator version, followed by the machine state. Slots are kept in a saves.Store
+//so any frontend can choose where they live.
+//
+//Like SaveState and LoadState, the slot manager must only be used between frames
+type SlotManager struct {
+	gbc         *GomeboyColor
+	store       saves.Store
+	CurrentSlot int
+	undo        []byte
+}
+
+func NewSlotManager(gbc *GomeboyColor, store saves.Store) *SlotManager {
+	var m *SlotManager = new(SlotManager)
+	m.gbc = gbc
+	m.store = store
+	return m
+}
+
+func (m *SlotManager) slotName(slot int) (string, error) {
+	if slot < 0 || slot >= STATE_SLOTS {
+		return "", InvalidSlot
+	}
+	return saves.StateSlotName(m.gbc.cart.ID, slot), nil
+}
+
+//Saves the machine into slot, replacing whatever was there
+func (m *SlotManager) Save(slot int) error {
+	name, err := m.slotName(slot)
+	if err != nil {
+		return err
+	}
+
+	var thumbnail bytes.Buffer
+	if err := png.Encode(&thumbnail, NewThumbnail(m.gbc.gpu.Screen()).Image()); err != nil {
+		return err
+	}
+
+	//the state is serialized before the store is touched so a failure cannot leave a partial slot
+	var buf bytes.Buffer
+	s := state.NewWriter(&buf)
+	s.Bytes([]byte(SLOT_MAGIC))
+	s.String(VERSION)
+	s.Int(int(time.Now().UnixNano()))
+	s.Data(thumbnail.Bytes())
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if err := m.gbc.SaveState(&buf); err != nil {
+		return err
+	}
+
+	w, err := m.store.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		w.Close()
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	log.Printf("Saved state to slot %d", slot)
+	return nil
+}
+
+//Loads the state in slot, the machine as it was beforehand can be restored with UndoLoad
+func (m *SlotManager) Load(slot int) error {
+	r, err := m.open(slot)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	s := state.NewReader(r)
+	if _, err := readSlotInfo(s, slot); err != nil {
+		return err
+	}
+
+	var undo bytes.Buffer
+	if err := m.gbc.SaveState(&undo); err != nil {
+		return err
+	}
+
+	if err := m.gbc.LoadState(r); err != nil {
+		return err
+	}
+
+	m.undo = undo.Bytes()
+	log.Printf("Loaded state from slot %d", slot)
+	return nil
+}
+
+//Reads the thumbnail, timestamp and version stored in slot
+func (m *SlotManager) Info(slot int) (*SlotInfo, error) {
+	r, err := m.open(slot)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return readSlotInfo(state.NewReader(r), slot)
+}
+
+func (m *SlotManager) QuickSave() error {
+	return m.Save(m.CurrentSlot)
+}
+
+func (m *SlotManager) QuickLoad() error {
+	return m.Load(m.CurrentSlot)
+}
+
+//Selects the slot used by QuickSave and QuickLoad
+func (m *SlotManager) SelectSlot(slot int) error {
+	if slot < 0 || slot >= STATE_SLOTS {
+		return InvalidSlot
+	}
+	m.CurrentSlot = slot
+	return nil
+}
+
+//Returns the machine to how it was before the last slot was loaded
+func (m *SlotManager) UndoLoad() error {
+	if m.undo == nil {
+		return NothingToUndo
+	}
+
+	if err := m.gbc.LoadState(bytes.NewReader(m.undo)); err != nil {
+		return err
+	}
+
+	m.undo = nil
+	log.Println("Undid last save state load")
+	return nil
+}
+
+func (m *SlotManager) open(slot int) (io.ReadCloser, error) {
+	name, err := m.slotName(slot)
+	if err != nil {
+		return nil, err
+	}
+	return m.store.Open(name)
+}
+
+func readSlotInfo(s *state.Reader, slot int) (*SlotInfo, error) {
+	magic := make([]byte, len(SLOT_MAGIC))
+	s.Bytes(magic)
+	if s.Err() != nil || string(magic) != SLOT_MAGIC {
+		return nil, NotASlot
+	}
+
+	var info *SlotInfo = new(SlotInfo)
+	info.Slot = slot
+	info.Version = s.String()
+	info.SavedAt = time.Unix(0, int64(s.Int()))
+	info.Thumbnail = s.Data(MAX_THUMBNAIL_SIZE)
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,56 @@
+package gbc
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/saves"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestSlotsSaveAndLoad(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "SLOTTEST")
+	slots := NewSlotManager(gbc, saves.NewMemoryStore())
+
+	gbc.mmu.WriteByte(0xC000, 0x11)
+	assert.Nil(t, slots.Save(3))
+	gbc.mmu.WriteByte(0xC000, 0x22)
+
+	assert.Nil(t, slots.Load(3))
+	assert.Equal(t, byte(0x11), gbc.mmu.ReadByte(0xC000))
+
+	info, err := slots.Info(3)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, info.Slot)
+	assert.False(t, info.SavedAt.IsZero())
+
+	img, err := png.Decode(bytes.NewReader(info.Thumbnail))
+	assert.Nil(t, err)
+	assert.Equal(t, THUMBNAIL_WIDTH, img.Bounds().Dx())
+	assert.Equal(t, THUMBNAIL_HEIGHT, img.Bounds().Dy())
+}
+
+func TestSlotsUndoLoad(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "SLOTTEST")
+	slots := NewSlotManager(gbc, saves.NewMemoryStore())
+	assert.Equal(t, NothingToUndo, slots.UndoLoad())
+
+	gbc.mmu.WriteByte(0xC000, 0x11)
+	assert.Nil(t, slots.QuickSave())
+	gbc.mmu.WriteByte(0xC000, 0x22)
+	assert.Nil(t, slots.QuickLoad())
+
+	assert.Nil(t, slots.UndoLoad())
+	assert.Equal(t, byte(0x22), gbc.mmu.ReadByte(0xC000))
+	assert.Equal(t, NothingToUndo, slots.UndoLoad())
+}
+
+func TestSlotsRejectInvalidSlots(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "SLOTTEST")
+	slots := NewSlotManager(gbc, saves.NewMemoryStore())
+
+	assert.Equal(t, InvalidSlot, slots.Save(STATE_SLOTS))
+	assert.Equal(t, InvalidSlot, slots.SelectSlot(-1))
+	assert.NotNil(t, slots.Load(0), "empty slot cannot be loaded")
+}
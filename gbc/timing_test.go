@@ -0,0 +1,189 @@
+package gbc
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/inputoutput"
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+// Runs a program from work RAM for the given number of instructions
+func runProgram(gbc *GomeboyColor, instructions int, program ...byte) {
+	for i, b := range program {
+		gbc.mmu.WriteByte(0xC200+types.Word(i), b)
+	}
+	gbc.cpu.PC = 0xC200
+	gbc.cpu.InterruptsEnabled = false
+	for i := 0; i < instructions; i++ {
+		gbc.Step()
+	}
+}
+
+func TestMemoryAccessesSeeTimerMidInstruction(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TIMING")
+
+	//TIMA is clocked every 4 M-cycles, after resetting DIV and TIMA the next increment
+	//lands on the 4th M-cycle of the following instruction
+	runProgram(gbc, 14,
+		0x3E, 0x05, //LD A, 0x05
+		0xE0, 0x07, //LDH (0x07), A
+		0xAF,       //XOR A
+		0xE0, 0x04, //LDH (0x04), A
+		0xE0, 0x05, //LDH (0x05), A
+		0x00,             //NOP
+		0xFA, 0x05, 0xFF, //LD A, (0xFF05) - reads on its 4th M-cycle
+		0xEA, 0x00, 0xC1, //LD (0xC100), A
+		0xAF,       //XOR A
+		0xE0, 0x04, //LDH (0x04), A
+		0xE0, 0x05, //LDH (0x05), A
+		0x00,       //NOP
+		0xF0, 0x05, //LDH A, (0x05) - reads on its 3rd M-cycle
+		0xEA, 0x01, 0xC1, //LD (0xC101), A
+	)
+
+	assert.Equal(t, byte(0x02), gbc.mmu.ReadByte(0xC100))
+	assert.Equal(t, byte(0x01), gbc.mmu.ReadByte(0xC101))
+}
+
+func TestHaltBugRepeatsNextByte(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TIMING")
+
+	//with interrupts disabled and one already pending HALT does not stop the CPU,
+	//instead the INC B following it is run twice
+	runProgram(gbc, 9,
+		0x06, 0x00, //LD B, 0x00
+		0x3E, 0x04, //LD A, 0x04
+		0xE0, 0xFF, //LDH (0xFF), A
+		0xE0, 0x0F, //LDH (0x0F), A
+		0x76,             //HALT
+		0x04,             //INC B
+		0x78,             //LD A, B
+		0xEA, 0x00, 0xC1, //LD (0xC100), A
+	)
+
+	assert.False(t, gbc.cpu.Halted)
+	assert.Equal(t, byte(0x02), gbc.mmu.ReadByte(0xC100))
+}
+
+func TestHaltWakesWithInterruptsDisabled(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TIMING")
+
+	//the timer overflows shortly after HALT, which should wake the CPU without
+	//servicing the interrupt
+	runProgram(gbc, 100,
+		0x3E, 0x04, //LD A, 0x04
+		0xE0, 0xFF, //LDH (0xFF), A
+		0xAF,       //XOR A
+		0xE0, 0x0F, //LDH (0x0F), A
+		0x3E, 0xF0, //LD A, 0xF0
+		0xE0, 0x05, //LDH (0x05), A
+		0x3E, 0x05, //LD A, 0x05
+		0xE0, 0x07, //LDH (0x07), A
+		0x76,       //HALT
+		0x3E, 0x42, //LD A, 0x42
+		0xEA, 0x00, 0xC1, //LD (0xC100), A
+	)
+
+	assert.False(t, gbc.cpu.Halted)
+	assert.Equal(t, byte(0x42), gbc.mmu.ReadByte(0xC100))
+	assert.Equal(t, byte(0x04), gbc.mmu.ReadByte(0xFF0F)&0x04, "interrupt should still be requested")
+}
+
+func TestEIDelaysInterruptsByOneInstruction(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TIMING")
+	gbc.cpu.SP = 0xD000
+
+	runProgram(gbc, 6,
+		0x3E, 0x04, //LD A, 0x04
+		0xE0, 0xFF, //LDH (0xFF), A
+		0xE0, 0x0F, //LDH (0x0F), A
+		0x06, 0x00, //LD B, 0x00
+		0xFB, //EI
+		0x04, //INC B
+		0x04, //INC B
+	)
+	assert.Equal(t, byte(0x01), gbc.cpu.R.B, "the instruction after EI should run before the interrupt")
+
+	//dispatch takes 5 M-cycles followed by the NOP at the timer vector
+	gbc.Step()
+	assert.Equal(t, 6, gbc.cpu.LastInstrCycle.M)
+	assert.Equal(t, types.Word(0x0051), gbc.cpu.PC)
+	assert.Equal(t, byte(0x0A), gbc.mmu.ReadByte(0xCFFE))
+	assert.Equal(t, byte(0xC2), gbc.mmu.ReadByte(0xCFFF))
+	assert.Equal(t, byte(0x00), gbc.mmu.ReadByte(0xFF0F)&0x04)
+}
+
+func TestPushToIECancelsInterruptDispatch(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TIMING")
+
+	//with SP at 0x0000 the upper byte of the PC (0xC2) is pushed to IE, which no
+	//longer enables the timer interrupt so dispatch jumps to 0x0000 instead
+	runProgram(gbc, 7,
+		0x31, 0x00, 0x00, //LD SP, 0x0000
+		0x3E, 0x04, //LD A, 0x04
+		0xE0, 0xFF, //LDH (0xFF), A
+		0xE0, 0x0F, //LDH (0x0F), A
+		0xFB, //EI
+		0x00, //NOP
+	)
+
+	assert.Equal(t, types.Word(0x0001), gbc.cpu.PC)
+	assert.Equal(t, byte(0xC2), gbc.mmu.ReadByte(0xFFFF))
+	assert.Equal(t, byte(0x04), gbc.mmu.ReadByte(0xFF0F)&0x04, "cancelled interrupt should still be requested")
+}
+
+func TestStopSwitchesSpeedWhenPrepared(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TIMING")
+
+	runProgram(gbc, 3,
+		0x3E, 0x01, //LD A, 0x01
+		0xE0, 0x4D, //LDH (0x4D), A
+		0x10, 0x00, //STOP
+	)
+
+	assert.Equal(t, 2, gbc.cpu.Speed)
+	assert.False(t, gbc.cpu.Stopped)
+	assert.True(t, gbc.cpu.LastInstrCycle.M > 2050)
+	assert.Equal(t, byte(0xFE), gbc.mmu.ReadByte(0xFF4D))
+	assert.Equal(t, byte(0x00), gbc.mmu.ReadByte(0xFF04), "DIV should not tick while switching")
+}
+
+func TestStopWaitsForJoypad(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TIMING")
+
+	runProgram(gbc, 3,
+		0x3E, 0x10, //LD A, 0x10
+		0xE0, 0x00, //LDH (0x00), A
+		0x10, 0x00, //STOP
+	)
+	for i := 0; i < 1000; i++ {
+		gbc.Step()
+	}
+	assert.True(t, gbc.cpu.Stopped)
+	assert.Equal(t, types.Word(0xC206), gbc.cpu.PC)
+	assert.Equal(t, byte(0x00), gbc.mmu.ReadByte(0xFF04), "DIV should not tick while stopped")
+
+	gbc.io.GetKeyHandler().SetButton(inputoutput.BUTTON_A, true)
+	gbc.Step()
+	assert.False(t, gbc.cpu.Stopped)
+}
+
+func TestSpeedSwitchStallRunsTheLCD(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TIMING")
+
+	//the switch stalls the CPU for SPEED_SWITCH_CYCLES M-cycles which is nearly 18 lines
+	runProgram(gbc, 7,
+		0x3E, 0x01, //LD A, 0x01
+		0xE0, 0x4D, //LDH (0x4D), A
+		0xF0, 0x44, //LDH A, (0x44)
+		0xEA, 0x00, 0xC1, //LD (0xC100), A
+		0x10, 0x00, //STOP
+		0xF0, 0x44, //LDH A, (0x44)
+		0xEA, 0x01, 0xC1, //LD (0xC101), A
+	)
+
+	assert.Equal(t, 2, gbc.cpu.Speed)
+	lines := (int(gbc.mmu.ReadByte(0xC101)) - int(gbc.mmu.ReadByte(0xC100)) + 154) % 154
+	assert.True(t, lines >= 17, "LCD moved %d lines during the switch", lines)
+}
@@ -0,0 +1,44 @@
+package gbc
+
+import (
+	"github.com/djhworld/gomeboycolor/trace"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Starts tracing every instruction the CPU executes to t, replacing any trace already
+//running. Reference logs such as gameboy-doctor's start at 0x0100 so the boot ROM
+//should be skipped when comparing against them.
+//This must not be called while a frame is being emulated, use BetweenFrames
+func (gbc *GomeboyColor) StartTrace(t *trace.Writer) {
+	gbc.tracer = t
+	gbc.cpu.LinkTracer(gbc.traceInstruction)
+}
+
+//Stops tracing and flushes what has been written, returning any error the trace hit.
+//This must not be called while a frame is being emulated, use BetweenFrames
+func (gbc *GomeboyColor) StopTrace() error {
+	if gbc.tracer == nil {
+		return nil
+	}
+	err := gbc.tracer.Flush()
+	gbc.tracer = nil
+	gbc.cpu.LinkTracer(nil)
+	return err
+}
+
+func (gbc *GomeboyColor) traceInstruction() {
+	gbc.tracer.Trace(gbc.traceState(), gbc.frameCount)
+}
+
+func (gbc *GomeboyColor) traceState() trace.State {
+	var s trace.State
+	r := gbc.cpu.R
+	s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L = r.A, r.F, r.B, r.C, r.D, r.E, r.H, r.L
+	s.SP = gbc.cpu.SP
+	s.PC = gbc.cpu.PC
+	for i := range s.PCMem {
+		s.PCMem[i] = gbc.mmu.Peek(s.PC + types.Word(i))
+	}
+	s.HasPCMem = true
+	return s
+}
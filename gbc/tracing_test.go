@@ -0,0 +1,32 @@
+package gbc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/trace"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestTraceLogsStateBeforeEachInstruction(t *testing.T) {
+	gbc := newTestGomeboyColor(t, "TRACE")
+
+	var buf bytes.Buffer
+	gbc.StartTrace(trace.NewWriter(&buf, trace.DOCTOR_FORMAT))
+	runProgram(gbc, 3,
+		0x3E, 0x42, //LD A, 0x42
+		0x06, 0x07, //LD B, 0x07
+		0x76, //HALT
+	)
+	//the CPU is halted so nothing more is traced
+	gbc.Step()
+	gbc.Step()
+	assert.Nil(t, gbc.StopTrace())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.True(t, strings.HasSuffix(lines[0], "PC:C200 PCMEM:3E,42,06,07"), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "A:42 "), lines[1])
+	assert.True(t, strings.HasSuffix(lines[2], "PC:C204 PCMEM:76,00,00,00"), lines[2])
+}
@@ -0,0 +1,456 @@
+package gpu
+
+import (
+	"log"
+
+	"github.com/djhworld/gomeboycolor/state"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//How the GPU turns VRAM into pixels
+type Renderer int
+
+const (
+	//draws each line in one go as LY changes, fast but blind to mid line changes
+	SCANLINE_RENDERER Renderer = iota
+	//steps the pixel fetcher and FIFOs dot by dot like the real PPU
+	FIFO_RENDERER
+)
+
+func (r Renderer) String() string {
+	if r == FIFO_RENDERER {
+		return "pixel FIFO"
+	}
+	return "scanline"
+}
+
+const DOTS_PER_LINE int = 456
+const OAM_SCAN_DOTS int = 80
+const MAX_SPRITES_PER_LINE int = 10
+
+//the fetcher throws away its first tile fetch of each line
+const FIRST_FETCH_DOTS int = 6
+
+//dots taken to fetch a sprite once the background fetcher is ready
+const SPRITE_FETCH_DOTS int = 6
+
+//background fetcher steps, each read takes two dots
+const (
+	FETCH_TILE = iota
+	FETCH_DATA_LOW
+	FETCH_DATA_HIGH
+	FETCH_PUSH
+)
+
+//A sprite picked by the OAM scan for the current line
+type lineSprite struct {
+	index int
+	y     int
+	x     int
+	tile  int
+	attrs byte
+}
+
+type bgPixel struct {
+	colour   int
+	palette  int
+	priority bool
+}
+
+//colour 0 is transparent
+type objPixel struct {
+	colour   int
+	palette  int
+	behindBG bool
+	oamIndex int
+}
+
+type fetcher struct {
+	step   int
+	dots   int
+	x      int
+	window bool
+	tileNo int
+	attrs  byte
+	row    [8]int
+}
+
+//State of the pixel transfer (mode 3) of the current line
+type pixelPipeline struct {
+	lx          int
+	discard     int
+	fetcher     fetcher
+	bg          [8]bgPixel
+	bgIndex     int
+	bgCount     int
+	obj         [8]objPixel
+	objHead     int
+	fetched     [MAX_SPRITES_PER_LINE]bool
+	spriteFetch int
+	spriteDots  int
+	windowDrawn bool
+}
+
+func (g *GPU) SetRenderer(r Renderer) {
+	g.renderer = r
+	log.Println(PREFIX, "Using the", r, "renderer")
+
+	//don't pick up a line half way through
+	g.pipeline.lx = DISPLAY_WIDTH
+}
+
+func (g *GPU) Renderer() Renderer {
+	return g.renderer
+}
+
+func (g *GPU) stepFIFO(t int) {
+	if !g.displayOn {
+		g.stepLCDOff(t)
+		return
+	}
+
+	for ; t > 0; t-- {
+		if g.ly < DISPLAY_HEIGHT {
+			g.lineDot(DOTS_PER_LINE - g.clock)
+		}
+
+		g.clock--
+		if g.clock <= 0 {
+			g.clock += DOTS_PER_LINE
+			g.nextLine()
+			if g.ly >= DISPLAY_HEIGHT {
+				g.mode = VBLANK
+			}
+		}
+		g.checkModeChange()
+		g.updateSTAT()
+	}
+}
+
+//Advances a visible line by one dot
+func (g *GPU) lineDot(dot int) {
+	switch dot {
+	case 0:
+		g.mode = OAMREAD
+		g.checkWindowY()
+	case OAM_SCAN_DOTS:
+		g.scanOAM()
+		g.startPixelTransfer()
+		g.mode = VRAMREAD
+	}
+
+	if g.mode != VRAMREAD {
+		return
+	}
+
+	//the last pixel goes out on the dot before HBlank starts
+	if g.pipeline.lx == DISPLAY_WIDTH {
+		g.mode = HBLANK
+		if g.pipeline.windowDrawn {
+			g.windowLine++
+		}
+		return
+	}
+	g.pixelTransferDot()
+}
+
+//Picks the first sprites in OAM order that are on the current line
+func (g *GPU) scanOAM() {
+	var height int = 8
+	if g.spriteSizeMode == Sprite8x16Mode {
+		height = 16
+	}
+
+	g.lineSpriteCount = 0
+	for i := 0; i < 40 && g.lineSpriteCount < MAX_SPRITES_PER_LINE; i++ {
+		var y int = int(g.oamRam[i*4]) - 16
+		if g.ly < y || g.ly >= y+height {
+			continue
+		}
+		g.lineSprites[g.lineSpriteCount] = lineSprite{
+			index: i,
+			y:     y,
+			x:     int(g.oamRam[i*4+1]),
+			tile:  int(g.oamRam[i*4+2]),
+			attrs: g.oamRam[i*4+3],
+		}
+		g.lineSpriteCount++
+	}
+}
+
+func (g *GPU) startPixelTransfer() {
+	p := &g.pipeline
+	*p = pixelPipeline{}
+	p.discard = int(g.scrollX) % 8
+	p.spriteFetch = -1
+	p.fetcher.dots = -FIRST_FETCH_DOTS
+}
+
+func (g *GPU) pixelTransferDot() {
+	p := &g.pipeline
+
+	if p.spriteFetch >= 0 {
+		g.spriteFetchDot()
+		return
+	}
+
+	//the window restarts the fetcher and throws away what it had fetched
+	if !p.fetcher.window && g.windowOn && g.windowYMatched && p.lx >= int(g.windowX)-7 {
+		p.fetcher = fetcher{window: true}
+		p.bgCount = 0
+		p.discard = 0
+		if g.windowX < 7 {
+			p.discard = 7 - int(g.windowX)
+		}
+		p.windowDrawn = true
+	}
+
+	if p.discard == 0 && g.spritesOn {
+		for i := 0; i < g.lineSpriteCount; i++ {
+			s := &g.lineSprites[i]
+			//sprites at X 0 are off screen and never fetched
+			if !p.fetched[i] && s.x > 0 && maxInt(s.x-8, 0) == p.lx {
+				p.spriteFetch = i
+				p.spriteDots = 0
+				g.spriteFetchDot()
+				return
+			}
+		}
+	}
+
+	g.fetcherDot()
+
+	if p.bgCount == 0 {
+		return
+	}
+	bg := p.bg[p.bgIndex]
+	p.bgIndex++
+	p.bgCount--
+
+	//fine scroll is done by dropping pixels at the start of the line
+	if p.discard > 0 {
+		p.discard--
+		return
+	}
+
+	obj := p.obj[p.objHead]
+	p.obj[p.objHead] = objPixel{}
+	p.objHead = (p.objHead + 1) % 8
+
+	g.outputPixel(p.lx, bg, obj)
+	p.lx++
+}
+
+//The background fetcher has to finish the tile it is on before a sprite can be fetched
+func (g *GPU) spriteFetchDot() {
+	p := &g.pipeline
+	if p.spriteDots == 0 {
+		if p.fetcher.step != FETCH_PUSH {
+			g.fetcherDot()
+			return
+		}
+		if p.bgCount == 0 {
+			g.fetcherDot()
+		}
+	}
+
+	p.spriteDots++
+	if p.spriteDots == SPRITE_FETCH_DOTS {
+		g.mergeSprite(g.lineSprites[p.spriteFetch])
+		p.fetched[p.spriteFetch] = true
+		p.spriteFetch = -1
+	}
+}
+
+func (g *GPU) fetcherDot() {
+	p := &g.pipeline
+	f := &p.fetcher
+
+	if f.step == FETCH_PUSH {
+		if p.bgCount == 0 {
+			for i, colour := range f.row {
+				p.bg[i] = bgPixel{colour: colour, palette: int(f.attrs & 0x07), priority: f.attrs&0x80 == 0x80}
+			}
+			p.bgIndex, p.bgCount = 0, 8
+			f.x++
+			f.step, f.dots = FETCH_TILE, 0
+		}
+		return
+	}
+
+	f.dots++
+	if f.dots < 2 {
+		return
+	}
+	f.dots = 0
+
+	switch f.step {
+	case FETCH_TILE:
+		g.fetchTileNo()
+	case FETCH_DATA_HIGH:
+		g.fetchTileRow()
+	}
+	f.step++
+}
+
+//Reads the tile number (and its CGB attributes) using the scroll registers as they are now
+func (g *GPU) fetchTileNo() {
+	f := &g.pipeline.fetcher
+	var addr types.Word
+	if f.window {
+		addr = g.windowTilemap + types.Word(g.windowLine/8*32+f.x%32)
+	} else {
+		var y int = (g.ly + int(g.scrollY)) % 256
+		addr = g.bgTilemap + types.Word(y/8*32+(int(g.scrollX)/8+f.x)%32)
+	}
+
+	f.tileNo = int(g.vram[0][addr&0x1FFF])
+	if g.tileDataSelect == TILEDATA0 && f.tileNo < 128 {
+		f.tileNo += 256
+	}
+
+	f.attrs = 0
+	if g.RunningColorGBHardware {
+		f.attrs = g.vram[1][addr&0x1FFF]
+	}
+}
+
+func (g *GPU) fetchTileRow() {
+	f := &g.pipeline.fetcher
+	var tileY int = (g.ly + int(g.scrollY)) % 8
+	if f.window {
+		tileY = g.windowLine % 8
+	}
+	var bank int = int(f.attrs&0x08) >> 3
+	formatTileLine(&g.tiledata[bank][f.tileNo], tileY, f.attrs&0x20 == 0x20, f.attrs&0x40 == 0x40, &f.row)
+}
+
+//Mixes a sprite into the sprite FIFO. On DMG the first sprite fetched keeps a pixel,
+//on CGB the sprite earliest in OAM does
+func (g *GPU) mergeSprite(s lineSprite) {
+	p := &g.pipeline
+
+	line, palette := g.spriteTileLine(s)
+
+	//sprites hanging off the left edge lose their first pixels
+	var start int = 0
+	if s.x < 8 {
+		start = 8 - s.x
+	}
+	for i := start; i < 8; i++ {
+		if line[i] == 0 {
+			continue
+		}
+		slot := &p.obj[(p.objHead+i-start)%8]
+		if slot.colour == 0 || (g.RunningColorGBHardware && s.index < slot.oamIndex) {
+			*slot = objPixel{colour: line[i], palette: palette, behindBG: s.attrs&0x80 == 0x80, oamIndex: s.index}
+		}
+	}
+}
+
+func (g *GPU) outputPixel(x int, bg bgPixel, obj objPixel) {
+	if !g.spritesOn {
+		obj.colour = 0
+	}
+
+	if g.RunningColorGBHardware {
+		//on CGB LCDC bit 0 takes priority away from the background instead of hiding it
+		if obj.colour != 0 && (!g.bgrdOn || bg.colour == 0 || (!bg.priority && !obj.behindBG)) {
+			g.screenData[g.ly][x] = g.cgbObjectPalettes[obj.palette][obj.colour].ToRGB()
+		} else {
+			g.screenData[g.ly][x] = g.cgbBackgroundPalettes[bg.palette][bg.colour].ToRGB()
+		}
+		g.rawScreenDotData[g.ly][x] = bg.colour
+		return
+	}
+
+	if !g.bgrdOn {
+		bg.colour = 0
+	}
+	switch {
+	case obj.colour != 0 && (bg.colour == 0 || !obj.behindBG):
+		g.screenData[g.ly][x] = g.objectPalettes[obj.palette][obj.colour]
+	case !g.bgrdOn:
+		g.screenData[g.ly][x] = g.dmgColours[0][0]
+	default:
+		g.screenData[g.ly][x] = g.bgPalette[bg.colour]
+	}
+	g.rawScreenDotData[g.ly][x] = bg.colour
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (p *pixelPipeline) saveState(s *state.Writer) {
+	s.Int(p.lx)
+	s.Int(p.discard)
+	s.Int(p.fetcher.step)
+	s.Int(p.fetcher.dots)
+	s.Int(p.fetcher.x)
+	s.Bool(p.fetcher.window)
+	s.Int(p.fetcher.tileNo)
+	s.Byte(p.fetcher.attrs)
+	for _, colour := range p.fetcher.row {
+		s.Byte(byte(colour))
+	}
+	for _, px := range p.bg {
+		s.Byte(byte(px.colour))
+		s.Byte(byte(px.palette))
+		s.Bool(px.priority)
+	}
+	s.Int(p.bgIndex)
+	s.Int(p.bgCount)
+	for _, px := range p.obj {
+		s.Byte(byte(px.colour))
+		s.Byte(byte(px.palette))
+		s.Bool(px.behindBG)
+		s.Int(px.oamIndex)
+	}
+	s.Int(p.objHead)
+	for _, fetched := range p.fetched {
+		s.Bool(fetched)
+	}
+	s.Int(p.spriteFetch)
+	s.Int(p.spriteDots)
+	s.Bool(p.windowDrawn)
+}
+
+func (p *pixelPipeline) loadState(s *state.Reader) {
+	p.lx = s.Int()
+	p.discard = s.Int()
+	p.fetcher.step = s.Int()
+	p.fetcher.dots = s.Int()
+	p.fetcher.x = s.Int()
+	p.fetcher.window = s.Bool()
+	p.fetcher.tileNo = s.Int()
+	p.fetcher.attrs = s.Byte()
+	for i := range p.fetcher.row {
+		p.fetcher.row[i] = int(s.Byte() & 0x03)
+	}
+	for i := range p.bg {
+		p.bg[i] = bgPixel{colour: int(s.Byte() & 0x03), palette: int(s.Byte() & 0x07), priority: s.Bool()}
+	}
+	p.bgIndex = s.Int()
+	p.bgCount = s.Int()
+	for i := range p.obj {
+		p.obj[i] = objPixel{colour: int(s.Byte() & 0x03), palette: int(s.Byte() & 0x07), behindBG: s.Bool(), oamIndex: s.Int()}
+	}
+	p.objHead = s.Int()
+	for i := range p.fetched {
+		p.fetched[i] = s.Bool()
+	}
+	p.spriteFetch = s.Int()
+	p.spriteDots = s.Int()
+	p.windowDrawn = s.Bool()
+
+	if p.lx < 0 || p.lx > DISPLAY_WIDTH || p.fetcher.step < FETCH_TILE || p.fetcher.step > FETCH_PUSH ||
+		p.fetcher.tileNo < 0 || p.fetcher.tileNo > 511 || p.bgIndex < 0 || p.bgCount < 0 || p.bgIndex+p.bgCount > 8 ||
+		p.objHead < 0 || p.objHead > 7 || p.spriteFetch < -1 || p.spriteFetch >= MAX_SPRITES_PER_LINE {
+		s.Invalid("pixel FIFO state is out of range")
+		*p = pixelPipeline{lx: DISPLAY_WIDTH, spriteFetch: -1}
+	}
+}
@@ -0,0 +1,77 @@
+package gpu
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+type testIRQHandler struct {
+	requested []byte
+}
+
+func (h *testIRQHandler) RequestInterrupt(interrupt byte) {
+	h.requested = append(h.requested, interrupt)
+}
+
+func newTestFIFOGPU() *GPU {
+	g := NewGPU()
+	g.SetRenderer(FIFO_RENDERER)
+	g.LinkIRQHandler(new(testIRQHandler))
+	g.LinkScreen(make(chan *types.Screen, 1))
+	g.Write(BGP, 0xE4)
+	//the LCD starts off, as after the boot ROM
+	g.Step(1)
+	g.Write(LCDC, 0x93)
+	return g
+}
+
+//Runs the first line of a frame and returns how many dots mode 3 took
+func mode3Length(g *GPU) int {
+	var dots int
+	for g.ly == 0 {
+		g.Step(1)
+		if g.mode == VRAMREAD {
+			dots++
+		}
+	}
+	return dots
+}
+
+func TestFIFOMode3Length(t *testing.T) {
+	g := newTestFIFOGPU()
+	assert.Equal(t, 172, mode3Length(g))
+
+	//fine scroll pixels are fetched and thrown away
+	g = newTestFIFOGPU()
+	g.Write(SCROLLX, 0x03)
+	assert.Equal(t, 175, mode3Length(g))
+
+	//the window restarts the fetcher
+	g = newTestFIFOGPU()
+	g.Write(LCDC, 0xB3)
+	g.Write(WX, 87)
+	assert.Equal(t, 178, mode3Length(g))
+
+	//a sprite stalls the pixel transfer while it is fetched
+	g = newTestFIFOGPU()
+	g.Write(0xFE00, 16)
+	g.Write(0xFE01, 48)
+	length := mode3Length(g)
+	assert.True(t, length >= 172+6 && length <= 172+11, fmt.Sprint(length))
+}
+
+func TestFIFOShowsMidLineChanges(t *testing.T) {
+	g := newTestFIFOGPU()
+	g.Write(BGP, 0x00)
+	for g.mode != VRAMREAD || g.pipeline.lx < 80 {
+		g.Step(1)
+	}
+	g.Write(BGP, 0x03)
+	mode3Length(g)
+
+	assert.Equal(t, GreyPalette[0], g.screenData[0][0])
+	assert.Equal(t, GreyPalette[3], g.screenData[0][DISPLAY_WIDTH-1])
+}
@@ -37,19 +37,15 @@ const (
 	WY                         = 0xFF4A
 )
 
-const HBLANK byte = 0x00
-const VBLANK byte = 0x01
-const OAMREAD byte = 0x02
-const VRAMREAD byte = 0x03
+const HBLANK byte = constants.LCD_MODE_HBLANK
+const VBLANK byte = constants.LCD_MODE_VBLANK
+const OAMREAD byte = constants.LCD_MODE_OAM
+const VRAMREAD byte = constants.LCD_MODE_VRAM
 const Sprite8x16Mode byte = 0
 const Sprite8x8Mode byte = 1
 
-var GBColours []types.RGB = []types.RGB{
-	types.RGB{Red: 235, Green: 235, Blue: 235},
-	types.RGB{Red: 196, Green: 196, Blue: 196},
-	types.RGB{Red: 96, Green: 96, Blue: 96},
-	types.RGB{Red: 0, Green: 0, Blue: 0},
-}
+//dots into line 153 before LY reads 0
+const LINE_153_LY_DOTS int = 4
 
 type RawTile [16]byte
 type Tile [8][8]int
@@ -60,10 +56,12 @@ type GPU struct {
 	rawScreenDotData      [144][160]int
 	screenOutputChannel   chan *types.Screen
 	irqHandler            components.IRQHandler
+	modeChangeHandler     func(mode byte)
+	reportedMode          byte
 	vram                  [2][8192]byte
 	oamRam                [160]byte
 	vBlankInterruptThrown bool
-	lcdInterruptThrown    bool
+	statLine              bool
 
 	mode                         byte
 	clock                        int
@@ -98,6 +96,8 @@ type GPU struct {
 
 	bgPalette      Palette
 	objectPalettes [2]Palette
+	//shades BGP, OBP0 and OBP1 pick from on DMG hardware
+	dmgColours [3]Palette
 
 	cgbBackgroundPalettes             [8]CGBPalette
 	cgbObjectPalettes                 [8]CGBPalette
@@ -106,10 +106,23 @@ type GPU struct {
 	cgbOBJPWriteSpecReg               CGBPaletteSpecRegister
 	cgbOBJPWriteDataRegister          byte
 	cgbScreenPixelBackgroundTileAttrs [144][160]*CGBBackgroundTileAttrs
+
+	renderer        Renderer
+	pipeline        pixelPipeline
+	lineSprites     [MAX_SPRITES_PER_LINE]lineSprite
+	lineSpriteCount int
+	windowLine      int
+	windowYMatched  bool
+
+	lcdStarting bool
+	blankFrame  bool
+	offDots     int
 }
 
 func NewGPU() *GPU {
 	var g *GPU = new(GPU)
+	var palette Palette = DefaultPalette()
+	g.dmgColours = [3]Palette{palette, palette, palette}
 	g.Reset()
 	return g
 }
@@ -124,6 +137,12 @@ func (g *GPU) LinkIRQHandler(m components.IRQHandler) {
 	log.Println(PREFIX, "Linked IRQ Handler to GPU")
 }
 
+//f is called whenever the LCD mode changes, turning the display off changes it to HBlank
+func (g *GPU) LinkModeChangeHandler(f func(mode byte)) {
+	g.modeChangeHandler = f
+	log.Println(PREFIX, "Linked mode change handler to GPU")
+}
+
 func (g *GPU) Name() string {
 	return NAME
 }
@@ -133,11 +152,16 @@ func (g *GPU) Reset() {
 	g.Write(LCDC, 0x00)
 	g.screenData = *new(types.Screen)
 	g.rawScreenDotData = *new([144][160]int)
+	g.vram = *new([2][8192]byte)
+	g.oamRam = *new([160]byte)
+	g.rawTiledata = *new([2][512]RawTile)
+	g.tiledata = *new([2][512]Tile)
 	g.mode = 0
+	g.reportedMode = 0
 	g.ly = 0
 	g.clock = 0
 	g.vBlankInterruptThrown = false
-	g.lcdInterruptThrown = false
+	g.statLine = false
 	g.RunningColorGBHardware = false
 
 	for i := 0; i < 40; i++ {
@@ -150,100 +174,151 @@ func (g *GPU) Reset() {
 	g.cgbBackgroundPalettes = *new([8]CGBPalette)
 	g.cgbObjectPalettes = *new([8]CGBPalette)
 	g.currentTileLineDotData = new([8]int)
+
+	g.pipeline = pixelPipeline{lx: DISPLAY_WIDTH, spriteFetch: -1}
+	g.lineSpriteCount = 0
+	g.windowLine = 0
+	g.windowYMatched = false
+	g.lcdStarting = false
+	g.blankFrame = false
+	g.offDots = 0
 }
 
 func (g *GPU) Step(t int) {
+	if g.renderer == FIFO_RENDERER {
+		g.stepFIFO(t)
+		return
+	}
+
 	if !g.displayOn {
-		g.ly = 0
-		g.clock = 456
-		g.mode = HBLANK
-	} else {
-		if g.ly >= 144 {
-			g.mode = VBLANK
-			g.lcdInterruptThrown = false
-		} else if g.clock >= 456-80 {
+		g.stepLCDOff(t)
+		return
+	}
+
+	if g.ly >= 144 {
+		g.mode = VBLANK
+	} else if g.clock >= 456-80 {
+		if !g.lcdStarting {
 			g.mode = OAMREAD
-			g.lcdInterruptThrown = false
-		} else if g.clock >= 456-80-172 {
-			g.mode = VRAMREAD
-			g.lcdInterruptThrown = false
-		} else {
-			g.mode = HBLANK
-			//throw HBlank LCD interrupt (if enabled)
-			if g.HblankLCDInterruptEnabled() && g.lcdInterruptThrown == false {
-				g.irqHandler.RequestInterrupt(constants.LCD_IRQ)
-				g.lcdInterruptThrown = true
-			}
 		}
+	} else if g.clock >= 456-80-172 {
+		g.mode = VRAMREAD
+	} else {
+		g.mode = HBLANK
 	}
 
-	if !g.displayOn {
-		return
+	//the line is drawn as the transfer to the LCD finishes so that registers changed
+	//earlier in the line (e.g. from an LYC interrupt) are used for it
+	if g.mode == HBLANK && g.reportedMode == VRAMREAD {
+		g.renderScanline()
 	}
 
 	g.clock -= t
 
 	if g.clock <= 0 {
 		g.clock += 456
-		g.ly += 1
+		g.nextLine()
 
-		if g.ly == 144 {
-			//reset sprite draw queues after frame has been rendered
-			for _, s := range g.sprites8x8 {
-				s.ResetScanlineDrawQueue()
-			}
-
-			for _, s := range g.sprites8x16 {
-				s.ResetScanlineDrawQueue()
-			}
+		if g.ly < 144 {
+			g.checkWindowY()
+		}
+	}
 
-			//throw vblank interrupt
-			if g.vBlankInterruptThrown == false {
-				g.irqHandler.RequestInterrupt(constants.V_BLANK_IRQ)
+	g.checkModeChange()
+	g.updateSTAT()
+}
 
-				//throw VBLANK LCD interrupt (if enabled)
-				if g.VBlankLCDInterruptEnabled() {
-					g.irqHandler.RequestInterrupt(constants.LCD_IRQ)
-				}
-				g.vBlankInterruptThrown = true
-			}
+func (g *GPU) renderScanline() {
+	//on CGB LCDC bit 0 only takes priority away from the background
+	if g.bgrdOn || g.RunningColorGBHardware {
+		g.RenderBackgroundScanline()
 
-			//dump output to screen controller over a channel
-			g.screenOutputChannel <- &g.screenData
-		} else if g.ly > 153 {
-			g.vBlankInterruptThrown = false
-			g.ly = 0
+		if g.windowOn {
+			g.RenderWindowScanline()
 		}
+	} else {
+		g.BlankScanline()
+	}
 
-		//throw coincidence LCD interrupt (if enabled)
-		if g.CoincidenceLCDInterruptEnabled() && byte(g.ly) == g.lyc {
-			g.stat |= 0x04
-			g.irqHandler.RequestInterrupt(constants.LCD_IRQ)
+	if g.spritesOn {
+		g.RenderSpritesOnScanline()
+	}
+}
+
+func (g *GPU) checkModeChange() {
+	if g.mode != g.reportedMode {
+		g.reportedMode = g.mode
+		g.lcdStarting = false
+		if g.modeChangeHandler != nil {
+			g.modeChangeHandler(g.mode)
 		}
+	}
+}
 
-		//Render scanline
-		if g.ly < 144 {
-			if g.displayOn {
-				if g.bgrdOn {
-					g.RenderBackgroundScanline()
-				}
+//Moves on to the next line, raising the VBlank interrupt and handing the finished
+//frame to the screen
+func (g *GPU) nextLine() {
+	g.ly += 1
 
-				if g.windowOn {
-					g.RenderWindowScanline()
-				}
+	if g.ly == 144 {
+		//throw vblank interrupt
+		if g.vBlankInterruptThrown == false {
+			g.irqHandler.RequestInterrupt(constants.V_BLANK_IRQ)
+			g.vBlankInterruptThrown = true
+		}
 
-				if g.spritesOn {
-					g.RenderSpritesOnScanline()
-				}
-			}
+		//the first frame after the LCD is turned on is never shown
+		if g.blankFrame {
+			g.fillScreen(g.blankColour())
+			g.blankFrame = false
 		}
+
+		//dump output to screen controller over a channel
+		g.screenOutputChannel <- &g.screenData
+	} else if g.ly > 153 {
+		g.vBlankInterruptThrown = false
+		g.ly = 0
+	}
+}
+
+//LY already reads 0 a few dots into line 153, so an LYC of 0 matches twice a frame
+func (g *GPU) lyRegister() int {
+	if g.ly == 153 && g.clock <= 456-LINE_153_LY_DOTS {
+		return 0
 	}
+	return g.ly
+}
+
+//The four STAT interrupt sources are ORed onto one line and the LCD interrupt is only
+//raised when that line goes high, so a source that is already holding it high blocks
+//the others
+func (g *GPU) updateSTAT() {
+	var coincidence bool = byte(g.lyRegister()) == g.lyc
+	if coincidence {
+		g.stat |= 0x04
+	} else {
+		g.stat &^= 0x04
+	}
+
+	var line bool = (coincidence && g.CoincidenceLCDInterruptEnabled()) ||
+		(g.mode == HBLANK && g.HblankLCDInterruptEnabled() && !g.lcdStarting) ||
+		(g.mode == VBLANK && g.VBlankLCDInterruptEnabled()) ||
+		(g.mode == OAMREAD && g.OAMLCDInterruptEnabled())
+
+	if line && !g.statLine {
+		g.irqHandler.RequestInterrupt(constants.LCD_IRQ)
+	}
+	g.statLine = line
 }
 
 func (g *GPU) CoincidenceLCDInterruptEnabled() bool {
 	return (g.Read(STAT) & 0x40) == 0x40
 }
 
+func (g *GPU) OAMLCDInterruptEnabled() bool {
+	return (g.Read(STAT) & 0x20) == 0x20
+}
+
 func (g *GPU) VBlankLCDInterruptEnabled() bool {
 	return (g.Read(STAT) & 0x10) == 0x10
 }
@@ -252,51 +327,66 @@ func (g *GPU) HblankLCDInterruptEnabled() bool {
 	return (g.Read(STAT) & 0x08) == 0x08
 }
 
+//Decodes the LCDC register, turning the display on or off is handled by the caller
+func (g *GPU) setLCDC(value byte) {
+	g.lcdc = value
+
+	g.displayOn = value&0x80 == 0x80 //bit 7
+
+	if value&0x40 == 0x40 { //bit 6
+		g.windowTilemap = TILEMAP1
+	} else {
+		g.windowTilemap = TILEMAP0
+	}
+
+	g.windowOn = value&0x20 == 0x20 //bit 5
+
+	if value&0x10 == 0x10 { //bit 4
+		g.tileDataSelect = TILEDATA1
+	} else {
+		g.tileDataSelect = TILEDATA0
+	}
+
+	if value&0x08 == 0x08 { //bit 3
+		g.bgTilemap = TILEMAP1
+	} else {
+		g.bgTilemap = TILEMAP0
+	}
+
+	if value&0x04 == 0x04 { //bit 2
+		g.spriteSizeMode = Sprite8x16Mode
+	} else {
+		g.spriteSizeMode = Sprite8x8Mode
+	}
+
+	g.spritesOn = value&0x02 == 0x02 //bit 1
+	g.bgrdOn = value&0x01 == 0x01    //bit 0
+}
+
 //Called from mmu
 func (g *GPU) Write(addr types.Word, value byte) {
 	switch {
 	case addr >= 0x8000 && addr <= 0x9FFF:
 		g.WriteToVideoRAM(addr, value)
 	case addr >= 0xFE00 && addr <= 0xFE9F:
-		g.oamRam[addr&0x009F] = value
+		g.oamRam[addr-0xFE00] = value
 		g.UpdateSprite(addr, value)
 	default:
 		switch addr {
 		case LCDC:
-			g.lcdc = value
-
-			g.displayOn = value&0x80 == 0x80 //bit 7
-
-			if value&0x40 == 0x40 { //bit 6
-				g.windowTilemap = TILEMAP1
-			} else {
-				g.windowTilemap = TILEMAP0
-			}
-
-			g.windowOn = value&0x20 == 0x20 //bit 5
-
-			if value&0x10 == 0x10 { //bit 4
-				g.tileDataSelect = TILEDATA1
-			} else {
-				g.tileDataSelect = TILEDATA0
+			var wasOn bool = g.displayOn
+			g.setLCDC(value)
+			if g.displayOn && !wasOn {
+				g.turnLCDOn()
+			} else if !g.displayOn && wasOn {
+				g.turnLCDOff()
 			}
-
-			if value&0x08 == 0x08 { //bit 3
-				g.bgTilemap = TILEMAP1
-			} else {
-				g.bgTilemap = TILEMAP0
-			}
-
-			if value&0x04 == 0x04 { //bit 2
-				g.spriteSizeMode = Sprite8x16Mode
-			} else {
-				g.spriteSizeMode = Sprite8x8Mode
-			}
-
-			g.spritesOn = value&0x02 == 0x02 //bit 1
-			g.bgrdOn = value&0x01 == 0x01    //bit 0
 		case STAT:
-			g.stat = (g.stat & 0x0F) | value
+			//the mode and coincidence bits are read only
+			g.stat = (g.stat & 0x07) | (value & 0x78)
+			if g.displayOn {
+				g.updateSTAT()
+			}
 		case SCROLLY:
 			g.scrollY = value
 		case SCROLLX:
@@ -309,15 +399,18 @@ func (g *GPU) Write(addr types.Word, value byte) {
 			g.ly = 0
 		case LYC:
 			g.lyc = value
+			if g.displayOn {
+				g.updateSTAT()
+			}
 		case BGP:
 			g.bgp = value
-			g.bgPalette = g.byteToPalette(value)
+			g.bgPalette = byteToPalette(value, g.dmgColours[0])
 		case OBJECTPALETTE_0:
 			g.obp0 = value
-			g.objectPalettes[0] = g.byteToPalette(value)
+			g.objectPalettes[0] = byteToPalette(value, g.dmgColours[1])
 		case OBJECTPALETTE_1:
 			g.obp1 = value
-			g.objectPalettes[1] = g.byteToPalette(value)
+			g.objectPalettes[1] = byteToPalette(value, g.dmgColours[2])
 		case CGB_BGP_WRITESPEC_REGISTER:
 			g.cgbBGPWriteSpecReg.Update(value)
 		case CGB_BGP_WRITEDATA_REGISTER:
@@ -358,19 +451,20 @@ func (g *GPU) Read(addr types.Word) byte {
 	case addr >= 0x8000 && addr <= 0x9FFF:
 		return g.ReadFromVideoRAM(addr)
 	case addr >= 0xFE00 && addr <= 0xFE9F:
-		return g.oamRam[addr&0x009F]
+		return g.oamRam[addr-0xFE00]
 	default:
 		switch addr {
 		case LCDC:
 			return g.lcdc
 		case STAT:
-			return (g.mode | g.stat&0xF8)
+			//bit 7 is unused and always reads 1
+			return 0x80 | g.stat&0x7C | g.mode
 		case SCROLLY:
 			return g.scrollY
 		case SCROLLX:
 			return g.scrollX
 		case LY:
-			return byte(g.ly)
+			return byte(g.lyRegister())
 		case LYC:
 			return g.lyc
 		case BGP:
@@ -484,19 +578,37 @@ func (g *GPU) RenderBackgroundScanline() {
 	g.DrawScanline(initialTilemapOffset, initialLineOffset, 0, initialTileX, initialTileY)
 }
 
+//The window keeps its own line counter that only moves on when a window line is drawn,
+//so hiding it part way down the screen doesn't skip any of it
 func (g *GPU) RenderWindowScanline() {
-	screenYAdjusted := g.ly - int(g.windowY)
+	//WX past 166 puts the window off the right edge of the screen
+	if !g.windowYMatched || g.windowX > 166 {
+		return
+	}
+
+	var initialTilemapOffset types.Word = g.windowTilemap + types.Word(g.windowLine)/8*32
+	var screenX int = int(g.windowX) - 7
+	var tileX int = 0
 
-	if (g.windowX >= 0 && g.windowX < 167) && (g.windowY >= 0 && g.windowY < 144) && screenYAdjusted >= 0 {
-		var initialTilemapOffset types.Word = g.windowTilemap + types.Word(screenYAdjusted)/8*32
-		var initialLineOffset types.Word = 0
-		var screenXAdjusted int = int((g.windowX - 7) % 255)
+	//with WX below 7 the window starts part way into its first tile
+	if screenX < 0 {
+		tileX = -screenX
+		screenX = 0
+	}
 
-		//find where in the tile we are
-		initialTileX := screenXAdjusted % 8
-		initialTileY := screenYAdjusted % 8
+	g.DrawScanline(initialTilemapOffset, 0, screenX, tileX, g.windowLine%8)
+	g.windowLine++
+}
 
-		g.DrawScanline(initialTilemapOffset, initialLineOffset, screenXAdjusted, initialTileX, initialTileY)
+//WY is compared with LY at the start of each line, once they have matched the window
+//can be shown for the rest of the frame even if WY changes
+func (g *GPU) checkWindowY() {
+	if g.ly == 0 {
+		g.windowLine = 0
+		g.windowYMatched = false
+	}
+	if g.ly == int(g.windowY) {
+		g.windowYMatched = true
 	}
 }
 
@@ -592,131 +704,105 @@ func (g *GPU) getCGBBackgroundTileAttrs(tilemapOffset types.Word, lineOffset typ
 	return -1, nil
 }
 
+//Draws the sprites picked by the OAM scan for the current line. Where sprites overlap
+//the one with the smallest X wins on DMG, on CGB the one earliest in OAM wins
 func (g *GPU) RenderSpritesOnScanline() {
-	if g.spriteSizeMode == Sprite8x8Mode {
-		for _, sprite := range g.sprites8x8 {
-			if sprite.SpriteAttributes().X != 0x00 && sprite.SpriteAttributes().Y != 0x00 {
-				//if sprite is on current scanline, then it needs to be drawn over the next
-				//8 lines, so these are added to a queue
-				if sprite.SpriteAttributes().Y-16 <= 0 {
-					sprite.PushScanlines(g.ly, 8)
-				} else if sprite.SpriteAttributes().Y-16 == g.ly {
-					sprite.PushScanlines(g.ly, 8)
-				}
-
-				if sprite.IsScanlineDrawQueueEmpty() == false {
-					//if next scanline == LY, then draw the line
-					if scanline, tileLine := sprite.PopScanline(); scanline == g.ly {
-						g.DrawSpriteTileLine(sprite, sprite.GetTileID(0), 0, tileLine)
-					}
-				}
+	g.scanOAM()
+
+	var sprites [MAX_SPRITES_PER_LINE]lineSprite = g.lineSprites
+	if !g.RunningColorGBHardware {
+		//insertion sort keeps sprites with the same X in OAM order
+		for i := 1; i < g.lineSpriteCount; i++ {
+			for j := i; j > 0 && sprites[j].x < sprites[j-1].x; j-- {
+				sprites[j], sprites[j-1] = sprites[j-1], sprites[j]
 			}
 		}
-	} else {
-		for _, sprite := range g.sprites8x16 {
-			if sprite.SpriteAttributes().X != 0x00 && sprite.SpriteAttributes().Y != 0x00 {
-				//if sprite is on current scanline, then it needs to be drawn over the next
-				//16 lines, so these are added to a queue
-				if sprite.SpriteAttributes().Y-16 <= 0 {
-					sprite.PushScanlines(g.ly, 16)
-				} else if sprite.SpriteAttributes().Y-16 == g.ly {
-					sprite.PushScanlines(g.ly, 16)
-				}
+	}
 
-				if sprite.IsScanlineDrawQueueEmpty() == false {
-					//if next scanline == LY, then draw the line
-					if scanline, tileLine := sprite.PopScanline(); scanline == g.ly {
-						if sprite.SpriteAttributes().ShouldFlipVertically {
-							if tileLine < 8 {
-								g.DrawSpriteTileLine(sprite, sprite.GetTileID(1), 0, tileLine) //draw second portion of sprite using next tile 8 lines down
-							} else {
-								g.DrawSpriteTileLine(sprite, sprite.GetTileID(0), 8, tileLine-8)
-							}
-						} else {
-							if tileLine < 8 {
-								g.DrawSpriteTileLine(sprite, sprite.GetTileID(0), 0, tileLine)
-							} else {
-								g.DrawSpriteTileLine(sprite, sprite.GetTileID(1), 8, tileLine-8) //draw second portion of sprite using next tile 8 lines down
-							}
-						}
-					}
-				}
+	var line [DISPLAY_WIDTH]objPixel
+	for _, s := range sprites[:g.lineSpriteCount] {
+		pixels, palette := g.spriteTileLine(s)
+		for i, colour := range pixels {
+			var x int = s.x - 8 + i
+			if colour == 0 || x < 0 || x >= DISPLAY_WIDTH || line[x].colour != 0 {
+				continue
 			}
+			line[x] = objPixel{colour: colour, palette: palette, behindBG: s.attrs&0x80 == 0x80, oamIndex: s.index}
 		}
 	}
-}
 
-//TODO: Sprite precedence rules
-// Draws a tile for the given sprite. Only draws one tile
-func (g *GPU) DrawSpriteTileLine(s Sprite, tileId, screenYOffset, tileY int) {
-	if g.RunningColorGBHardware {
-		g.drawCGBSpriteTileLine(s, tileId, screenYOffset, tileY)
-	} else {
-		g.drawNonCGBSpriteTileLine(s, tileId, screenYOffset, tileY)
-	}
-}
-
-func (g *GPU) drawCGBSpriteTileLine(s Sprite, tileId, screenYOffset, tileY int) {
-	if s.SpriteAttributes().X >= 0 && s.SpriteAttributes().Y >= 0 {
-		//tile data can come from one of two banks in CGB mode
-		var t *Tile = &g.tiledata[s.SpriteAttributes().CGBBankNo][tileId]
-		formatTileLine(t, tileY, s.SpriteAttributes().ShouldFlipHorizontally, s.SpriteAttributes().ShouldFlipVertically, g.currentTileLineDotData)
-
-		sx, sy := s.SpriteAttributes().X-8, s.SpriteAttributes().Y-16
-		for tileX := 0; tileX < 8; tileX++ {
-			if g.currentTileLineDotData[tileX] != 0 {
-				adjX, adjY := sx+tileX, sy+tileY+screenYOffset
-				if (adjY < DISPLAY_HEIGHT && adjY >= 0) && (adjX < DISPLAY_WIDTH && adjX >= 0) {
-					//if background tile has priority then skip drawing this sprite pixel
-
-					if g.bgrdOn {
-						bgDotData := g.rawScreenDotData[adjY][adjX]
-						objDotData := g.currentTileLineDotData[tileX]
-						result := calculateObjToBackgroundPriority(g.cgbScreenPixelBackgroundTileAttrs[adjY][adjX].HasPriority, s.SpriteAttributes().SpriteHasPriority, bgDotData, objDotData)
-						if result != OBJ_PRIORITY {
-							continue
-						}
-					}
+	for x, obj := range line {
+		if obj.colour == 0 {
+			continue
+		}
 
-					g.screenData[adjY][adjX] = g.cgbObjectPalettes[s.SpriteAttributes().CGBPaletteNo][g.currentTileLineDotData[tileX]].ToRGB()
-				}
+		var bgDotData int = g.rawScreenDotData[g.ly][x]
+		if g.RunningColorGBHardware {
+			attrs := g.cgbScreenPixelBackgroundTileAttrs[g.ly][x]
+			if g.bgrdOn && attrs != nil && calculateObjToBackgroundPriority(attrs.HasPriority, !obj.behindBG, bgDotData, obj.colour) != OBJ_PRIORITY {
+				continue
+			}
+			g.screenData[g.ly][x] = g.cgbObjectPalettes[obj.palette][obj.colour].ToRGB()
+		} else {
+			//sprites behind the background only show through colour 0
+			if obj.behindBG && bgDotData != 0 {
+				continue
 			}
+			g.screenData[g.ly][x] = g.objectPalettes[obj.palette][obj.colour]
 		}
 	}
 }
 
-func (g *GPU) drawNonCGBSpriteTileLine(s Sprite, tileId, screenYOffset, tileY int) {
-	if s.SpriteAttributes().X >= 0 && s.SpriteAttributes().Y >= 0 {
-		//tile data in non CGB mode only comes from bank 0
-		var t *Tile = &g.tiledata[0][tileId]
-		formatTileLine(t, tileY, s.SpriteAttributes().ShouldFlipHorizontally, s.SpriteAttributes().ShouldFlipVertically, g.currentTileLineDotData)
+//Returns the pixels of a sprite on the current line, already flipped, and its palette
+func (g *GPU) spriteTileLine(s lineSprite) ([8]int, int) {
+	var height int = 8
+	if g.spriteSizeMode == Sprite8x16Mode {
+		height = 16
+	}
+	var row int = g.ly - s.y
+	if s.attrs&0x40 == 0x40 {
+		row = height - 1 - row
+	}
+	var tile int = s.tile
+	if height == 16 {
+		tile = (tile & 0xFE) | row/8
+	}
 
-		sx, sy := s.SpriteAttributes().X-8, s.SpriteAttributes().Y-16
-		for tileX := 0; tileX < 8; tileX++ {
-			if g.currentTileLineDotData[tileX] != 0 {
-				adjX, adjY := sx+tileX, sy+tileY+screenYOffset
-				if (adjY < DISPLAY_HEIGHT && adjY >= 0) && (adjX < DISPLAY_WIDTH && adjX >= 0) {
-					//If sprite does NOT have priority and background palette color at coordinate (adjX, adjY) isn't in bgp[0], then skip drawing pixel
-					if !s.SpriteAttributes().SpriteHasPriority && g.screenData[adjY][adjX] != g.bgPalette[0] {
-						continue
-					}
+	var bank, palette int = 0, int(s.attrs>>4) & 0x01
+	if g.RunningColorGBHardware {
+		bank, palette = int(s.attrs&0x08)>>3, int(s.attrs&0x07)
+	}
 
-					g.screenData[adjY][adjX] = g.objectPalettes[s.SpriteAttributes().NonCGBPaletteSelected][g.currentTileLineDotData[tileX]]
-				}
-			}
-		}
+	var pixels [8]int
+	formatTileLine(&g.tiledata[bank][tile], row%8, s.attrs&0x20 == 0x20, false, &pixels)
+	return pixels, palette
+}
+
+//With the background switched off a DMG shows a blank line under the sprites
+func (g *GPU) BlankScanline() {
+	for x := 0; x < DISPLAY_WIDTH; x++ {
+		g.screenData[g.ly][x] = g.dmgColours[0][0]
+		g.rawScreenDotData[g.ly][x] = 0
 	}
 }
 
-func (g *GPU) byteToPalette(b byte) Palette {
+func byteToPalette(b byte, colours Palette) Palette {
 	var palette Palette
-	palette[0] = GBColours[int(b&0x03)]
-	palette[1] = GBColours[int((b>>2)&0x03)]
-	palette[2] = GBColours[int((b>>4)&0x03)]
-	palette[3] = GBColours[(int(b>>6) & 0x03)]
+	palette[0] = colours[int(b&0x03)]
+	palette[1] = colours[int((b>>2)&0x03)]
+	palette[2] = colours[int((b>>4)&0x03)]
+	palette[3] = colours[(int(b>>6) & 0x03)]
 	return palette
 }
 
+//Sets the shades DMG games are drawn in, each palette register can be given its own
+func (g *GPU) SetDMGColours(bg, obj0, obj1 Palette) {
+	g.dmgColours = [3]Palette{bg, obj0, obj1}
+	g.bgPalette = byteToPalette(g.bgp, bg)
+	g.objectPalettes[0] = byteToPalette(g.obp0, obj0)
+	g.objectPalettes[1] = byteToPalette(g.obp1, obj1)
+}
+
 //debug helpers
 func (g *GPU) DumpTiles() [512][8][8]types.RGB {
 	fmt.Println("Dumping", len(g.tiledata[0]), "tiles")
@@ -724,7 +810,7 @@ func (g *GPU) DumpTiles() [512][8][8]types.RGB {
 	for i, tile := range g.tiledata[0] {
 		for y := 0; y < 8; y++ {
 			for x := 0; x < 8; x++ {
-				cr := GBColours[tile[y][x]]
+				cr := g.dmgColours[0][tile[y][x]]
 				out[i][y][x] = cr
 			}
 		}
@@ -741,7 +827,7 @@ func (g *GPU) Dump8x8Sprites() [40][8][8]types.RGB {
 			for x := 0; x < 8; x++ {
 				tileId := spr.GetTileID(0)
 				tile := g.tiledata[0][tileId]
-				cr := GBColours[tile[y][x]]
+				cr := g.dmgColours[0][tile[y][x]]
 				out[i][y][x] = cr
 			}
 		}
@@ -773,7 +859,7 @@ func (g *GPU) DumpTilemap(tileMapAddr types.Word, tileDataSigned bool) [256][256
 				}
 				tile := g.tiledata[0][tileId]
 				for tileX := 0; tileX < 8; tileX++ {
-					cr := GBColours[tile[tileY][tileX]]
+					cr := g.dmgColours[0][tile[tileY][tileX]]
 					result[rx][ry] = cr
 					rx++
 				}
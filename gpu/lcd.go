@@ -0,0 +1,57 @@
+package gpu
+
+import (
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+const DOTS_PER_FRAME int = DOTS_PER_LINE * 154
+
+//The LCD picks up this many dots into line 0 when it is turned on
+const LCD_ON_LINE_0_DOTS int = 4
+
+//Turning the LCD on restarts it part way into line 0. Until it first starts drawing it
+//reports mode 0 without that counting as an HBlank, and the frame it draws is not shown
+func (g *GPU) turnLCDOn() {
+	g.ly = 0
+	g.clock = DOTS_PER_LINE - LCD_ON_LINE_0_DOTS
+	g.mode = HBLANK
+	g.lcdStarting = true
+	g.blankFrame = true
+	g.vBlankInterruptThrown = false
+	g.pipeline.lx = DISPLAY_WIDTH
+	g.checkWindowY()
+}
+
+func (g *GPU) turnLCDOff() {
+	g.ly = 0
+	g.mode = HBLANK
+	g.statLine = false
+	g.offDots = 0
+	g.checkModeChange()
+}
+
+//The screen is white while the LCD is off, a white frame is still handed over every
+//frame's worth of dots so the frontend keeps up
+func (g *GPU) stepLCDOff(t int) {
+	g.offDots += t
+	if g.offDots >= DOTS_PER_FRAME {
+		g.offDots -= DOTS_PER_FRAME
+		g.fillScreen(g.blankColour())
+		g.screenOutputChannel <- &g.screenData
+	}
+}
+
+func (g *GPU) blankColour() types.RGB {
+	if g.RunningColorGBHardware {
+		return CGBColor(0x7FFF).ToRGB()
+	}
+	return g.dmgColours[0][0]
+}
+
+func (g *GPU) fillScreen(colour types.RGB) {
+	for y := range g.screenData {
+		for x := range g.screenData[y] {
+			g.screenData[y][x] = colour
+		}
+	}
+}
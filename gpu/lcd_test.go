@@ -0,0 +1,85 @@
+package gpu
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func isFilled(screen *types.Screen, colour types.RGB) bool {
+	for y := range screen {
+		for x := range screen[y] {
+			if screen[y][x] != colour {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func nextScreen(g *GPU, screens chan *types.Screen) *types.Screen {
+	for len(screens) == 0 {
+		g.Step(4)
+	}
+	return <-screens
+}
+
+func TestLCDOnTiming(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		g, _ := newTestGPU(r)
+		g.Write(LCDC, 0x91)
+
+		//line 0 starts part way in, reporting mode 0 instead of scanning OAM
+		g.Step(1)
+		assert.Equal(t, HBLANK, g.Read(STAT)&0x03, r.String())
+		var dots int = 1
+		for g.Read(LY) == 0 {
+			g.Step(1)
+			dots++
+		}
+		assert.Equal(t, DOTS_PER_LINE-LCD_ON_LINE_0_DOTS, dots, r.String())
+		g.Step(1)
+		assert.Equal(t, OAMREAD, g.Read(STAT)&0x03, r.String())
+	}
+}
+
+func TestFirstFrameAfterLCDOnIsBlank(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		g, _ := newTestGPU(r)
+		screens := make(chan *types.Screen, 2)
+		g.LinkScreen(screens)
+		for addr := types.Word(0x8000); addr < 0x8010; addr++ {
+			g.Write(addr, 0xFF)
+		}
+		g.Write(BGP, 0xE4)
+
+		//the screen is handed over by pointer so is checked as soon as it arrives
+		g.Write(LCDC, 0x91)
+		assert.True(t, isFilled(nextScreen(g, screens), GreyPalette[0]), r.String())
+		assert.True(t, isFilled(nextScreen(g, screens), GreyPalette[3]), r.String())
+	}
+}
+
+func TestWhiteFramesWhileLCDOff(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		g, _ := newTestGPU(r)
+		screens := make(chan *types.Screen, 2)
+		g.LinkScreen(screens)
+		g.Write(BGP, 0xE4)
+		g.Write(LCDC, 0x91)
+		for g.ly < 50 {
+			g.Step(4)
+		}
+
+		g.Write(LCDC, 0x11)
+		assert.Equal(t, byte(0), g.Read(LY), r.String())
+		assert.Equal(t, HBLANK, g.Read(STAT)&0x03, r.String())
+
+		g.Step(DOTS_PER_FRAME - 1)
+		assert.Equal(t, 0, len(screens), r.String())
+		g.Step(1)
+		assert.Equal(t, 1, len(screens), r.String())
+		assert.True(t, isFilled(<-screens, GreyPalette[0]), r.String())
+	}
+}
@@ -0,0 +1,182 @@
+package gpu
+
+import (
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Shades DMG games can be drawn in, lightest to darkest
+var GreyPalette Palette = Palette{
+	types.RGB{Red: 235, Green: 235, Blue: 235},
+	types.RGB{Red: 196, Green: 196, Blue: 196},
+	types.RGB{Red: 96, Green: 96, Blue: 96},
+	types.RGB{Red: 0, Green: 0, Blue: 0},
+}
+
+//The shades DMG games are drawn in unless another palette is configured, lightest to
+//darkest. They start out as GreyPalette and can be changed before a game is loaded
+var GBColours []types.RGB = []types.RGB{GreyPalette[0], GreyPalette[1], GreyPalette[2], GreyPalette[3]}
+
+//The palette GBColours holds, any shades missing from it are taken from GreyPalette
+func DefaultPalette() Palette {
+	var palette Palette = GreyPalette
+	copy(palette[:], GBColours)
+	return palette
+}
+
+//The green tinted LCD of the original Gameboy
+var GreenPalette Palette = Palette{
+	types.RGB{Red: 155, Green: 188, Blue: 15},
+	types.RGB{Red: 139, Green: 172, Blue: 15},
+	types.RGB{Red: 48, Green: 98, Blue: 48},
+	types.RGB{Red: 15, Green: 56, Blue: 15},
+}
+
+//The grey LCD of the Gameboy Pocket
+var PocketPalette Palette = Palette{
+	types.RGB{Red: 196, Green: 207, Blue: 161},
+	types.RGB{Red: 139, Green: 149, Blue: 109},
+	types.RGB{Red: 77, Green: 83, Blue: 60},
+	types.RGB{Red: 31, Green: 31, Blue: 31},
+}
+
+//Colours the CGB boot ROM gives a DMG game, for the background and the two object
+//palettes
+type Colourisation [3]CGBPalette
+
+func (c Colourisation) Palettes() (Palette, Palette, Palette) {
+	var palettes [3]Palette
+	for i, cp := range c {
+		for j, colour := range cp {
+			palettes[i][j] = colour.ToRGB()
+		}
+	}
+	return palettes[0], palettes[1], palettes[2]
+}
+
+//Palettes the boot ROM builds its colourisations from
+var (
+	cgbBrown     CGBPalette = CGBPalette{0x7FFF, 0x32BF, 0x00D0, 0x0000}
+	cgbRed       CGBPalette = CGBPalette{0x7FFF, 0x421F, 0x1CF2, 0x0000}
+	cgbDarkBrown CGBPalette = CGBPalette{0x639F, 0x4279, 0x15B0, 0x04CB}
+	cgbBlue      CGBPalette = CGBPalette{0x7FFF, 0x7E8C, 0x7C00, 0x0000}
+	cgbDarkBlue  CGBPalette = CGBPalette{0x7FFF, 0x6E31, 0x454A, 0x0000}
+	cgbGreen     CGBPalette = CGBPalette{0x7FFF, 0x1BEF, 0x0200, 0x0000}
+	cgbGrey      CGBPalette = CGBPalette{0x7FFF, 0x5294, 0x294A, 0x0000}
+	cgbPastel    CGBPalette = CGBPalette{0x53FF, 0x4A5F, 0x7E52, 0x0000}
+	cgbOrange    CGBPalette = CGBPalette{0x7FFF, 0x03FF, 0x001F, 0x0000}
+	cgbYellow    CGBPalette = CGBPalette{0x7FFF, 0x03FF, 0x012F, 0x0000}
+	cgbLime      CGBPalette = CGBPalette{0x7FFF, 0x03EA, 0x011F, 0x0000}
+	cgbDarkGreen CGBPalette = CGBPalette{0x7FFF, 0x1BEF, 0x6180, 0x0000}
+	cgbReverse   CGBPalette = CGBPalette{0x0000, 0x4200, 0x037F, 0x7FFF}
+)
+
+//Given to Nintendo games the boot ROM doesn't recognise and to other publishers' games
+var DefaultColourisation Colourisation = Colourisation{cgbDarkGreen, cgbRed, cgbRed}
+
+//Picked by holding a direction, on its own or with A or B, while the boot logo shows.
+//Indexed by direction (up, down, left, right) then no button, A, B
+var ButtonColourisations [4][3]Colourisation = [4][3]Colourisation{
+	{
+		{cgbBrown, cgbBrown, cgbBrown},
+		{cgbRed, cgbRed, cgbRed},
+		{cgbDarkBrown, cgbBrown, cgbBrown},
+	},
+	{
+		{cgbPastel, cgbPastel, cgbPastel},
+		{cgbOrange, cgbOrange, cgbOrange},
+		{cgbYellow, cgbBlue, cgbGreen},
+	},
+	{
+		{cgbBlue, cgbRed, cgbGreen},
+		{cgbDarkBlue, cgbRed, cgbBrown},
+		{cgbGrey, cgbGrey, cgbGrey},
+	},
+	{
+		{cgbLime, cgbLime, cgbLime},
+		{cgbDarkGreen, cgbRed, cgbRed},
+		{cgbReverse, cgbReverse, cgbReverse},
+	},
+}
+
+//The colours the boot ROM picks its palettes from, four to a palette. A few of the
+//combinations below start part way through a palette
+var bootColours [120]CGBColor = [120]CGBColor{
+	0x7FFF, 0x32BF, 0x00D0, 0x0000, 0x639F, 0x4279, 0x15B0, 0x04CB,
+	0x7FFF, 0x6E31, 0x454A, 0x0000, 0x7FFF, 0x1BEF, 0x0200, 0x0000,
+	0x7FFF, 0x421F, 0x1CF2, 0x0000, 0x7FFF, 0x5294, 0x294A, 0x0000,
+	0x7FFF, 0x03FF, 0x012F, 0x0000, 0x7FFF, 0x03EF, 0x01D6, 0x0000,
+	0x7FFF, 0x42B5, 0x3DC8, 0x0000, 0x7E74, 0x03FF, 0x0180, 0x0000,
+	0x67FF, 0x77AC, 0x1A13, 0x2D6B, 0x7ED6, 0x4BFF, 0x2175, 0x0000,
+	0x53FF, 0x4A5F, 0x7E52, 0x0000, 0x4FFF, 0x7ED2, 0x3A4C, 0x1CE0,
+	0x03ED, 0x7FFF, 0x255F, 0x0000, 0x036A, 0x021F, 0x03FF, 0x7FFF,
+	0x7FFF, 0x01DF, 0x0112, 0x0000, 0x231F, 0x035F, 0x00F2, 0x0009,
+	0x7FFF, 0x03EA, 0x011F, 0x0000, 0x299F, 0x001A, 0x000C, 0x0000,
+	0x7FFF, 0x027F, 0x001F, 0x0000, 0x7FFF, 0x03E0, 0x0206, 0x0120,
+	0x7FFF, 0x7EEB, 0x001F, 0x7C00, 0x7FFF, 0x3FFF, 0x7E00, 0x001F,
+	0x7FFF, 0x03FF, 0x001F, 0x0000, 0x03FF, 0x001F, 0x000C, 0x0000,
+	0x7FFF, 0x033F, 0x0193, 0x0000, 0x0000, 0x4200, 0x037F, 0x7FFF,
+	0x7FFF, 0x7E8C, 0x7C00, 0x0000, 0x7FFF, 0x1BEF, 0x6180, 0x0000,
+}
+
+//Offsets into bootColours of the object 0, object 1 and background palettes, in the
+//order the boot ROM keeps them
+var bootPaletteCombinations [51][3]int = [51][3]int{
+	{16, 16, 116}, {72, 72, 72}, {80, 80, 80}, {96, 96, 96}, {36, 36, 36},
+	{0, 0, 0}, {108, 108, 108}, {20, 20, 20}, {48, 48, 48}, {104, 104, 104},
+	{64, 32, 32}, {16, 112, 112}, {16, 8, 8}, {12, 16, 16}, {16, 116, 116},
+	{112, 16, 112}, {8, 68, 8}, {64, 64, 32}, {16, 16, 28}, {16, 16, 72},
+	{16, 16, 80}, {76, 76, 36}, {15, 15, 44}, {68, 68, 8}, {16, 16, 8},
+	{16, 16, 12}, {112, 112, 0}, {12, 12, 0}, {0, 0, 4}, {72, 88, 72},
+	{80, 88, 80}, {96, 88, 96}, {64, 88, 32}, {68, 16, 52}, {111, 0, 56},
+	{111, 16, 60}, {76, 88, 36}, {64, 112, 40}, {16, 92, 112}, {68, 88, 8},
+	{16, 0, 8}, {16, 112, 12}, {112, 12, 0}, {12, 112, 16}, {84, 112, 16},
+	{12, 112, 0}, {100, 12, 112}, {0, 112, 32}, {16, 12, 112}, {112, 16, 0},
+	{16, 112, 116},
+}
+
+//The boot ROM's table of Nintendo title checksums (the sum of the 16 title bytes).
+//Checksums from index 65 on are shared by more than one game, they are repeated every
+//14 entries and told apart by titleLetters
+var titleChecksums [94]byte = [94]byte{
+	0x00, 0x88, 0x16, 0x36, 0xD1, 0xDB, 0xF2, 0x3C, 0x8C, 0x92, 0x3D, 0x5C, 0x58, 0xC9, 0x3E, 0x70,
+	0x1D, 0x59, 0x69, 0x19, 0x35, 0xA8, 0x14, 0xAA, 0x75, 0x95, 0x99, 0x34, 0x6F, 0x15, 0xFF, 0x97,
+	0x4B, 0x90, 0x17, 0x10, 0x39, 0xF7, 0xF6, 0xA2, 0x49, 0x4E, 0x43, 0x68, 0xE0, 0x8B, 0xF0, 0xCE,
+	0x0C, 0x29, 0xE8, 0xB7, 0x86, 0x9A, 0x52, 0x01, 0x9D, 0x71, 0x9C, 0xBD, 0x5D, 0x6D, 0x67, 0x3F,
+	0x6B, 0xB3, 0x46, 0x28, 0xA5, 0xC6, 0xD3, 0x27, 0x61, 0x18, 0x66, 0x6A, 0xBF, 0x0D, 0xF4, 0xB3,
+	0x46, 0x28, 0xA5, 0xC6, 0xD3, 0x27, 0x61, 0x18, 0x66, 0x6A, 0xBF, 0x0D, 0xF4, 0xB3,
+}
+
+//The fourth letter of the title for each of titleChecksums from index 65
+var titleLetters string = "BEFAARBEKEK R-URAR INAILICE R"
+
+//The entry of bootPaletteCombinations used for each of titleChecksums. The first entry
+//is the default given to titles that aren't in the table
+var titleCombinations [94]byte = [94]byte{
+	0, 4, 5, 35, 34, 3, 31, 15, 10, 5, 19, 36, 7, 37, 30, 44,
+	21, 32, 31, 20, 5, 33, 13, 14, 5, 29, 5, 18, 9, 3, 2, 26,
+	25, 25, 41, 42, 26, 45, 42, 45, 36, 38, 26, 42, 30, 41, 34, 34,
+	5, 42, 6, 5, 33, 25, 42, 42, 40, 2, 16, 25, 42, 42, 5, 0,
+	39, 36, 22, 25, 6, 32, 12, 36, 11, 39, 18, 39, 24, 31, 50, 17,
+	46, 6, 27, 0, 47, 41, 41, 0, 0, 19, 34, 23, 18, 29,
+}
+
+//Builds the colours for one of bootPaletteCombinations, in the order Colourisation uses
+func bootColourisation(combination int) Colourisation {
+	var c Colourisation
+	offsets := bootPaletteCombinations[combination]
+	for i, palette := range []int{2, 0, 1} {
+		copy(c[i][:], bootColours[offsets[palette]:])
+	}
+	return c
+}
+
+//Looks up the colours for a Nintendo game from its title checksum and fourth letter
+//the way the boot ROM does, titles it doesn't know get DefaultColourisation
+func TitleColourisation(checksum, letter byte) Colourisation {
+	for i, sum := range titleChecksums {
+		if sum == checksum && (i < 65 || titleLetters[i-65] == letter) {
+			return bootColourisation(int(titleCombinations[i]))
+		}
+	}
+	return DefaultColourisation
+}
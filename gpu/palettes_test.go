@@ -0,0 +1,65 @@
+package gpu
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDMGColoursPerPaletteRegister(t *testing.T) {
+	g := NewGPU()
+	g.Write(BGP, 0xE4)
+	g.Write(OBJECTPALETTE_1, 0x1B)
+
+	bg, obj0, obj1 := DefaultColourisation.Palettes()
+	g.SetDMGColours(bg, obj0, obj1)
+	assert.Equal(t, bg[3], g.bgPalette[3])
+	assert.Equal(t, obj1[3], g.objectPalettes[1][0])
+
+	//registers written afterwards pick from the new shades too
+	g.Write(OBJECTPALETTE_0, 0xE4)
+	assert.Equal(t, obj0[1], g.objectPalettes[0][1])
+}
+
+func titleChecksum(title string) byte {
+	var sum byte
+	for _, b := range []byte(title) {
+		sum += b
+	}
+	return sum
+}
+
+func TestTitleColourisation(t *testing.T) {
+	assert.Equal(t, Colourisation{cgbRed, cgbGreen, cgbRed}, TitleColourisation(titleChecksum("POKEMON RED"), 'E'))
+	assert.Equal(t, Colourisation{cgbBlue, cgbRed, cgbBlue}, TitleColourisation(titleChecksum("POKEMON BLUE"), 'E'))
+
+	kirby := TitleColourisation(titleChecksum("KIRBY DREAM LAND"), 'B')
+	assert.Equal(t, CGBPalette{0x7E74, 0x03FF, 0x0180, 0x0000}, kirby[0])
+	assert.Equal(t, CGBPalette{0x299F, 0x001A, 0x000C, 0x0000}, kirby[1])
+
+	//SUPER MARIOLAND and METROID2 share a checksum, their object palettes start part
+	//way through a palette
+	sml := TitleColourisation(titleChecksum("SUPER MARIOLAND"), 'E')
+	assert.Equal(t, CGBPalette{0x7ED6, 0x4BFF, 0x2175, 0x0000}, sml[0])
+	assert.Equal(t, CGBPalette{0x0000, 0x7FFF, 0x421F, 0x1CF2}, sml[1])
+	metroid := TitleColourisation(titleChecksum("METROID2"), 'R')
+	assert.Equal(t, Colourisation{cgbBlue, CGBPalette{0x03FF, 0x001F, 0x000C, 0x0000}, cgbGreen}, metroid)
+
+	//the third game with the TETRIS ATTACK checksum
+	assert.Equal(t, Colourisation{cgbLime, cgbLime, CGBPalette{0x7FFF, 0x7EEB, 0x001F, 0x7C00}},
+		TitleColourisation(titleChecksum("TETRIS ATTACK"), 'R'))
+
+	assert.Equal(t, DefaultColourisation, TitleColourisation(titleChecksum("METROID2"), 'X'))
+	assert.Equal(t, DefaultColourisation, TitleColourisation(0x00, 'A'))
+}
+
+func TestDefaultPaletteFollowsGBColours(t *testing.T) {
+	assert.Equal(t, GreyPalette, DefaultPalette())
+
+	saved := GBColours
+	defer func() { GBColours = saved }()
+	GBColours = []types.RGB{{Red: 255}, {Green: 255}}
+	assert.Equal(t, Palette{{Red: 255}, {Green: 255}, GreyPalette[2], GreyPalette[3]}, DefaultPalette())
+	assert.Equal(t, types.RGB{Red: 255}, NewGPU().dmgColours[0][0])
+}
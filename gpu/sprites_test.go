@@ -0,0 +1,74 @@
+package gpu
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+//Sets up solid sprite tiles in colour 3 and switches the LCD on with sprites enabled
+func newSpriteTestGPU(r Renderer, color bool) *GPU {
+	g, _ := newTestGPU(r)
+	g.RunningColorGBHardware = color
+	for addr := types.Word(0x8010); addr < 0x8020; addr++ {
+		g.Write(addr, 0xFF)
+	}
+	g.Write(BGP, 0xE4)
+	g.Write(OBJECTPALETTE_0, 0xE4)
+	g.Write(OBJECTPALETTE_1, 0x54)
+	g.cgbObjectPalettes[0][3] = 0x001F
+	g.cgbObjectPalettes[1][3] = 0x03E0
+	g.Write(LCDC, 0x93)
+	return g
+}
+
+func addSprite(g *GPU, index int, y, x, tile, attrs byte) {
+	var addr types.Word = 0xFE00 + types.Word(index*4)
+	g.Write(addr, y)
+	g.Write(addr+1, x)
+	g.Write(addr+2, tile)
+	g.Write(addr+3, attrs)
+}
+
+func renderFirstLine(g *GPU) {
+	if g.Renderer() == FIFO_RENDERER {
+		mode3Length(g)
+		return
+	}
+	g.RenderBackgroundScanline()
+	g.RenderSpritesOnScanline()
+}
+
+func TestOnlyTenSpritesPerLine(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		g := newSpriteTestGPU(r, false)
+		for i := 0; i < 11; i++ {
+			addSprite(g, i, 16, byte(8+i*10), 1, 0x00)
+		}
+		renderFirstLine(g)
+
+		assert.Equal(t, GreyPalette[3], g.screenData[0][90], r.String())
+		assert.Equal(t, GreyPalette[0], g.screenData[0][100], r.String())
+	}
+}
+
+func TestOverlappingSpritePriority(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		//the sprite further left wins on DMG
+		g := newSpriteTestGPU(r, false)
+		addSprite(g, 0, 16, 20, 1, 0x11)
+		addSprite(g, 1, 16, 16, 1, 0x00)
+		renderFirstLine(g)
+		assert.Equal(t, GreyPalette[3], g.screenData[0][12], r.String())
+		assert.Equal(t, GreyPalette[1], g.screenData[0][16], r.String())
+
+		//the sprite first in OAM wins on CGB
+		g = newSpriteTestGPU(r, true)
+		addSprite(g, 0, 16, 20, 1, 0x11)
+		addSprite(g, 1, 16, 16, 1, 0x00)
+		renderFirstLine(g)
+		assert.Equal(t, CGBColor(0x03E0).ToRGB(), g.screenData[0][12], r.String())
+		assert.Equal(t, CGBColor(0x001F).ToRGB(), g.screenData[0][8], r.String())
+	}
+}
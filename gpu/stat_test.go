@@ -0,0 +1,82 @@
+package gpu
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/constants"
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func newTestGPU(r Renderer) (*GPU, *testIRQHandler) {
+	g := NewGPU()
+	g.SetRenderer(r)
+	irqs := new(testIRQHandler)
+	g.LinkIRQHandler(irqs)
+	g.LinkScreen(make(chan *types.Screen, 2))
+	g.Step(1)
+	return g, irqs
+}
+
+func (h *testIRQHandler) count(interrupt byte) int {
+	var n int
+	for _, i := range h.requested {
+		if i == interrupt {
+			n++
+		}
+	}
+	return n
+}
+
+//Runs from the LCD being switched on to the start of the next frame
+func runFrame(g *GPU) {
+	g.Write(LCDC, 0x91)
+	for g.ly < 153 {
+		g.Step(4)
+	}
+	for g.ly == 153 {
+		g.Step(4)
+	}
+}
+
+func TestSTATSourcesBlockEachOther(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		g, irqs := newTestGPU(r)
+		g.Write(STAT, 0x08)
+		runFrame(g)
+		assert.Equal(t, 144, irqs.count(constants.LCD_IRQ), r.String())
+
+		//mode 2 follows HBlank straight away so adds nothing, and the first line after
+		//the LCD is switched on has no mode 2
+		g, irqs = newTestGPU(r)
+		g.Write(STAT, 0x28)
+		runFrame(g)
+		assert.Equal(t, 144, irqs.count(constants.LCD_IRQ), r.String())
+	}
+}
+
+func TestLYCMatchesZeroDuringLine153(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		g, irqs := newTestGPU(r)
+		g.Write(STAT, 0x40)
+		g.Write(LYC, 0x00)
+		//switching the LCD on with LY == LYC raises the interrupt
+		g.Write(LCDC, 0x91)
+		for g.ly < 153 {
+			g.Step(4)
+		}
+		assert.Equal(t, byte(153), g.Read(LY), r.String())
+		assert.Equal(t, byte(0x00), g.Read(STAT)&0x04, r.String())
+
+		g.Step(4)
+		assert.Equal(t, byte(0), g.Read(LY), r.String())
+		assert.Equal(t, byte(0x84), g.Read(STAT)&0x84, r.String())
+		assert.Equal(t, 2, irqs.count(constants.LCD_IRQ), r.String())
+
+		//still matching when line 0 starts so no new interrupt
+		for g.ly == 153 {
+			g.Step(4)
+		}
+		assert.Equal(t, 2, irqs.count(constants.LCD_IRQ), r.String())
+	}
+}
@@ -0,0 +1,157 @@
+package gpu
+
+import (
+	"github.com/djhworld/gomeboycolor/state"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+func (g *GPU) SaveState(s *state.Writer) {
+	s.Bytes(g.vram[0][:])
+	s.Bytes(g.vram[1][:])
+	s.Bytes(g.oamRam[:])
+
+	s.Byte(g.mode)
+	s.Int(g.clock)
+	s.Int(g.ly)
+	s.Byte(g.lcdc)
+	s.Byte(g.lyc)
+	s.Byte(g.stat)
+	s.Byte(g.scrollY)
+	s.Byte(g.scrollX)
+	s.Byte(g.windowX)
+	s.Byte(g.windowY)
+	s.Byte(g.bgp)
+	s.Byte(g.obp0)
+	s.Byte(g.obp1)
+	s.Bool(g.vBlankInterruptThrown)
+	s.Bool(g.statLine)
+
+	s.Bool(g.RunningColorGBHardware)
+	s.Byte(g.cgbVramBankSelectionRegister)
+	for _, palettes := range [][8]CGBPalette{g.cgbBackgroundPalettes, g.cgbObjectPalettes} {
+		for _, palette := range palettes {
+			for _, colour := range palette {
+				s.Word(types.Word(colour))
+			}
+		}
+	}
+	s.Byte(g.cgbBGPWriteSpecReg.Value)
+	s.Byte(g.cgbBGPWriteDataRegister)
+	s.Byte(g.cgbOBJPWriteSpecReg.Value)
+	s.Byte(g.cgbOBJPWriteDataRegister)
+
+	s.Screen(&g.screenData)
+
+	s.Int(g.windowLine)
+	s.Bool(g.windowYMatched)
+	s.Int(g.lineSpriteCount)
+	for _, sprite := range g.lineSprites {
+		s.Int(sprite.index)
+		s.Int(sprite.y)
+		s.Int(sprite.x)
+		s.Int(sprite.tile)
+		s.Byte(sprite.attrs)
+	}
+	g.pipeline.saveState(s)
+
+	s.Bool(g.lcdStarting)
+	s.Bool(g.blankFrame)
+	s.Int(g.offDots)
+}
+
+//Loads the GPU state and rebuilds the decoded tiles, sprites and palettes from it
+func (g *GPU) LoadState(s *state.Reader) {
+	s.Bytes(g.vram[0][:])
+	s.Bytes(g.vram[1][:])
+	s.Bytes(g.oamRam[:])
+
+	g.mode = s.Byte()
+	g.clock = s.Int()
+	g.ly = s.Int()
+	g.setLCDC(s.Byte())
+	g.lyc = s.Byte()
+	g.stat = s.Byte()
+	g.scrollY = s.Byte()
+	g.scrollX = s.Byte()
+	g.windowX = s.Byte()
+	g.windowY = s.Byte()
+	g.Write(BGP, s.Byte())
+	g.Write(OBJECTPALETTE_0, s.Byte())
+	g.Write(OBJECTPALETTE_1, s.Byte())
+	g.vBlankInterruptThrown = s.Bool()
+	g.statLine = s.Bool()
+
+	g.RunningColorGBHardware = s.Bool()
+	g.cgbVramBankSelectionRegister = s.Byte()
+	for _, palettes := range []*[8]CGBPalette{&g.cgbBackgroundPalettes, &g.cgbObjectPalettes} {
+		for i := range palettes {
+			for j := range palettes[i] {
+				palettes[i][j] = CGBColor(s.Word())
+			}
+		}
+	}
+	g.cgbBGPWriteSpecReg.Update(s.Byte())
+	g.cgbBGPWriteDataRegister = s.Byte()
+	g.cgbOBJPWriteSpecReg.Update(s.Byte())
+	g.cgbOBJPWriteDataRegister = s.Byte()
+
+	s.Screen(&g.screenData)
+
+	g.windowLine = s.Int()
+	g.windowYMatched = s.Bool()
+	g.lineSpriteCount = s.Int()
+	for i := range g.lineSprites {
+		g.lineSprites[i] = lineSprite{index: s.Int(), y: s.Int(), x: s.Int(), tile: s.Int(), attrs: s.Byte()}
+	}
+	g.pipeline.loadState(s)
+
+	g.lcdStarting = s.Bool()
+	g.blankFrame = s.Bool()
+	g.offDots = s.Int()
+
+	//mode changes are reported as they happen so the current one already has been
+	g.reportedMode = g.mode
+
+	if g.lineSpriteCount < 0 || g.lineSpriteCount > MAX_SPRITES_PER_LINE {
+		s.Invalid("%d sprites on a line is out of range", g.lineSpriteCount)
+		g.lineSpriteCount = 0
+	}
+	for _, sprite := range g.lineSprites[:g.lineSpriteCount] {
+		if sprite.index < 0 || sprite.index >= 40 || sprite.tile < 0 || sprite.tile > 255 {
+			s.Invalid("sprite %d on the current line is out of range", sprite.index)
+			g.lineSpriteCount = 0
+			break
+		}
+	}
+
+	if g.offDots < 0 || g.offDots >= DOTS_PER_FRAME {
+		s.Invalid("%d dots with the LCD off is out of range", g.offDots)
+		g.offDots = 0
+	}
+
+	if g.ly < 0 || g.ly > 153 {
+		s.Invalid("LY %d is out of range", g.ly)
+		g.ly = 0
+	}
+
+	for bank := range g.vram {
+		for addr := range g.vram[bank] {
+			g.UpdateTile(0x8000+types.Word(addr), g.vram[bank][addr], byte(bank))
+		}
+	}
+
+	//both sprite sizes are refreshed, the next frame may switch between them
+	for i := 0; i < 40; i++ {
+		g.sprites8x8[i] = NewSprite8x8()
+		g.sprites8x16[i] = NewSprite8x16()
+	}
+	for addr, value := range g.oamRam {
+		g.sprites8x8[addr/4].UpdateSprite(0xFE00+types.Word(addr), value)
+		g.sprites8x16[addr/4].UpdateSprite(0xFE00+types.Word(addr), value)
+	}
+}
+
+//Returns the screen buffer the GPU is currently drawing into
+func (g *GPU) Screen() *types.Screen {
+	return &g.screenData
+}
@@ -0,0 +1,71 @@
+package gpu
+
+import (
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+//The window map's first row uses a tile whose top line is colour 3 and the rest colour 0
+func newWindowTestGPU(r Renderer) *GPU {
+	g, _ := newTestGPU(r)
+	g.Write(0x8010, 0xFF)
+	g.Write(0x8011, 0xFF)
+	for addr := types.Word(0x9C00); addr < 0x9C20; addr++ {
+		g.Write(addr, 0x01)
+	}
+	g.Write(BGP, 0xE4)
+	g.Write(WX, 7)
+	g.Write(WY, 1)
+	return g
+}
+
+//Steps until the given line has been drawn
+func runUntilDrawn(g *GPU, ly int) {
+	for g.ly != ly || g.mode != HBLANK || g.clock > DOTS_PER_LINE-OAM_SCAN_DOTS {
+		g.Step(1)
+	}
+}
+
+func TestWindowLineCounterOnlyMovesWhenDrawn(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		g := newWindowTestGPU(r)
+		g.Write(LCDC, 0xD1)
+		runUntilDrawn(g, 3)
+		g.Write(LCDC, 0xF1)
+		runUntilDrawn(g, 5)
+
+		//the window picks up from its first line rather than LY - WY
+		assert.Equal(t, GreyPalette[0], g.screenData[3][0], r.String())
+		assert.Equal(t, GreyPalette[3], g.screenData[4][0], r.String())
+		assert.Equal(t, GreyPalette[0], g.screenData[5][0], r.String())
+	}
+}
+
+func TestWindowEdgeCases(t *testing.T) {
+	for _, r := range []Renderer{SCANLINE_RENDERER, FIFO_RENDERER} {
+		//WX 166 leaves a single window pixel on the right edge
+		g := newWindowTestGPU(r)
+		g.Write(WX, 166)
+		g.Write(LCDC, 0xF1)
+		runUntilDrawn(g, 1)
+		assert.Equal(t, GreyPalette[0], g.screenData[1][158], r.String())
+		assert.Equal(t, GreyPalette[3], g.screenData[1][159], r.String())
+
+		//WY moving past LY before it matches keeps the window hidden
+		g = newWindowTestGPU(r)
+		g.Write(WY, 10)
+		g.Write(LCDC, 0xF1)
+		runUntilDrawn(g, 5)
+		g.Write(WY, 3)
+		runUntilDrawn(g, 12)
+		assert.Equal(t, GreyPalette[0], g.screenData[10][0], r.String())
+
+		//and is shown from the line WY is moved to when it is still ahead
+		g.Write(WY, 20)
+		runUntilDrawn(g, 21)
+		assert.Equal(t, GreyPalette[3], g.screenData[20][0], r.String())
+		assert.Equal(t, GreyPalette[0], g.screenData[21][0], r.String())
+	}
+}
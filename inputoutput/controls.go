@@ -2,9 +2,11 @@ package inputoutput
 
 import (
 	"log"
+	"sync"
 
 	"github.com/djhworld/gomeboycolor/components"
 	"github.com/djhworld/gomeboycolor/constants"
+	"github.com/djhworld/gomeboycolor/state"
 	"github.com/djhworld/gomeboycolor/types"
 )
 
@@ -27,6 +29,9 @@ type KeyHandler struct {
 	colSelect     byte
 	rows          [2]byte
 	irqHandler    components.IRQHandler
+
+	mutex       sync.Mutex
+	interceptor func(button int, down bool)
 }
 
 func (k *KeyHandler) Init(cs ControlScheme) {
@@ -57,8 +62,12 @@ func (k *KeyHandler) Read(addr types.Word) byte {
 		value = k.rows[1]
 	case ROW_2:
 		value = k.rows[0]
+	case 0x00:
+		//both rows selected, a line is low if a button in either row is down
+		value = k.rows[0] & k.rows[1]
 	default:
-		value = 0x00
+		//neither row selected so no line can be pulled low
+		value = 0x0F
 	}
 
 	return value
@@ -68,47 +77,118 @@ func (k *KeyHandler) Write(addr types.Word, value byte) {
 	k.colSelect = value & 0x30
 }
 
-//released sets bit for key to 0
-func (k *KeyHandler) KeyDown(key int) {
-	k.irqHandler.RequestInterrupt(constants.JOYP_HILO_IRQ)
+//Joypad buttons, independent of the keys a frontend maps to them
+const (
+	BUTTON_UP = iota
+	BUTTON_DOWN
+	BUTTON_LEFT
+	BUTTON_RIGHT
+	BUTTON_A
+	BUTTON_B
+	BUTTON_START
+	BUTTON_SELECT
+)
+
+//which row each button is on and the bit it clears in that row when pressed
+var buttonBits [8]struct {
+	row int
+	bit byte
+} = [8]struct {
+	row int
+	bit byte
+}{
+	BUTTON_UP:     {0, 0x4},
+	BUTTON_DOWN:   {0, 0x8},
+	BUTTON_LEFT:   {0, 0x2},
+	BUTTON_RIGHT:  {0, 0x1},
+	BUTTON_A:      {1, 0x1},
+	BUTTON_B:      {1, 0x2},
+	BUTTON_START:  {1, 0x8},
+	BUTTON_SELECT: {1, 0x4},
+}
+
+func (k *KeyHandler) button(key int) (int, bool) {
 	switch key {
 	case k.controlScheme.UP:
-		k.rows[0] &= 0xB
+		return BUTTON_UP, true
 	case k.controlScheme.DOWN:
-		k.rows[0] &= 0x7
+		return BUTTON_DOWN, true
 	case k.controlScheme.LEFT:
-		k.rows[0] &= 0xD
+		return BUTTON_LEFT, true
 	case k.controlScheme.RIGHT:
-		k.rows[0] &= 0xE
+		return BUTTON_RIGHT, true
 	case k.controlScheme.A:
-		k.rows[1] &= 0xE
+		return BUTTON_A, true
 	case k.controlScheme.B:
-		k.rows[1] &= 0xD
+		return BUTTON_B, true
 	case k.controlScheme.START:
-		k.rows[1] &= 0x7
+		return BUTTON_START, true
 	case k.controlScheme.SELECT:
-		k.rows[1] &= 0xB
+		return BUTTON_SELECT, true
+	}
+	return 0, false
+}
+
+//released sets bit for key to 0
+func (k *KeyHandler) KeyDown(key int) {
+	if button, ok := k.button(key); ok {
+		k.input(button, true)
 	}
 }
 
 //released sets bit for key to 1
 func (k *KeyHandler) KeyUp(key int) {
-	switch key {
-	case k.controlScheme.UP:
-		k.rows[0] |= 0x4
-	case k.controlScheme.DOWN:
-		k.rows[0] |= 0x8
-	case k.controlScheme.LEFT:
-		k.rows[0] |= 0x2
-	case k.controlScheme.RIGHT:
-		k.rows[0] |= 0x1
-	case k.controlScheme.A:
-		k.rows[1] |= 0x1
-	case k.controlScheme.B:
-		k.rows[1] |= 0x2
-	case k.controlScheme.START:
-		k.rows[1] |= 0x8
-	case k.controlScheme.SELECT:
-		k.rows[1] |= 0x4
+	if button, ok := k.button(key); ok {
+		k.input(button, false)
+	}
+}
+
+//Routes input from the frontend to the interceptor when one is set
+func (k *KeyHandler) input(button int, down bool) {
+	k.mutex.Lock()
+	interceptor := k.interceptor
+	k.mutex.Unlock()
+
+	if interceptor != nil {
+		interceptor(button, down)
+	} else {
+		k.SetButton(button, down)
+	}
+}
+
+//Diverts KeyDown and KeyUp to f instead of changing the joypad state, so input can be
+//recorded or replaced. Passing nil restores normal input
+func (k *KeyHandler) Intercept(f func(button int, down bool)) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.interceptor = f
+}
+
+//Presses or releases a button directly, bypassing any interceptor
+func (k *KeyHandler) SetButton(button int, down bool) {
+	b := buttonBits[button]
+	if down {
+		k.irqHandler.RequestInterrupt(constants.JOYP_HILO_IRQ)
+		k.rows[b.row] &^= b.bit
+	} else {
+		k.rows[b.row] |= b.bit
 	}
 }
+
+//Whether a button is currently held down
+func (k *KeyHandler) IsPressed(button int) bool {
+	b := buttonBits[button]
+	return k.rows[b.row]&b.bit == 0
+}
+
+func (k *KeyHandler) SaveState(s *state.Writer) {
+	s.Byte(k.colSelect)
+	s.Byte(k.rows[0])
+	s.Byte(k.rows[1])
+}
+
+func (k *KeyHandler) LoadState(s *state.Reader) {
+	k.colSelect = s.Byte()
+	k.rows[0] = s.Byte()
+	k.rows[1] = s.Byte()
+}
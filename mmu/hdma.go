@@ -0,0 +1,96 @@
+package mmu
+
+import (
+	"log"
+
+	"github.com/djhworld/gomeboycolor/constants"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+const HDMA_BLOCK_SIZE int = 0x10
+
+//M-cycles the CPU is stalled for each block copied at single speed, this doubles at
+//double speed as the copy takes the same real time
+const HDMA_BLOCK_STALL int = 8
+
+type HDMATransfer struct {
+	Source      types.Word
+	Destination types.Word
+	Length      int
+	HblankMode  bool
+	Running     bool
+}
+
+//Starts, or for a running HBlank DMA with bit 7 clear cancels, a transfer
+func (mmu *GbcMMU) writeHDMARegister(value byte) {
+	hdma := mmu.hdmaTransferInfo
+	if hdma.Running && hdma.HblankMode && value&0x80 == 0x00 {
+		log.Printf("%s: HBlank DMA cancelled with %d blocks left", PREFIX, hdma.Length)
+		hdma.Running = false
+		return
+	}
+
+	hdma.Length = int(value&0x7F) + 1
+	if value&0x80 == 0x00 {
+		//general purpose DMA copies everything straight away
+		hdma.HblankMode = false
+		hdma.Running = false
+		for hdma.Length > 0 {
+			mmu.copyHDMABlock()
+		}
+	} else {
+		hdma.HblankMode = true
+		hdma.Running = true
+	}
+}
+
+//Bit 7 is clear while an HBlank DMA is running, the rest is the number of blocks left
+//minus one. A finished transfer reads 0xFF
+func (mmu *GbcMMU) readHDMARegister() byte {
+	hdma := mmu.hdmaTransferInfo
+	var remaining byte = byte(hdma.Length-1) & 0x7F
+	if hdma.Running {
+		return remaining
+	}
+	return 0x80 | remaining
+}
+
+func (mmu *GbcMMU) copyHDMABlock() {
+	hdma := mmu.hdmaTransferInfo
+	var source types.Word = hdma.Source & 0xFFF0
+	var destination types.Word = 0x8000 | hdma.Destination&0x1FF0
+	for i := types.Word(0); i < types.Word(HDMA_BLOCK_SIZE); i++ {
+		mmu.write(destination+i, mmu.read(source+i))
+	}
+
+	hdma.Source += types.Word(HDMA_BLOCK_SIZE)
+	hdma.Destination += types.Word(HDMA_BLOCK_SIZE)
+	hdma.Length--
+	if hdma.Length == 0 {
+		hdma.Running = false
+	}
+
+	var stall int = HDMA_BLOCK_STALL
+	if mmu.cgbDoubleSpeedPreparationRegister&0x80 == 0x80 {
+		stall *= 2
+	}
+	mmu.dmaStallCycles += stall
+}
+
+//Called by the GPU whenever the LCD mode changes, HBlank DMA copies one block at the
+//start of each HBlank
+func (mmu *GbcMMU) LCDModeChanged(mode byte) {
+	mmu.lcdMode = mode
+	hdma := mmu.hdmaTransferInfo
+	if mode == constants.LCD_MODE_HBLANK && hdma.Running && hdma.HblankMode {
+		mmu.copyHDMABlock()
+	}
+}
+
+//Returns, and clears, the M-cycles the CPU has to wait for DMA transfers that have
+//happened since it was last asked
+func (mmu *GbcMMU) TakeDMAStall() int {
+	var stall int = mmu.dmaStallCycles
+	mmu.dmaStallCycles = 0
+	return stall
+}
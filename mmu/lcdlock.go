@@ -0,0 +1,28 @@
+package mmu
+
+import (
+	"github.com/djhworld/gomeboycolor/constants"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Turns the blocking of CPU accesses to VRAM and OAM while the PPU is using them on or
+//off, turning it off can help when debugging games that write at the wrong time
+func (mmu *GbcMMU) SetAccessLocking(locking bool) {
+	mmu.accessLocking = locking
+}
+
+//The PPU owns OAM while it scans and draws a line and VRAM while it draws, CPU reads
+//return 0xFF and writes are dropped. DMA transfers are not affected
+func (mmu *GbcMMU) lockedByLCD(addr types.Word) bool {
+	if !mmu.accessLocking {
+		return false
+	}
+
+	switch {
+	case addr >= 0x8000 && addr <= 0x9FFF:
+		return mmu.lcdMode == constants.LCD_MODE_VRAM
+	case addr >= OAM_START && addr <= 0xFE9F:
+		return mmu.lcdMode == constants.LCD_MODE_OAM || mmu.lcdMode == constants.LCD_MODE_VRAM
+	}
+	return false
+}
@@ -36,24 +36,17 @@ type MemoryMappedUnit interface {
 	ReadByte(address types.Word) byte
 	ReadWord(address types.Word) types.Word
 	SetInBootMode(mode bool)
+	SpeedSwitchPrepared() bool
+	SetDoubleSpeed(doubleSpeed bool)
 	LoadBIOS(data []byte) (bool, error)
 	LoadCartridge(cart *cartridge.Cartridge)
 	Reset()
 }
 
-type HDMATransfer struct {
-	Source      types.Word
-	Destination types.Word
-	Length      int
-	HblankMode  bool
-	Running     bool
-}
-
 type GbcMMU struct {
 	bios              [256]byte //0x0000 -> 0x00FF
 	cartridge         *cartridge.Cartridge
 	internalRAM       [8][4096]byte //0xC000 -> 0xDFFF (CGB Working RAM) (8x banks of 4KB)
-	internalRAMShadow [7680]byte    //0xE000 -> 0xFDFF
 	emptySpace        [52]byte      //0xFF4C -> 0xFF7F
 	zeroPageRAM       [128]byte     //0xFF80 - 0xFFFE
 	inBootMode        bool
@@ -63,16 +56,30 @@ type GbcMMU struct {
 	interruptsFlag    byte
 	peripheralsIO     [65536]components.Peripheral
 
+	//OAM DMA
+	oamDMAActive        bool
+	oamDMAStartDelay    int
+	oamDMASource        types.Word
+	oamDMAPendingSource types.Word
+	oamDMAIndex         int
+	oamDMAValue         byte
+
+	//LCD mode last reported by the GPU, used to lock the CPU out of VRAM and OAM
+	lcdMode       byte
+	accessLocking bool
+
 	//CGB features
 	cgbWramBankSelectedRegister       byte
 	cgbDoubleSpeedPreparationRegister byte
 	RunningColorGBHardware            bool
 	hdmaTransferInfo                  *HDMATransfer
+	dmaStallCycles                    int
 	serialTmp                         byte
 }
 
 func NewGbcMMU() *GbcMMU {
 	var mmu *GbcMMU = new(GbcMMU)
+	mmu.accessLocking = true
 	mmu.Reset()
 	return mmu
 }
@@ -80,11 +87,26 @@ func NewGbcMMU() *GbcMMU {
 func (mmu *GbcMMU) Reset() {
 	log.Println(PREFIX+": Resetting", PREFIX)
 	mmu.inBootMode = true
+	mmu.internalRAM = *new([8][4096]byte)
+	mmu.emptySpace = *new([52]byte)
+	mmu.zeroPageRAM = *new([128]byte)
+	mmu.dmgStatusRegister = 0x00
+	mmu.DMARegister = 0x00
+	mmu.oamDMAActive = false
+	mmu.oamDMAStartDelay = 0
+	mmu.oamDMASource = 0x0000
+	mmu.oamDMAPendingSource = 0x0000
+	mmu.oamDMAIndex = 0
+	mmu.oamDMAValue = 0xFF
+	mmu.lcdMode = constants.LCD_MODE_HBLANK
+	mmu.serialTmp = 0x00
+	mmu.interruptsEnabled = 0x00
 	mmu.interruptsFlag = 0x00
 	mmu.cgbWramBankSelectedRegister = 0x00
 	mmu.cgbDoubleSpeedPreparationRegister = 0x00
 	mmu.RunningColorGBHardware = false
 	mmu.hdmaTransferInfo = new(HDMATransfer)
+	mmu.dmaStallCycles = 0
 }
 
 func (mmu *GbcMMU) PrintPeripheralMap() {
@@ -95,7 +117,44 @@ func (mmu *GbcMMU) PrintPeripheralMap() {
 	}
 }
 
+//Writes on behalf of the CPU, which loses access to most of memory during OAM DMA
+//and to VRAM and OAM while the PPU is using them
 func (mmu *GbcMMU) WriteByte(addr types.Word, value byte) {
+	if mmu.oamDMAActive && mmu.oamDMABlocksWrite(addr) {
+		return
+	}
+	if mmu.lockedByLCD(addr) {
+		return
+	}
+	mmu.write(addr, value)
+}
+
+//Reads on behalf of the CPU, which loses access to most of memory during OAM DMA
+//and to VRAM and OAM while the PPU is using them
+func (mmu *GbcMMU) ReadByte(addr types.Word) byte {
+	if mmu.oamDMAActive {
+		if value, conflict := mmu.oamDMAConflictRead(addr); conflict {
+			return value
+		}
+	}
+	if mmu.lockedByLCD(addr) {
+		return 0xFF
+	}
+	return mmu.read(addr)
+}
+
+//Reads what is in memory regardless of OAM DMA or the PPU, for debuggers and other
+//tools that look at the machine without being part of it
+func (mmu *GbcMMU) Peek(addr types.Word) byte {
+	return mmu.read(addr)
+}
+
+//Writes to memory regardless of OAM DMA or the PPU, for debuggers editing memory
+func (mmu *GbcMMU) Poke(addr types.Word, value byte) {
+	mmu.write(addr, value)
+}
+
+func (mmu *GbcMMU) write(addr types.Word, value byte) {
 	//Check peripherals first
 	if p := mmu.peripheralsIO[addr]; p != nil {
 		p.Write(addr, value)
@@ -111,10 +170,9 @@ func (mmu *GbcMMU) WriteByte(addr types.Word, value byte) {
 	//GB Internal RAM
 	case addr >= 0xC000 && addr <= 0xDFFF:
 		mmu.WriteToWorkingRAM(addr, value)
-		//copy value to shadow if within shadow range
-		if addr >= 0xC000 && addr <= 0xDDFF {
-			mmu.internalRAMShadow[addr&(0xDDFF-0xC000)] = mmu.ReadByte(addr)
-		}
+	//GB Internal RAM echo
+	case addr >= 0xE000 && addr <= 0xFDFF:
+		mmu.WriteToWorkingRAM(addr-0x2000, value)
 	case addr == 0xFF01 || addr == 0xFF02:
 		//serial cable communication
 		mmu.serialTmp = ZERO
@@ -122,11 +180,8 @@ func (mmu *GbcMMU) WriteByte(addr types.Word, value byte) {
 	case addr == 0xFF0F:
 		mmu.interruptsFlag = value
 	//DMA transfer
-	case addr == 0xFF46:
-		var startAddr types.Word = types.Word(value) << 8
-		var oamAddr types.Word = 0xFE00
-		//transfer 10 blocks to OAM
-		mmu.doInstantDMATransfer(startAddr, oamAddr, 10, 16)
+	case addr == OAM_DMA_REG:
+		mmu.startOAMDMA(value)
 	//Empty but "unusable for I/O"
 	case addr > 0xFF4C && addr <= 0xFF7F:
 		mmu.WriteByteToRegister(addr, value)
@@ -142,7 +197,7 @@ func (mmu *GbcMMU) WriteByte(addr types.Word, value byte) {
 	}
 }
 
-func (mmu *GbcMMU) ReadByte(addr types.Word) byte {
+func (mmu *GbcMMU) read(addr types.Word) byte {
 	//Check peripherals first
 	if p := mmu.peripheralsIO[addr]; p != nil {
 		return p.Read(addr)
@@ -165,11 +220,11 @@ func (mmu *GbcMMU) ReadByte(addr types.Word) byte {
 	//GB Internal RAM
 	case addr >= 0xC000 && addr <= 0xDFFF:
 		return mmu.ReadFromWorkingRAM(addr)
-	//GB Internal RAM shadow
+	//GB Internal RAM echo
 	case addr >= 0xE000 && addr <= 0xFDFF:
-		return mmu.internalRAMShadow[addr&(0xFDFF-0xE000)]
+		return mmu.ReadFromWorkingRAM(addr - 0x2000)
 	//DMA register
-	case addr == 0xFF46:
+	case addr == OAM_DMA_REG:
 		return mmu.DMARegister
 	case addr == 0xFF01 || addr == 0xFF02:
 		//serial cable communication
@@ -211,6 +266,21 @@ func (mmu *GbcMMU) SetInBootMode(mode bool) {
 	mmu.inBootMode = mode
 }
 
+//Whether the game has asked for the CPU speed to change on the next STOP (CGB only)
+func (mmu *GbcMMU) SpeedSwitchPrepared() bool {
+	return mmu.RunningColorGBHardware && mmu.cgbDoubleSpeedPreparationRegister&0x01 == 0x01
+}
+
+//Called by the CPU once a speed switch has completed, KEY1 reports the new speed and
+//is no longer prepared for a switch
+func (mmu *GbcMMU) SetDoubleSpeed(doubleSpeed bool) {
+	if doubleSpeed {
+		mmu.cgbDoubleSpeedPreparationRegister = 0x80
+	} else {
+		mmu.cgbDoubleSpeedPreparationRegister = 0x00
+	}
+}
+
 func (mmu *GbcMMU) ConnectPeripheral(p components.Peripheral, startAddr, endAddr types.Word) {
 	if startAddr == endAddr {
 		log.Printf("%s: Connecting MMU to %s on address %s", PREFIX, p.Name(), startAddr)
@@ -253,11 +323,8 @@ func (mmu *GbcMMU) IsCartridgeColor() bool {
 	return mmu.cartridge.IsColourGB
 }
 
-func (mmu *GbcMMU) SaveCartridgeRam(writer io.Writer) {
-	err := mmu.cartridge.SaveRam(writer)
-	if err != nil {
-		log.Println("Error occured attempting to save RAM: ", err)
-	}
+func (mmu *GbcMMU) SaveCartridgeRam(writer io.Writer) error {
+	return mmu.cartridge.SaveRam(writer)
 }
 
 func (mmu *GbcMMU) LoadCartridgeRam(reader io.Reader) {
@@ -276,7 +343,8 @@ func (mmu *GbcMMU) WriteByteToRegister(addr types.Word, value byte) {
 		if mmu.RunningColorGBHardware == false {
 			log.Printf("%s: WARNING -> Cannot write to %s in non-CGB mode! ROM may have unexpected behaviour (ROM is probably unsupported in non-CGB mode)", PREFIX, CGB_WRAM_BANK_SELECT)
 		} else {
-			mmu.cgbDoubleSpeedPreparationRegister = value
+			//only the prepare bit can be written, bit 7 reports the current speed
+			mmu.cgbDoubleSpeedPreparationRegister = mmu.cgbDoubleSpeedPreparationRegister&0x80 | value&0x01
 		}
 	case CGB_INFRARED_PORT_REG:
 		log.Printf("%s: Attempting to write 0x%X to infrared port register (%s), this is currently unsupported", PREFIX, value, addr)
@@ -299,13 +367,7 @@ func (mmu *GbcMMU) WriteByteToRegister(addr types.Word, value byte) {
 		if mmu.RunningColorGBHardware == false {
 			log.Printf("%s: WARNING -> Cannot write to %s in non-CGB mode! ROM may have unexpected behaviour (ROM is probably unsupported in non-CGB mode)", PREFIX, CGB_WRAM_BANK_SELECT)
 		} else {
-			if value&0x80 == 0x00 {
-				mmu.hdmaTransferInfo.Length = int(value&0x7F) + 1
-				mmu.hdmaTransferInfo.Running = true
-				mmu.doInstantDMATransfer(mmu.hdmaTransferInfo.Source, mmu.hdmaTransferInfo.Destination, mmu.hdmaTransferInfo.Length, 16)
-			} else {
-				log.Println("HDMA horizontal HBlank is unsupported at the moment ")
-			}
+			mmu.writeHDMARegister(value)
 		}
 	default:
 		//unknown register, who cares?
@@ -320,9 +382,9 @@ func (mmu *GbcMMU) ReadByteFromRegister(addr types.Word) byte {
 		return mmu.dmgStatusRegister
 	case CGB_DOUBLE_SPEED_PREP_REG:
 		if mmu.RunningColorGBHardware == false {
-			log.Fatalf("%s: WARNING -> Attempting to read from %s in non-CGB mode! ROM may have unexpected behaviour (ROM is probably unsupported in non-CGB mode)", PREFIX, addr)
+			return 0xFF
 		}
-		return mmu.cgbDoubleSpeedPreparationRegister
+		return mmu.cgbDoubleSpeedPreparationRegister | 0x7E
 	case CGB_INFRARED_PORT_REG:
 		log.Fatalf("%s: Attempting to read from infrared port register (%s), this is currently unsupported", PREFIX, addr)
 		return 0x00
@@ -332,6 +394,11 @@ func (mmu *GbcMMU) ReadByteFromRegister(addr types.Word) byte {
 			return 0x00
 		}
 		return mmu.cgbWramBankSelectedRegister
+	case CGB_HDMA_REG:
+		if mmu.RunningColorGBHardware == false {
+			return 0xFF
+		}
+		return mmu.readHDMARegister()
 	default:
 		log.Printf("Reading register: %s", addr)
 		return mmu.emptySpace[addr-0xFF4C]
@@ -392,15 +459,6 @@ func (mmu *GbcMMU) ReadFromWorkingRAM(addr types.Word) byte {
 	return 0x00
 }
 
-func (mmu *GbcMMU) doInstantDMATransfer(startAddress, destinationAddr types.Word, blocks, blockSize int) {
-	length := types.Word(blockSize * blocks)
-	var i types.Word = 0x0000
-	for ; i < length; i++ {
-		data := mmu.ReadByte(startAddress + i)
-		mmu.WriteByte(destinationAddr+i, data)
-	}
-}
-
 //USE SHARED CONSTANTS FOR FLAGS AND STUFF TOO - for reuse in the CPU
 func (mmu *GbcMMU) RequestInterrupt(interrupt byte) {
 	oldVal := mmu.ReadByte(constants.INTERRUPT_FLAG_ADDR)
@@ -411,6 +469,8 @@ func (mmu *GbcMMU) RequestInterrupt(interrupt byte) {
 		mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, oldVal|constants.LCD_IRQ)
 	case constants.TIMER_OVERFLOW_IRQ:
 		mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, oldVal|constants.TIMER_OVERFLOW_IRQ)
+	case constants.SERIAL_IRQ:
+		mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, oldVal|constants.SERIAL_IRQ)
 	case constants.JOYP_HILO_IRQ:
 		mmu.WriteByte(constants.INTERRUPT_FLAG_ADDR, oldVal|constants.JOYP_HILO_IRQ)
 	default:
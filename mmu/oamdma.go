@@ -0,0 +1,93 @@
+package mmu
+
+import (
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+const OAM_DMA_REG types.Word = 0xFF46
+const OAM_START types.Word = 0xFE00
+
+//Bytes copied to OAM, one per M-cycle
+const OAM_DMA_LENGTH int = 0xA0
+
+//M-cycles between writing 0xFF46 and the transfer taking over the bus. The transfer
+//starts copying on the cycle after that
+const OAM_DMA_START_DELAY int = 2
+
+//The memory buses an OAM DMA can read from. While a transfer is running the CPU sees
+//whatever the DMA is reading if it uses the same bus
+const (
+	NO_BUS = iota
+	EXTERNAL_BUS
+	VRAM_BUS
+)
+
+//Starts (or restarts) a transfer from value * 0x100 into OAM. Sources from 0xE000 up
+//read working RAM as the echo region does
+func (mmu *GbcMMU) startOAMDMA(value byte) {
+	mmu.DMARegister = value
+	var source types.Word = types.Word(value) << 8
+	if source >= 0xE000 {
+		source -= 0x2000
+	}
+	mmu.oamDMAPendingSource = source
+	mmu.oamDMAStartDelay = OAM_DMA_START_DELAY
+}
+
+//Advances OAM DMA by the given number of M-cycles. A transfer that is already running
+//carries on while a restarted one waits to start
+func (mmu *GbcMMU) StepDMA(mcycles int) {
+	for ; mcycles > 0; mcycles-- {
+		if mmu.oamDMAStartDelay > 0 {
+			mmu.oamDMAStartDelay--
+			if mmu.oamDMAStartDelay == 0 {
+				mmu.oamDMASource = mmu.oamDMAPendingSource
+				mmu.oamDMAIndex = 0
+				mmu.oamDMAActive = true
+				continue
+			}
+		}
+
+		if mmu.oamDMAActive {
+			var offset types.Word = types.Word(mmu.oamDMAIndex)
+			mmu.oamDMAValue = mmu.read(mmu.oamDMASource + offset)
+			mmu.write(OAM_START+offset, mmu.oamDMAValue)
+			mmu.oamDMAIndex++
+			if mmu.oamDMAIndex == OAM_DMA_LENGTH {
+				mmu.oamDMAActive = false
+			}
+		}
+	}
+}
+
+func busFor(addr types.Word) int {
+	switch {
+	case addr >= 0x8000 && addr <= 0x9FFF:
+		return VRAM_BUS
+	case addr < 0xFE00:
+		return EXTERNAL_BUS
+	}
+	return NO_BUS
+}
+
+//Works out what the CPU sees reading addr while a transfer is running. OAM reads 0xFF
+//and the bus being used by the DMA returns the byte currently being copied, IO and HRAM
+//are unaffected
+func (mmu *GbcMMU) oamDMAConflictRead(addr types.Word) (byte, bool) {
+	if addr >= OAM_START && addr <= 0xFEFF {
+		return 0xFF, true
+	}
+	if bus := busFor(addr); bus != NO_BUS && bus == busFor(mmu.oamDMASource) {
+		return mmu.oamDMAValue, true
+	}
+	return 0x00, false
+}
+
+//CPU writes to OAM or the bus being used by the DMA are lost
+func (mmu *GbcMMU) oamDMABlocksWrite(addr types.Word) bool {
+	if addr >= OAM_START && addr <= 0xFEFF {
+		return true
+	}
+	bus := busFor(addr)
+	return bus != NO_BUS && bus == busFor(mmu.oamDMASource)
+}
@@ -0,0 +1,70 @@
+package mmu
+
+import (
+	"github.com/djhworld/gomeboycolor/state"
+)
+
+//Saves working RAM, HRAM, interrupt registers and OAM DMA/HDMA state. The cartridge
+//and the peripherals connected to the MMU save their own state
+func (mmu *GbcMMU) SaveState(s *state.Writer) {
+	for i := range mmu.internalRAM {
+		s.Bytes(mmu.internalRAM[i][:])
+	}
+	s.Bytes(mmu.emptySpace[:])
+	s.Bytes(mmu.zeroPageRAM[:])
+	s.Bool(mmu.inBootMode)
+	s.Byte(mmu.dmgStatusRegister)
+	s.Byte(mmu.DMARegister)
+	s.Bool(mmu.oamDMAActive)
+	s.Int(mmu.oamDMAStartDelay)
+	s.Word(mmu.oamDMASource)
+	s.Word(mmu.oamDMAPendingSource)
+	s.Int(mmu.oamDMAIndex)
+	s.Byte(mmu.oamDMAValue)
+	s.Byte(mmu.lcdMode)
+	s.Byte(mmu.interruptsEnabled)
+	s.Byte(mmu.interruptsFlag)
+	s.Byte(mmu.serialTmp)
+
+	s.Bool(mmu.RunningColorGBHardware)
+	s.Byte(mmu.cgbWramBankSelectedRegister)
+	s.Byte(mmu.cgbDoubleSpeedPreparationRegister)
+	s.Word(mmu.hdmaTransferInfo.Source)
+	s.Word(mmu.hdmaTransferInfo.Destination)
+	s.Int(mmu.hdmaTransferInfo.Length)
+	s.Bool(mmu.hdmaTransferInfo.HblankMode)
+	s.Bool(mmu.hdmaTransferInfo.Running)
+}
+
+func (mmu *GbcMMU) LoadState(s *state.Reader) {
+	for i := range mmu.internalRAM {
+		s.Bytes(mmu.internalRAM[i][:])
+	}
+	s.Bytes(mmu.emptySpace[:])
+	s.Bytes(mmu.zeroPageRAM[:])
+	mmu.inBootMode = s.Bool()
+	mmu.dmgStatusRegister = s.Byte()
+	mmu.DMARegister = s.Byte()
+	mmu.oamDMAActive = s.Bool()
+	mmu.oamDMAStartDelay = s.Int()
+	mmu.oamDMASource = s.Word()
+	mmu.oamDMAPendingSource = s.Word()
+	mmu.oamDMAIndex = s.Int()
+	mmu.oamDMAValue = s.Byte()
+	mmu.lcdMode = s.Byte()
+	if mmu.oamDMAIndex < 0 || mmu.oamDMAIndex > OAM_DMA_LENGTH || mmu.oamDMAStartDelay < 0 || mmu.oamDMAStartDelay > OAM_DMA_START_DELAY {
+		s.Invalid("OAM DMA progress %d/%d is out of range", mmu.oamDMAIndex, mmu.oamDMAStartDelay)
+	}
+	mmu.interruptsEnabled = s.Byte()
+	mmu.interruptsFlag = s.Byte()
+	mmu.serialTmp = s.Byte()
+
+	mmu.RunningColorGBHardware = s.Bool()
+	mmu.cgbWramBankSelectedRegister = s.Byte()
+	mmu.cgbDoubleSpeedPreparationRegister = s.Byte()
+	mmu.hdmaTransferInfo.Source = s.Word()
+	mmu.hdmaTransferInfo.Destination = s.Word()
+	mmu.hdmaTransferInfo.Length = s.Int()
+	mmu.hdmaTransferInfo.HblankMode = s.Bool()
+	mmu.hdmaTransferInfo.Running = s.Bool()
+}
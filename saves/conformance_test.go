@@ -0,0 +1,32 @@
+package saves_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/saves"
+	"github.com/djhworld/gomeboycolor/saves/savestest"
+)
+
+func TestMemoryStoreConformance(t *testing.T) {
+	savestest.TestStore(t, func() saves.ManagedStore {
+		return saves.NewMemoryStore()
+	})
+}
+
+func TestFileStoreConformance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomeboycolor-saves")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var n int
+	savestest.TestStore(t, func() saves.ManagedStore {
+		n++
+		return saves.NewFileStore(filepath.Join(dir, fmt.Sprint(n)), 2)
+	})
+}
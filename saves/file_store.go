@@ -0,0 +1,290 @@
+package saves
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const PREFIX = "SAVES:"
+
+//file extension used for battery saves written by the FileStore
+const SAVE_EXTENSION = ".sav"
+
+//Stores battery saves on the filesystem, one file per game.
+//
+//Saves are written to a temporary file that only replaces the existing save once it
+//has been completely written and synced to disk. The previous saves for a game are
+//kept as numbered backups (<game>.sav.1 being the most recent).
+type FileStore struct {
+	Directory string
+	Backups   int
+}
+
+func NewFileStore(directory string, backups int) *FileStore {
+	var s *FileStore = new(FileStore)
+	s.Directory = directory
+	s.Backups = backups
+	return s
+}
+
+func (s *FileStore) Open(game string) (io.ReadCloser, error) {
+	path, err := s.savePath(game)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, SaveNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *FileStore) Create(game string) (io.WriteCloser, error) {
+	path, err := s.savePath(game)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.Directory, 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := ioutil.TempFile(s.Directory, filepath.Base(path)+".tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileStoreWriter{store: s, file: tmp, path: path}, nil
+}
+
+func (s *FileStore) List() ([]Info, error) {
+	files, err := ioutil.ReadDir(s.Directory)
+	if os.IsNotExist(err) {
+		return []Info{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var result []Info = make([]Info, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), SAVE_EXTENSION) {
+			continue
+		}
+
+		game := strings.TrimSuffix(f.Name(), SAVE_EXTENSION)
+		if IsStateSlot(game) {
+			continue
+		}
+
+		info, err := s.Stat(game)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+func (s *FileStore) Stat(game string) (Info, error) {
+	path, err := s.savePath(game)
+	if err != nil {
+		return Info{}, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Info{}, SaveNotFound
+	} else if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return Info{}, err
+	}
+
+	return readInfo(game, f, stat.Size(), stat.ModTime()), nil
+}
+
+//Deletes the save for a game along with its backups
+func (s *FileStore) Delete(game string) error {
+	path, err := s.savePath(game)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); os.IsNotExist(err) {
+		return SaveNotFound
+	} else if err != nil {
+		return err
+	}
+
+	for i := 1; i <= s.Backups; i++ {
+		if err := os.Remove(backupPath(path, i)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Renames the save for a game along with its backups
+func (s *FileStore) Rename(game, newGame string) error {
+	path, err := s.savePath(game)
+	if err != nil {
+		return err
+	}
+
+	newPath, err := s.savePath(newGame)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return SaveNotFound
+	}
+
+	if _, err := os.Stat(newPath); err == nil {
+		return SaveAlreadyExists
+	}
+
+	if err := os.Rename(path, newPath); err != nil {
+		return err
+	}
+
+	for i := 1; i <= s.Backups; i++ {
+		err := os.Rename(backupPath(path, i), backupPath(newPath, i))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *FileStore) savePath(game string) (string, error) {
+	if err := validateName(game); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Directory, game+SAVE_EXTENSION), nil
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+//Shifts the existing backups of the save at path along by one, dropping the oldest,
+//and copies the current save into the first backup slot
+func (s *FileStore) rotateBackups(path string) error {
+	if s.Backups <= 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	oldest := backupPath(path, s.Backups)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for i := s.Backups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(path, i), backupPath(path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	//the current save is copied rather than moved so a save always exists on disk
+	return copyFile(path, backupPath(path, 1))
+}
+
+func copyFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+//Writes to a temporary file, the save is only committed when Close succeeds
+type fileStoreWriter struct {
+	store  *FileStore
+	file   *os.File
+	path   string
+	err    error
+	closed bool
+}
+
+func (w *fileStoreWriter) Write(b []byte) (int, error) {
+	n, err := w.file.Write(b)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (w *fileStoreWriter) Close() error {
+	if w.closed {
+		return errors.New("Save has already been closed")
+	}
+	w.closed = true
+
+	err := w.commit()
+	if err != nil {
+		log.Println(PREFIX, "Discarding incomplete save for", w.path, "-", err)
+		os.Remove(w.file.Name())
+	}
+	return err
+}
+
+func (w *fileStoreWriter) commit() error {
+	if w.err != nil {
+		w.file.Close()
+		return w.err
+	}
+
+	if err := w.file.Sync(); err != nil {
+		w.file.Close()
+		return err
+	}
+
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	if err := w.store.rotateBackups(w.path); err != nil {
+		return err
+	}
+
+	return os.Rename(w.file.Name(), w.path)
+}
@@ -0,0 +1,123 @@
+package saves
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func newTestFileStore(t *testing.T, backups int) (*FileStore, func()) {
+	dir, err := ioutil.TempDir("", "gomeboycolor-saves")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewFileStore(filepath.Join(dir, "nested", "saves"), backups), func() { os.RemoveAll(dir) }
+}
+
+func writeSave(t *testing.T, s *FileStore, game string, content string) {
+	w, err := s.Create(game)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte(content))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+}
+
+func readSave(t *testing.T, s *FileStore, game string) string {
+	r, err := s.Open(game)
+	assert.Nil(t, err)
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	return string(b)
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	s, cleanup := newTestFileStore(t, 2)
+	defer cleanup()
+
+	writeSave(t, s, "game", "hello")
+
+	assert.Equal(t, "hello", readSave(t, s, "game"))
+}
+
+func TestFileStoreOpenMissingSave(t *testing.T) {
+	s, cleanup := newTestFileStore(t, 2)
+	defer cleanup()
+
+	_, err := s.Open("game")
+	assert.Equal(t, SaveNotFound, err)
+}
+
+func TestFileStoreKeepsBackups(t *testing.T) {
+	s, cleanup := newTestFileStore(t, 2)
+	defer cleanup()
+
+	writeSave(t, s, "game", "one")
+	writeSave(t, s, "game", "two")
+	writeSave(t, s, "game", "three")
+	writeSave(t, s, "game", "four")
+
+	assert.Equal(t, "four", readSave(t, s, "game"))
+
+	path := filepath.Join(s.Directory, "game"+SAVE_EXTENSION)
+	b, err := ioutil.ReadFile(path + ".1")
+	assert.Nil(t, err)
+	assert.Equal(t, "three", string(b))
+
+	b, err = ioutil.ReadFile(path + ".2")
+	assert.Nil(t, err)
+	assert.Equal(t, "two", string(b))
+
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileStoreFailedWriteKeepsExistingSave(t *testing.T) {
+	s, cleanup := newTestFileStore(t, 1)
+	defer cleanup()
+
+	writeSave(t, s, "game", "good")
+
+	w, err := s.Create("game")
+	assert.Nil(t, err)
+	w.Write([]byte("partial"))
+
+	//simulate the disk filling up part way through the save
+	fw := w.(*fileStoreWriter)
+	fw.err = errors.New("no space left on device")
+	assert.NotNil(t, w.Close())
+
+	assert.Equal(t, "good", readSave(t, s, "game"))
+
+	files, err := ioutil.ReadDir(s.Directory)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files), "temporary file should have been removed")
+}
+
+func TestFileStoreRejectsInvalidNames(t *testing.T) {
+	s, cleanup := newTestFileStore(t, 1)
+	defer cleanup()
+
+	for _, name := range []string{"", ".", "..", "../game", "a/b"} {
+		_, err := s.Create(name)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestFileStoreDeleteRemovesBackups(t *testing.T) {
+	s, cleanup := newTestFileStore(t, 2)
+	defer cleanup()
+
+	writeSave(t, s, "game", "one")
+	writeSave(t, s, "game", "two")
+	writeSave(t, s, "game", "three")
+	assert.Nil(t, s.Delete("game"))
+
+	files, err := ioutil.ReadDir(s.Directory)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(files))
+}
@@ -0,0 +1,70 @@
+package saves
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+//Reads the cartridge title and save time from a battery save without decoding
+//its RAM banks. Saves from older versions may not have a title, in which case
+//it is left blank
+func ReadHeader(r io.Reader) (title string, lastSaved time.Time, err error) {
+	decoder := json.NewDecoder(r)
+
+	if t, err := decoder.Token(); err != nil {
+		return "", time.Time{}, err
+	} else if t != json.Delim('{') {
+		return "", time.Time{}, errors.New(fmt.Sprintf("Unexpected token %v at start of save", t))
+	}
+
+	var foundTitle, foundLastSaved bool
+	for decoder.More() && !(foundTitle && foundLastSaved) {
+		t, err := decoder.Token()
+		if err != nil {
+			return "", time.Time{}, err
+		}
+
+		switch t {
+		case "Title":
+			if err := decoder.Decode(&title); err != nil {
+				return "", time.Time{}, err
+			}
+			foundTitle = true
+		case "LastSaved":
+			var value string
+			if err := decoder.Decode(&value); err != nil {
+				return "", time.Time{}, err
+			}
+			if lastSaved, err = time.Parse(time.UnixDate, value); err != nil {
+				return "", time.Time{}, err
+			}
+			foundLastSaved = true
+		default:
+			//skip over anything else
+			var ignored json.RawMessage
+			if err := decoder.Decode(&ignored); err != nil {
+				return "", time.Time{}, err
+			}
+		}
+	}
+
+	if !foundLastSaved {
+		return "", time.Time{}, errors.New("Save does not contain a LastSaved time")
+	}
+
+	return title, lastSaved, nil
+}
+
+//Builds the info for a save, falling back to the modification time the store
+//recorded if the save header cannot be read
+func readInfo(game string, r io.Reader, size int64, modified time.Time) Info {
+	info := Info{Game: game, Size: size, LastSaved: modified}
+	if title, lastSaved, err := ReadHeader(r); err == nil {
+		info.Title = title
+		info.LastSaved = lastSaved
+	}
+	return info
+}
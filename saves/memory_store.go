@@ -0,0 +1,130 @@
+package saves
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+)
+
+//Keeps saves in memory, useful for tests and frontends that persist saves themselves
+type MemoryStore struct {
+	mutex sync.Mutex
+	saves map[string]memorySave
+}
+
+type memorySave struct {
+	data     []byte
+	modified time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	var s *MemoryStore = new(MemoryStore)
+	s.saves = make(map[string]memorySave)
+	return s
+}
+
+func (s *MemoryStore) Open(game string) (io.ReadCloser, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	save, ok := s.saves[game]
+	if !ok {
+		return nil, SaveNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(save.data)), nil
+}
+
+func (s *MemoryStore) Create(game string) (io.WriteCloser, error) {
+	if err := validateName(game); err != nil {
+		return nil, err
+	}
+	return &memoryStoreWriter{store: s, game: game}, nil
+}
+
+func (s *MemoryStore) List() ([]Info, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []Info = make([]Info, 0, len(s.saves))
+	for game, save := range s.saves {
+		if IsStateSlot(game) {
+			continue
+		}
+		result = append(result, s.info(game, save))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Game < result[j].Game
+	})
+	return result, nil
+}
+
+func (s *MemoryStore) Stat(game string) (Info, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	save, ok := s.saves[game]
+	if !ok {
+		return Info{}, SaveNotFound
+	}
+	return s.info(game, save), nil
+}
+
+func (s *MemoryStore) Delete(game string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.saves[game]; !ok {
+		return SaveNotFound
+	}
+	delete(s.saves, game)
+	return nil
+}
+
+func (s *MemoryStore) Rename(game, newGame string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	save, ok := s.saves[game]
+	if !ok {
+		return SaveNotFound
+	}
+
+	if err := validateName(newGame); err != nil {
+		return err
+	}
+
+	if _, exists := s.saves[newGame]; exists {
+		return SaveAlreadyExists
+	}
+
+	s.saves[newGame] = save
+	delete(s.saves, game)
+	return nil
+}
+
+func (s *MemoryStore) info(game string, save memorySave) Info {
+	return readInfo(game, bytes.NewReader(save.data), int64(len(save.data)), save.modified)
+}
+
+//Buffers writes, the save is only stored once the writer is closed
+type memoryStoreWriter struct {
+	store *MemoryStore
+	game  string
+	buf   bytes.Buffer
+}
+
+func (w *memoryStoreWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (w *memoryStoreWriter) Close() error {
+	w.store.mutex.Lock()
+	defer w.store.mutex.Unlock()
+
+	w.store.saves[w.game] = memorySave{data: w.buf.Bytes(), modified: time.Now()}
+	return nil
+}
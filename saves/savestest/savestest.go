@@ -0,0 +1,260 @@
+//Conformance tests for saves.ManagedStore implementations.
+//
+//Third party stores can run the suite from their own tests: -
+//
+//	func TestMyStore(t *testing.T) {
+//		savestest.TestStore(t, func() saves.ManagedStore { return NewMyStore() })
+//	}
+package savestest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/djhworld/gomeboycolor/cartridge"
+	"github.com/djhworld/gomeboycolor/saves"
+)
+
+//Runs every conformance test against stores made by newStore, which must
+//return a new, empty store each time it is called
+func TestStore(t *testing.T, newStore func() saves.ManagedStore) {
+	tests := []struct {
+		name string
+		test func(*testing.T, saves.ManagedStore)
+	}{
+		{"CreateThenOpen", testCreateThenOpen},
+		{"CreateReplacesSave", testCreateReplacesSave},
+		{"OpenMissing", testOpenMissing},
+		{"ListEmpty", testListEmpty},
+		{"List", testList},
+		{"ListSkipsStateSlots", testListSkipsStateSlots},
+		{"Stat", testStat},
+		{"StatMissing", testStatMissing},
+		{"Delete", testDelete},
+		{"DeleteMissing", testDeleteMissing},
+		{"Rename", testRename},
+		{"RenameMissing", testRenameMissing},
+		{"RenameOntoExisting", testRenameOntoExisting},
+		{"InvalidNames", testInvalidNames},
+	}
+
+	for _, tc := range tests {
+		test := tc.test
+		t.Run(tc.name, func(t *testing.T) {
+			test(t, newStore())
+		})
+	}
+}
+
+//Creates a battery save in the same format the emulator writes
+func makeSave(t *testing.T, title string, fill byte) []byte {
+	bank := bytes.Repeat([]byte{fill}, 0x2000)
+
+	var buf bytes.Buffer
+	s := cartridge.NewSave()
+	s.Title = title
+	if err := s.Save(&buf, [][]byte{bank, bank}); err != nil {
+		t.Fatal("could not create save:", err)
+	}
+	return buf.Bytes()
+}
+
+func writeSave(t *testing.T, store saves.ManagedStore, game string, data []byte) {
+	w, err := store.Create(game)
+	if err != nil {
+		t.Fatal("Create:", err)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		t.Fatal("Write:", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal("Close:", err)
+	}
+}
+
+func readSave(t *testing.T, store saves.ManagedStore, game string) []byte {
+	r, err := store.Open(game)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal("Read:", err)
+	}
+	return data
+}
+
+func testCreateThenOpen(t *testing.T, store saves.ManagedStore) {
+	data := makeSave(t, "TETRIS", 0x01)
+	writeSave(t, store, "game", data)
+
+	if !bytes.Equal(data, readSave(t, store, "game")) {
+		t.Error("save read back does not match what was written")
+	}
+}
+
+func testCreateReplacesSave(t *testing.T, store saves.ManagedStore) {
+	writeSave(t, store, "game", makeSave(t, "TETRIS", 0x01))
+	data := makeSave(t, "TETRIS", 0x02)
+	writeSave(t, store, "game", data)
+
+	if !bytes.Equal(data, readSave(t, store, "game")) {
+		t.Error("save read back is not the latest save written")
+	}
+}
+
+func testOpenMissing(t *testing.T, store saves.ManagedStore) {
+	if _, err := store.Open("missing"); err != saves.SaveNotFound {
+		t.Errorf("expected %v but got %v", saves.SaveNotFound, err)
+	}
+}
+
+func testListEmpty(t *testing.T, store saves.ManagedStore) {
+	infos, err := store.List()
+	if err != nil {
+		t.Fatal("List:", err)
+	}
+
+	if len(infos) != 0 {
+		t.Errorf("expected no saves but found %v", infos)
+	}
+}
+
+func testList(t *testing.T, store saves.ManagedStore) {
+	writeSave(t, store, "game1", makeSave(t, "TETRIS", 0x01))
+	writeSave(t, store, "game2", makeSave(t, "ZELDA", 0x02))
+
+	infos, err := store.List()
+	if err != nil {
+		t.Fatal("List:", err)
+	}
+
+	titles := make(map[string]string)
+	for _, info := range infos {
+		titles[info.Game] = info.Title
+	}
+
+	if len(infos) != 2 || titles["game1"] != "TETRIS" || titles["game2"] != "ZELDA" {
+		t.Errorf("unexpected saves listed: %v", infos)
+	}
+}
+
+func testListSkipsStateSlots(t *testing.T, store saves.ManagedStore) {
+	writeSave(t, store, "game", makeSave(t, "TETRIS", 0x01))
+	writeSave(t, store, saves.StateSlotName("game", 3), []byte("state"))
+
+	infos, err := store.List()
+	if err != nil {
+		t.Fatal("List:", err)
+	}
+
+	if len(infos) != 1 || infos[0].Game != "game" {
+		t.Errorf("expected only the battery save to be listed but found %v", infos)
+	}
+}
+
+func testStat(t *testing.T, store saves.ManagedStore) {
+	before := time.Now().Add(-time.Second)
+	data := makeSave(t, "TETRIS", 0x01)
+	writeSave(t, store, "game", data)
+
+	info, err := store.Stat("game")
+	if err != nil {
+		t.Fatal("Stat:", err)
+	}
+
+	if info.Game != "game" {
+		t.Errorf("expected game %q but got %q", "game", info.Game)
+	}
+
+	if info.Title != "TETRIS" {
+		t.Errorf("expected title %q but got %q", "TETRIS", info.Title)
+	}
+
+	if info.Size != int64(len(data)) {
+		t.Errorf("expected size %d but got %d", len(data), info.Size)
+	}
+
+	if info.LastSaved.Before(before) || info.LastSaved.After(time.Now().Add(time.Second)) {
+		t.Errorf("unexpected save time %v", info.LastSaved)
+	}
+}
+
+func testStatMissing(t *testing.T, store saves.ManagedStore) {
+	if _, err := store.Stat("missing"); err != saves.SaveNotFound {
+		t.Errorf("expected %v but got %v", saves.SaveNotFound, err)
+	}
+}
+
+func testDelete(t *testing.T, store saves.ManagedStore) {
+	writeSave(t, store, "game", makeSave(t, "TETRIS", 0x01))
+
+	if err := store.Delete("game"); err != nil {
+		t.Fatal("Delete:", err)
+	}
+
+	if _, err := store.Stat("game"); err != saves.SaveNotFound {
+		t.Errorf("expected save to be deleted but Stat returned %v", err)
+	}
+
+	if _, err := store.Open("game"); err == nil {
+		t.Error("expected save to be deleted but it could still be opened")
+	}
+}
+
+func testDeleteMissing(t *testing.T, store saves.ManagedStore) {
+	if err := store.Delete("missing"); err != saves.SaveNotFound {
+		t.Errorf("expected %v but got %v", saves.SaveNotFound, err)
+	}
+}
+
+func testRename(t *testing.T, store saves.ManagedStore) {
+	data := makeSave(t, "TETRIS", 0x01)
+	writeSave(t, store, "game", data)
+
+	if err := store.Rename("game", "renamed"); err != nil {
+		t.Fatal("Rename:", err)
+	}
+
+	if _, err := store.Stat("game"); err != saves.SaveNotFound {
+		t.Errorf("expected old save to be gone but Stat returned %v", err)
+	}
+
+	if !bytes.Equal(data, readSave(t, store, "renamed")) {
+		t.Error("renamed save does not match what was written")
+	}
+}
+
+func testRenameMissing(t *testing.T, store saves.ManagedStore) {
+	if err := store.Rename("missing", "renamed"); err != saves.SaveNotFound {
+		t.Errorf("expected %v but got %v", saves.SaveNotFound, err)
+	}
+}
+
+func testRenameOntoExisting(t *testing.T, store saves.ManagedStore) {
+	data := makeSave(t, "ZELDA", 0x02)
+	writeSave(t, store, "game1", makeSave(t, "TETRIS", 0x01))
+	writeSave(t, store, "game2", data)
+
+	if err := store.Rename("game1", "game2"); err != saves.SaveAlreadyExists {
+		t.Errorf("expected %v but got %v", saves.SaveAlreadyExists, err)
+	}
+
+	if !bytes.Equal(data, readSave(t, store, "game2")) {
+		t.Error("existing save was overwritten by rename")
+	}
+}
+
+func testInvalidNames(t *testing.T, store saves.ManagedStore) {
+	for _, name := range []string{"", ".", "..", "../game", "dir/game"} {
+		if _, err := store.Create(name); err == nil {
+			t.Errorf("expected Create to reject the name %q", name)
+		}
+	}
+}
@@ -1,8 +1,63 @@
 package saves
 
-import "io"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var SaveNotFound error = errors.New("Save not found")
+var SaveAlreadyExists error = errors.New("Save already exists")
 
 type Store interface {
 	Open(game string) (io.ReadCloser, error)
 	Create(game string) (io.WriteCloser, error)
 }
+
+//Describes a save without loading its RAM banks
+type Info struct {
+	Game      string
+	Title     string
+	Size      int64
+	LastSaved time.Time
+}
+
+//A store that can also be browsed and managed, e.g. by a frontend's save manager.
+//Open, Stat, Delete and Rename return SaveNotFound for games that have no save. List
+//only returns battery saves, save state slots kept in the same store are left out
+type ManagedStore interface {
+	Store
+	List() ([]Info, error)
+	Stat(game string) (Info, error)
+	Delete(game string) error
+	Rename(game, newGame string) error
+}
+
+//Save state slots are kept alongside battery saves as <game>.slot<n>
+const STATE_SLOT_SEPARATOR string = ".slot"
+
+func StateSlotName(game string, slot int) string {
+	return fmt.Sprintf("%s%s%d", game, STATE_SLOT_SEPARATOR, slot)
+}
+
+//Whether name belongs to a save state slot rather than a battery save
+func IsStateSlot(name string) bool {
+	i := strings.LastIndex(name, STATE_SLOT_SEPARATOR)
+	if i <= 0 {
+		return false
+	}
+	_, err := strconv.Atoi(name[i+len(STATE_SLOT_SEPARATOR):])
+	return err == nil
+}
+
+//Names are used as file names by some stores so must not be able to refer to another directory
+func validateName(game string) error {
+	if game == "" || game != filepath.Base(game) || game == "." || game == ".." {
+		return errors.New(fmt.Sprintf("Invalid game name %q for save store", game))
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+//Helpers for serializing emulator components into save states.
+//
+//Values are written in a fixed little endian binary layout, in the order each
+//component chooses. The first error encountered is kept and every later read or
+//write becomes a no-op, so components can serialize themselves without checking
+//errors after every field.
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//longest string that will be read back from a save state
+const MAX_STRING_LENGTH = 1024
+
+type Writer struct {
+	w   io.Writer
+	err error
+	buf [8]byte
+}
+
+func NewWriter(w io.Writer) *Writer {
+	var s *Writer = new(Writer)
+	s.w = w
+	return s
+}
+
+func (s *Writer) Err() error {
+	return s.err
+}
+
+func (s *Writer) Bytes(b []byte) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = s.w.Write(b)
+}
+
+func (s *Writer) Byte(b byte) {
+	s.buf[0] = b
+	s.Bytes(s.buf[:1])
+}
+
+func (s *Writer) Bool(b bool) {
+	if b {
+		s.Byte(1)
+	} else {
+		s.Byte(0)
+	}
+}
+
+func (s *Writer) Word(w types.Word) {
+	binary.LittleEndian.PutUint16(s.buf[:2], uint16(w))
+	s.Bytes(s.buf[:2])
+}
+
+func (s *Writer) Int(i int) {
+	binary.LittleEndian.PutUint64(s.buf[:8], uint64(int64(i)))
+	s.Bytes(s.buf[:8])
+}
+
+//Writes a length prefixed block of bytes
+func (s *Writer) Data(b []byte) {
+	s.Int(len(b))
+	s.Bytes(b)
+}
+
+func (s *Writer) String(str string) {
+	s.Data([]byte(str))
+}
+
+func (s *Writer) Screen(screen *types.Screen) {
+	var line [160 * 3]byte
+	for y := range screen {
+		for x, c := range screen[y] {
+			line[x*3], line[x*3+1], line[x*3+2] = c.Red, c.Green, c.Blue
+		}
+		s.Bytes(line[:])
+	}
+}
+
+type Reader struct {
+	r   io.Reader
+	err error
+	buf [8]byte
+}
+
+func NewReader(r io.Reader) *Reader {
+	var s *Reader = new(Reader)
+	s.r = r
+	return s
+}
+
+func (s *Reader) Err() error {
+	return s.err
+}
+
+//Marks the state as invalid, used by components when a value read back makes no sense
+func (s *Reader) Invalid(format string, args ...interface{}) {
+	if s.err == nil {
+		s.err = errors.New("Invalid save state: " + fmt.Sprintf(format, args...))
+	}
+}
+
+//Fills b entirely from the state
+func (s *Reader) Bytes(b []byte) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = io.ReadFull(s.r, b)
+}
+
+func (s *Reader) Byte() byte {
+	s.Bytes(s.buf[:1])
+	if s.err != nil {
+		return 0
+	}
+	return s.buf[0]
+}
+
+func (s *Reader) Bool() bool {
+	return s.Byte() != 0
+}
+
+func (s *Reader) Word() types.Word {
+	s.Bytes(s.buf[:2])
+	if s.err != nil {
+		return 0
+	}
+	return types.Word(binary.LittleEndian.Uint16(s.buf[:2]))
+}
+
+func (s *Reader) Int() int {
+	s.Bytes(s.buf[:8])
+	if s.err != nil {
+		return 0
+	}
+	return int(int64(binary.LittleEndian.Uint64(s.buf[:8])))
+}
+
+//Reads a length prefixed block of bytes, refusing blocks longer than max
+func (s *Reader) Data(max int) []byte {
+	length := s.Int()
+	if s.err != nil {
+		return nil
+	}
+	if length < 0 || length > max {
+		s.Invalid("block length %d is out of range", length)
+		return nil
+	}
+
+	b := make([]byte, length)
+	s.Bytes(b)
+	return b
+}
+
+func (s *Reader) String() string {
+	return string(s.Data(MAX_STRING_LENGTH))
+}
+
+func (s *Reader) Screen(screen *types.Screen) {
+	var line [160 * 3]byte
+	for y := range screen {
+		s.Bytes(line[:])
+		if s.err != nil {
+			return
+		}
+		for x := range screen[y] {
+			screen[y][x] = types.RGB{Red: line[x*3], Green: line[x*3+1], Blue: line[x*3+2]}
+		}
+	}
+}
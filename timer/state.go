@@ -0,0 +1,22 @@
+package timer
+
+import (
+	"github.com/djhworld/gomeboycolor/state"
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+func (timer *Timer) SaveState(s *state.Writer) {
+	s.Word(types.Word(timer.divider))
+	s.Byte(timer.tima)
+	s.Byte(timer.tacRegister)
+	s.Byte(timer.tmaRegister)
+	s.Bool(timer.overflowed)
+}
+
+func (timer *Timer) LoadState(s *state.Reader) {
+	timer.divider = uint16(s.Word())
+	timer.tima = s.Byte()
+	timer.tacRegister = s.Byte() & 0x07
+	timer.tmaRegister = s.Byte()
+	timer.overflowed = s.Bool()
+}
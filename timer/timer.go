@@ -10,7 +10,7 @@ import (
 )
 
 const (
-	DIV_REGISTER  types.Word = 0xFF04
+	DIV_REGISTER  types.Word = constants.DIV_REGISTER_ADDR
 	TIMA_REGISTER            = 0xFF05
 	TMA_REGISTER             = 0xFF06
 	TAC_REGISTER             = 0xFF07
@@ -20,83 +20,31 @@ const (
 	NAME = "TIMER"
 )
 
-type Frequency string
-
-const (
-	freq4096   Frequency = "4096hz"
-	freq16384            = "16384hz"
-	freq65536            = "65536hz"
-	freq262144           = "262144hz"
-)
-
-var FrequenciesToCycles map[Frequency]int = map[Frequency]int{
-	freq4096:   256, //1024,
-	freq262144: 4,
-	freq65536:  16,
-	freq16384:  64,
-}
-
-type Counter struct {
-	Name           string
-	ClockFrequency Frequency
-	ClockCounter   int
-	Value          byte
-}
-
-func NewCounter(name string, initialFreq Frequency) *Counter {
-	var counter *Counter = new(Counter)
-	counter.Reset()
-	counter.Name = name
-	counter.SetFrequency(initialFreq)
-	return counter
-}
-
-func (c *Counter) String() string {
-	return fmt.Sprint(c.Name+":", " Frequency: ", c.ClockFrequency, " (", FrequenciesToCycles[c.ClockFrequency], " cycles) ", "| Current Counter: ", c.ClockCounter, " | Value: ", c.Value)
-}
-
-func (c *Counter) Reset() {
-	//default to 4096
-	c.SetFrequency(freq4096)
-	c.Value = 0
-}
-
-func (c *Counter) SetFrequency(freq Frequency) {
-	c.ClockFrequency = freq
-	c.ClockCounter = FrequenciesToCycles[freq]
-}
-
-//returns true when overflowed
-func (c *Counter) Step(cycles int) bool {
-	c.ClockCounter -= cycles
-
-	for c.ClockCounter <= 0 {
-		c.Value++
-		c.ClockCounter += FrequenciesToCycles[c.ClockFrequency]
-		if c.Value == 0x00 {
-			return true
-		}
-	}
-
-	return false
+//The bit of the internal divider that clocks TIMA for each TAC frequency, TIMA is
+//incremented whenever the selected bit goes from 1 to 0
+var timaClockBits [4]uint16 = [4]uint16{
+	9, //4096hz
+	3, //262144hz
+	5, //65536hz
+	7, //16384hz
 }
 
+//The timer is driven by a 16 bit divider that counts T-cycles, DIV is its upper 8 bits
 type Timer struct {
-	timaCounter *Counter
-	divCounter  *Counter
+	divider     uint16
+	tima        byte
+	tacRegister byte
+	tmaRegister byte
+
+	//TIMA reads as 0 for an M-cycle after it overflows, TMA is loaded and the
+	//interrupt raised on the M-cycle after that
+	overflowed bool
 
-	tacRegister     byte
-	tmaRegister     byte
-	irqHandler      components.IRQHandler
-	interruptThrown bool
+	irqHandler components.IRQHandler
 }
 
 func NewTimer() *Timer {
 	var t *Timer = new(Timer)
-	t.divCounter = NewCounter("DIV", freq16384)
-	t.timaCounter = NewCounter("TIMA", freq4096)
-	t.tacRegister = 0x00
-	t.tmaRegister = 0x00
 	return t
 }
 
@@ -104,28 +52,51 @@ func (timer *Timer) Name() string {
 	return NAME
 }
 
+//Steps the timer by the given number of CPU M-cycles
 func (timer *Timer) Step(cycles int) {
-	if timer.tacRegister&0x04 == 0x04 {
-		if overflowed := timer.timaCounter.Step(cycles); overflowed {
+	for i := 0; i < cycles; i++ {
+		if timer.overflowed {
+			timer.overflowed = false
+			timer.tima = timer.tmaRegister
 			timer.irqHandler.RequestInterrupt(constants.TIMER_OVERFLOW_IRQ)
-			timer.timaCounter.Value = timer.tmaRegister
 		}
+		timer.setDivider(timer.divider + 4)
 	}
+}
 
-	//step div timer
-	timer.divCounter.Step(cycles)
+//Whether the signal clocking TIMA is currently high
+func (timer *Timer) timaClock() bool {
+	if timer.tacRegister&0x04 == 0 {
+		return false
+	}
+	return (timer.divider>>timaClockBits[timer.tacRegister&0x03])&0x01 == 0x01
+}
+
+func (timer *Timer) setDivider(value uint16) {
+	var before bool = timer.timaClock()
+	timer.divider = value
+	if before && !timer.timaClock() {
+		timer.incrementTIMA()
+	}
+}
+
+func (timer *Timer) incrementTIMA() {
+	timer.tima++
+	if timer.tima == 0x00 {
+		timer.overflowed = true
+	}
 }
 
 func (timer *Timer) Read(Address types.Word) byte {
 	switch Address {
 	case DIV_REGISTER:
-		return timer.divCounter.Value
+		return byte(timer.divider >> 8)
 	case TIMA_REGISTER:
-		return timer.timaCounter.Value
+		return timer.tima
 	case TMA_REGISTER:
 		return timer.tmaRegister
 	case TAC_REGISTER:
-		return timer.tacRegister
+		return timer.tacRegister | 0xF8
 	default:
 		panic(fmt.Sprintln("Timer module is not set up to handle address", Address))
 	}
@@ -134,41 +105,26 @@ func (timer *Timer) Read(Address types.Word) byte {
 func (timer *Timer) Write(address types.Word, value byte) {
 	switch address {
 	case DIV_REGISTER:
-		timer.divCounter.Value = 0
+		//resetting the divider can cause a falling edge on the bit clocking TIMA
+		timer.setDivider(0)
 	case TIMA_REGISTER:
-		timer.timaCounter.Value = value
+		//writing TIMA while it is waiting to be reloaded cancels the reload
+		timer.tima = value
+		timer.overflowed = false
 	case TMA_REGISTER:
 		timer.tmaRegister = value
 	case TAC_REGISTER:
-		var oldFrequency Frequency = timer.GetFrequency(timer.tacRegister & 0x03)
-		var newFrequency Frequency = timer.GetFrequency(value & 0x03)
-
-		if oldFrequency != newFrequency {
-			timer.timaCounter.SetFrequency(newFrequency)
-			log.Println(timer.timaCounter)
+		//disabling the timer or changing frequency can also cause a falling edge
+		var before bool = timer.timaClock()
+		timer.tacRegister = value & 0x07
+		if before && !timer.timaClock() {
+			timer.incrementTIMA()
 		}
-
-		timer.tacRegister = value
 	default:
 		panic(fmt.Sprintln("Timer module is not set up to handle address", address))
 	}
 }
 
-func (timer *Timer) GetFrequency(freqId byte) Frequency {
-	switch freqId {
-	case 0:
-		return freq4096
-	case 1:
-		return freq262144
-	case 2:
-		return freq65536
-	case 3:
-		return freq16384
-	default:
-		panic("Unknown frequency!")
-	}
-}
-
 func (timer *Timer) LinkIRQHandler(m components.IRQHandler) {
 	timer.irqHandler = m
 	log.Println(timer.Name() + ": Linked IRQ Handler to Timer")
@@ -176,4 +132,9 @@ func (timer *Timer) LinkIRQHandler(m components.IRQHandler) {
 
 func (timer *Timer) Reset() {
 	log.Println("Resetting", timer.Name())
+	timer.divider = 0
+	timer.tima = 0x00
+	timer.tacRegister = 0x00
+	timer.tmaRegister = 0x00
+	timer.overflowed = false
 }
@@ -0,0 +1,112 @@
+package trace
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+//The first point at which two traces differ
+type Divergence struct {
+	Instruction int //1 based index of the instruction in both traces
+
+	Ours        State
+	Reference   State
+	Previous    State //the last state the traces agreed on
+	HasPrevious bool
+
+	//registers that differ, empty if one of the traces ended early
+	Fields []string
+
+	OursEnded      bool
+	ReferenceEnded bool
+}
+
+func (d *Divergence) String() string {
+	var lines []string = []string{fmt.Sprintf("Traces diverge at instruction %d", d.Instruction)}
+	if d.HasPrevious {
+		lines = append(lines, fmt.Sprint("  previous:  ", d.Previous))
+	}
+
+	switch {
+	case d.OursEnded:
+		lines = append(lines, "  ours:      <end of trace>")
+		lines = append(lines, fmt.Sprint("  reference: ", d.Reference))
+	case d.ReferenceEnded:
+		lines = append(lines, fmt.Sprint("  ours:      ", d.Ours))
+		lines = append(lines, "  reference: <end of trace>")
+	default:
+		lines = append(lines, fmt.Sprint("  ours:      ", d.Ours))
+		lines = append(lines, fmt.Sprint("  reference: ", d.Reference))
+		lines = append(lines, fmt.Sprint("  differs:   ", strings.Join(d.Fields, " ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+//Compares two traces instruction by instruction, returning the first divergence (nil
+//if the traces match) and the number of instructions that matched
+func Diff(ours, reference io.Reader) (*Divergence, int, error) {
+	var o, r *Reader = NewReader(ours), NewReader(reference)
+	var previous State
+	var matched int
+
+	for {
+		ostate, oerr := o.Next()
+		if oerr != nil && oerr != io.EOF {
+			return nil, matched, oerr
+		}
+		rstate, rerr := r.Next()
+		if rerr != nil && rerr != io.EOF {
+			return nil, matched, rerr
+		}
+
+		if oerr == io.EOF && rerr == io.EOF {
+			return nil, matched, nil
+		}
+
+		var d *Divergence = &Divergence{
+			Instruction:    matched + 1,
+			Ours:           ostate,
+			Reference:      rstate,
+			Previous:       previous,
+			HasPrevious:    matched > 0,
+			OursEnded:      oerr == io.EOF,
+			ReferenceEnded: rerr == io.EOF,
+		}
+		if d.OursEnded || d.ReferenceEnded {
+			return d, matched, nil
+		}
+
+		if d.Fields = compare(ostate, rstate); len(d.Fields) > 0 {
+			return d, matched, nil
+		}
+		previous = ostate
+		matched++
+	}
+}
+
+//Returns the names of the registers that differ. PCMEM is only compared when both
+//traces include it
+func compare(a, b State) []string {
+	var fields []string
+	check := func(name string, equal bool) {
+		if !equal {
+			fields = append(fields, name)
+		}
+	}
+
+	check("A", a.A == b.A)
+	check("F", a.F == b.F)
+	check("B", a.B == b.B)
+	check("C", a.C == b.C)
+	check("D", a.D == b.D)
+	check("E", a.E == b.E)
+	check("H", a.H == b.H)
+	check("L", a.L == b.L)
+	check("SP", a.SP == b.SP)
+	check("PC", a.PC == b.PC)
+	if a.HasPCMem && b.HasPCMem {
+		check("PCMEM", a.PCMem == b.PCMem)
+	}
+	return fields
+}
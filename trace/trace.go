@@ -0,0 +1,185 @@
+//Writes and reads per-instruction CPU traces that can be compared against logs from
+//other emulators
+package trace
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+type Format int
+
+const (
+	//one line per instruction in the format used by gameboy-doctor, e.g.
+	//A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0100 PCMEM:00,C3,13,02
+	DOCTOR_FORMAT Format = iota
+
+	//fixed size records of BINARY_RECORD_SIZE bytes: A F B C D E H L, SP and PC
+	//(little endian) followed by the 4 bytes at PC
+	BINARY_FORMAT
+)
+
+const BINARY_RECORD_SIZE int = 16
+
+//The CPU state before an instruction is executed
+type State struct {
+	A, F, B, C, D, E, H, L byte
+	SP, PC                 types.Word
+	PCMem                  [4]byte
+
+	//false when read from a log that does not include PCMEM
+	HasPCMem bool
+}
+
+func (s State) String() string {
+	var line string = fmt.Sprintf("A:%02X F:%02X B:%02X C:%02X D:%02X E:%02X H:%02X L:%02X SP:%04X PC:%04X",
+		s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L, uint16(s.SP), uint16(s.PC))
+	if s.HasPCMem {
+		line += fmt.Sprintf(" PCMEM:%02X,%02X,%02X,%02X", s.PCMem[0], s.PCMem[1], s.PCMem[2], s.PCMem[3])
+	}
+	return line
+}
+
+//Parses a line written in DOCTOR_FORMAT
+func ParseLine(line string) (State, error) {
+	var s State
+	var seen int
+	for _, field := range strings.Fields(line) {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			return s, errors.New(fmt.Sprintf("Invalid trace field %q", field))
+		}
+		name, value := parts[0], parts[1]
+
+		if name == "PCMEM" {
+			bytes := strings.Split(value, ",")
+			if len(bytes) != len(s.PCMem) {
+				return s, errors.New(fmt.Sprintf("Invalid trace field %q", field))
+			}
+			for i, b := range bytes {
+				v, err := strconv.ParseUint(b, 16, 8)
+				if err != nil {
+					return s, errors.New(fmt.Sprintf("Invalid trace field %q", field))
+				}
+				s.PCMem[i] = byte(v)
+			}
+			s.HasPCMem = true
+			continue
+		}
+
+		var bits int = 8
+		if name == "SP" || name == "PC" {
+			bits = 16
+		}
+		v, err := strconv.ParseUint(value, 16, bits)
+		if err != nil {
+			return s, errors.New(fmt.Sprintf("Invalid trace field %q", field))
+		}
+
+		switch name {
+		case "A":
+			s.A = byte(v)
+		case "F":
+			s.F = byte(v)
+		case "B":
+			s.B = byte(v)
+		case "C":
+			s.C = byte(v)
+		case "D":
+			s.D = byte(v)
+		case "E":
+			s.E = byte(v)
+		case "H":
+			s.H = byte(v)
+		case "L":
+			s.L = byte(v)
+		case "SP":
+			s.SP = types.Word(v)
+		case "PC":
+			s.PC = types.Word(v)
+		default:
+			//other emulators add extra fields (e.g. cycle counts) that can't be compared
+			continue
+		}
+		seen++
+	}
+
+	if seen != 10 {
+		return s, errors.New(fmt.Sprintf("Trace line is missing registers: %q", line))
+	}
+	return s, nil
+}
+
+func (s State) appendBinary(b []byte) []byte {
+	b = append(b, s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L)
+	b = append(b, byte(s.SP), byte(s.SP>>8), byte(s.PC), byte(s.PC>>8))
+	return append(b, s.PCMem[:]...)
+}
+
+func parseBinary(b []byte) State {
+	var s State
+	s.A, s.F, s.B, s.C, s.D, s.E, s.H, s.L = b[0], b[1], b[2], b[3], b[4], b[5], b[6], b[7]
+	s.SP = types.Word(binary.LittleEndian.Uint16(b[8:]))
+	s.PC = types.Word(binary.LittleEndian.Uint16(b[10:]))
+	copy(s.PCMem[:], b[12:16])
+	s.HasPCMem = true
+	return s
+}
+
+//Reads states back from a trace in either format
+type Reader struct {
+	r      *bufio.Reader
+	format Format
+	line   int
+}
+
+//Creates a reader for a trace, the format is detected from the start of the trace.
+//A text trace always begins with "A:" which can't be the start of a binary trace as
+//the low nibble of F is always zero
+func NewReader(r io.Reader) *Reader {
+	var t *Reader = new(Reader)
+	t.r = bufio.NewReader(r)
+	t.format = BINARY_FORMAT
+	if start, err := t.r.Peek(2); err != nil || string(start) == "A:" {
+		t.format = DOCTOR_FORMAT
+	}
+	return t
+}
+
+//Returns the next state in the trace, io.EOF is returned at the end of the trace
+func (t *Reader) Next() (State, error) {
+	if t.format == BINARY_FORMAT {
+		var record []byte = make([]byte, BINARY_RECORD_SIZE)
+		if _, err := io.ReadFull(t.r, record); err == io.ErrUnexpectedEOF {
+			return State{}, errors.New(fmt.Sprintf("Trace record %d is truncated", t.line+1))
+		} else if err != nil {
+			return State{}, err
+		}
+		t.line++
+		return parseBinary(record), nil
+	}
+
+	for {
+		line, err := t.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return State{}, err
+		}
+		t.line++
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		s, err := ParseLine(line)
+		if err != nil {
+			return State{}, errors.New(fmt.Sprintf("Trace line %d: %v", t.line, err))
+		}
+		return s, nil
+	}
+}
@@ -0,0 +1,88 @@
+package trace
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/djhworld/gomeboycolor/types"
+	"github.com/stretchrcom/testify/assert"
+)
+
+const doctorLine string = "A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0100 PCMEM:00,C3,13,02"
+
+func TestStateRoundTripsThroughDoctorFormat(t *testing.T) {
+	s, err := ParseLine(doctorLine)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(0xB0), s.F)
+	assert.Equal(t, [4]byte{0x00, 0xC3, 0x13, 0x02}, s.PCMem)
+	assert.Equal(t, doctorLine, s.String())
+
+	_, err = ParseLine("A:01 F:B0 B:00")
+	assert.NotNil(t, err)
+}
+
+func TestWriterTriggers(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, DOCTOR_FORMAT)
+	w.StartAtPC(0x0102)
+	w.StopAtFrame(2)
+
+	for frame := 0; frame < 4; frame++ {
+		for pc := 0x0100; pc < 0x0104; pc++ {
+			w.Trace(State{PC: types.Word(pc)}, frame)
+		}
+	}
+	assert.Nil(t, w.Flush())
+	assert.True(t, w.Stopped())
+
+	//starts at 0x0102 in frame 0 and runs until frame 2 begins
+	assert.Equal(t, 6, w.Written())
+	assert.Equal(t, 6, strings.Count(buf.String(), "\n"))
+	assert.True(t, strings.HasPrefix(buf.String(), "A:00 F:00 B:00 C:00 D:00 E:00 H:00 L:00 SP:0000 PC:0102\n"))
+}
+
+func TestDiffFindsFirstDivergence(t *testing.T) {
+	var ours bytes.Buffer
+	w := NewWriter(&ours, BINARY_FORMAT)
+	for _, line := range []string{
+		doctorLine,
+		"A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0101 PCMEM:C3,13,02,00",
+		"A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0213 PCMEM:AF,00,00,00",
+	} {
+		s, _ := ParseLine(line)
+		w.Trace(s, 0)
+	}
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, 3*BINARY_RECORD_SIZE, ours.Len())
+
+	//the reference log has no PCMEM so only the registers are compared
+	reference := strings.Join([]string{
+		"A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0100",
+		"A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0101",
+		"A:00 F:80 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0213",
+		"A:00 F:80 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0214",
+	}, "\n")
+
+	d, matched, err := Diff(bytes.NewReader(ours.Bytes()), strings.NewReader(reference))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, matched)
+	assert.Equal(t, 3, d.Instruction)
+	assert.Equal(t, []string{"A", "F"}, d.Fields)
+	assert.Contains(t, d.String(), "differs:   A F")
+
+	d, matched, err = Diff(strings.NewReader(reference), strings.NewReader(reference))
+	assert.Nil(t, err)
+	assert.Nil(t, d)
+	assert.Equal(t, 4, matched)
+}
+
+func TestReaderRejectsTruncatedBinaryTrace(t *testing.T) {
+	r := NewReader(bytes.NewReader(make([]byte, BINARY_RECORD_SIZE+3)))
+	_, err := r.Next()
+	assert.Nil(t, err)
+	_, err = r.Next()
+	assert.NotNil(t, err)
+	assert.NotEqual(t, io.EOF, err)
+}
@@ -0,0 +1,106 @@
+package trace
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/djhworld/gomeboycolor/types"
+)
+
+//Writes a state per instruction to an io.Writer. Without a start trigger tracing begins
+//straight away, once a stop trigger has been hit tracing does not restart
+type Writer struct {
+	w      *bufio.Writer
+	format Format
+	buf    []byte
+	err    error
+
+	startPC, stopPC       types.Word
+	startOnPC, stopOnPC   bool
+	startFrame, stopFrame int
+	stopOnFrame           bool
+
+	started bool
+	stopped bool
+	written int
+}
+
+func NewWriter(w io.Writer, format Format) *Writer {
+	var t *Writer = new(Writer)
+	t.w = bufio.NewWriter(w)
+	t.format = format
+	return t
+}
+
+//Starts tracing when the CPU reaches pc
+func (t *Writer) StartAtPC(pc types.Word) {
+	t.startPC, t.startOnPC = pc, true
+}
+
+//Stops tracing when the CPU reaches pc, the instruction at pc is not traced
+func (t *Writer) StopAtPC(pc types.Word) {
+	t.stopPC, t.stopOnPC = pc, true
+}
+
+//Starts tracing from the given frame
+func (t *Writer) StartAtFrame(frame int) {
+	t.startFrame = frame
+}
+
+//Stops tracing when the given frame begins
+func (t *Writer) StopAtFrame(frame int) {
+	t.stopFrame, t.stopOnFrame = frame, true
+}
+
+//Records the state of the CPU before an instruction in the given frame is executed
+func (t *Writer) Trace(s State, frame int) {
+	if t.stopped || t.err != nil {
+		return
+	}
+
+	if (t.stopOnPC && s.PC == t.stopPC) || (t.stopOnFrame && frame >= t.stopFrame) {
+		t.stopped = true
+		t.Flush()
+		return
+	}
+
+	if !t.started {
+		if frame < t.startFrame || (t.startOnPC && s.PC != t.startPC) {
+			return
+		}
+		t.started = true
+	}
+
+	t.buf = t.buf[:0]
+	if t.format == BINARY_FORMAT {
+		t.buf = s.appendBinary(t.buf)
+	} else {
+		t.buf = append(t.buf, s.String()...)
+		t.buf = append(t.buf, '\n')
+	}
+
+	if _, err := t.w.Write(t.buf); err != nil {
+		t.err = err
+		return
+	}
+	t.written++
+}
+
+//Whether a stop trigger has been hit or writing has failed
+func (t *Writer) Stopped() bool {
+	return t.stopped || t.err != nil
+}
+
+//The number of instructions traced so far
+func (t *Writer) Written() int {
+	return t.written
+}
+
+//Writes out anything buffered, returning the first error the writer hit
+func (t *Writer) Flush() error {
+	if t.err != nil {
+		return t.err
+	}
+	t.err = t.w.Flush()
+	return t.err
+}